@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+)
+
+// expandAlias turns "azure-ai :name text..." or "azure-ai name text..." into a single
+// query: the alias template from the config file followed by the remaining arguments.
+// A bare name only counts when such an alias exists; ":name" must exist.
+func expandAlias(cfg *config.Config, args []string) ([]string, error) {
+	if len(args) == 0 {
+		return args, nil
+	}
+
+	name, explicit := strings.CutPrefix(args[0], ":")
+	template, ok := cfg.Alias(name)
+	if !ok {
+		if explicit {
+			return nil, fmt.Errorf("unknown alias: %s (define it under aliases: in %s)", name, config.ConfigFileName)
+		}
+		return args, nil
+	}
+
+	rest := strings.Join(args[1:], " ")
+	if rest == "" {
+		return []string{template}, nil
+	}
+	// Keep the argument separate from templates that don't end in whitespace
+	if last, _ := utf8.DecodeLastRuneInString(template); !unicode.IsSpace(last) {
+		template += " "
+	}
+	return []string{template + rest}, nil
+}
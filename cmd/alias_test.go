@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+)
+
+func TestExpandAlias(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "aliases:\n  review: \"review this code for bugs: \"\n  standup: summarize my git diff\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.NewConfigFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{"explicit", []string{":review", "func f() {}"}, []string{"review this code for bugs: func f() {}"}, false},
+		{"bare name", []string{"review", "a", "b"}, []string{"review this code for bugs: a b"}, false},
+		{"separator added", []string{"standup", "for today"}, []string{"summarize my git diff for today"}, false},
+		{"template only", []string{":standup"}, []string{"summarize my git diff"}, false},
+		{"not an alias", []string{"what is go", "and rust"}, []string{"what is go", "and rust"}, false},
+		{"unknown explicit", []string{":nope"}, nil, true},
+		{"no args", nil, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandAlias(cfg, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandAlias(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expandAlias(%q) = %q, want %q", tt.args, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("expandAlias(%q)[%d] = %q, want %q", tt.args, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+	"github.com/quocvuong92/azure-ai-cli/internal/display"
+)
+
+// newBenchCmd creates the "bench" subcommand comparing search providers on a query set
+func newBenchCmd() *cobra.Command {
+	var queriesFile string
+	var providers []string
+
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Compare search providers on a set of queries",
+		Long: `Run every query in a file on each configured search provider and print
+result counts and latency side by side.
+
+The queries file has one query per line; blank lines and lines starting with #
+are ignored. Each query costs one search per provider.`,
+		Example: `  azure-ai bench --queries queries.txt
+  azure-ai bench --queries queries.txt --providers tavily,brave`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runBench(queriesFile, providers); err != nil {
+				display.ShowError(err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&queriesFile, "queries", "", "File with one query per line (required)")
+	cmd.Flags().StringSliceVar(&providers, "providers", nil, "Providers to compare (default: all with keys)")
+
+	return cmd
+}
+
+// readQueries loads non-empty, non-comment lines from a file
+func readQueries(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var queries []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		queries = append(queries, line)
+	}
+	return queries, scanner.Err()
+}
+
+func runBench(queriesFile string, providers []string) error {
+	if queriesFile == "" {
+		return fmt.Errorf("--queries is required")
+	}
+	queries, err := readQueries(queriesFile)
+	if err != nil {
+		return err
+	}
+	if len(queries) == 0 {
+		return fmt.Errorf("no queries in %s", queriesFile)
+	}
+
+	cfg := config.NewConfig()
+	cfg.LoadSearchKeys()
+
+	if len(providers) == 0 {
+		for _, p := range config.SearchProviders {
+			if cfg.HasSearchKeys(p) {
+				providers = append(providers, p)
+			}
+		}
+	}
+	if len(providers) == 0 {
+		return config.ErrWebSearchKeyNotFound
+	}
+
+	clients := make([]api.SearchClient, len(providers))
+	for j, p := range providers {
+		p = strings.ToLower(strings.TrimSpace(p))
+		providers[j] = p
+		if !cfg.HasSearchKeys(p) {
+			return fmt.Errorf("no API keys configured for provider: %s", p)
+		}
+		client, err := api.NewSearchClient(p, cfg)
+		if err != nil {
+			return err
+		}
+		name := providerDisplayName(p)
+		client.SetKeyRotationCallback(func(from, to, total int) {
+			display.ShowKeyRotation(name, from, to, total)
+		})
+		clients[j] = client
+	}
+
+	ctx := context.Background()
+	results := make([][]display.BenchResult, len(queries))
+	for i, q := range queries {
+		results[i] = make([]display.BenchResult, len(providers))
+		for j, client := range clients {
+			sp := display.NewSpinner(fmt.Sprintf("[%d/%d] %s: %s", i+1, len(queries), providers[j], q))
+			sp.Start()
+			start := time.Now()
+			resp, err := client.Search(ctx, q)
+			sp.Stop()
+
+			r := display.BenchResult{Latency: time.Since(start), Err: err}
+			if err != nil {
+				log.Printf("bench %s %q: %v", providers[j], q, err)
+			} else {
+				r.Results = len(resp.Results)
+				recordSearchQuota(cfg, providers[j])
+			}
+			results[i][j] = r
+		}
+	}
+
+	display.ShowBenchTable(providers, queries, results)
+	return nil
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+)
+
+func TestReadQueries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queries.txt")
+	content := "# provider comparison\n\ngo generics\n  rust async  \n#skipped\n\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readQueries(path)
+	if err != nil {
+		t.Fatalf("readQueries() error = %v", err)
+	}
+	if want := []string{"go generics", "rust async"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("readQueries() = %q, want %q", got, want)
+	}
+}
+
+func TestRunBenchErrors(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for _, env := range []string{config.EnvTavilyAPIKeys, config.EnvLinkupAPIKeys, config.EnvBraveAPIKeys, config.EnvPerplexityAPIKeys, config.EnvSearXNGURL} {
+		t.Setenv(env, "")
+	}
+	dir := t.TempDir()
+	queries := filepath.Join(dir, "queries.txt")
+	comments := filepath.Join(dir, "comments.txt")
+	_ = os.WriteFile(queries, []byte("go generics\n"), 0o600)
+	_ = os.WriteFile(comments, []byte("# nothing yet\n"), 0o600)
+
+	tests := []struct {
+		name      string
+		file      string
+		providers []string
+		want      string
+	}{
+		{"no queries file", "", nil, "--queries is required"},
+		{"missing file", filepath.Join(dir, "missing.txt"), nil, "no such file"},
+		{"only comments", comments, nil, "no queries in"},
+		{"no provider has keys", queries, nil, config.ErrWebSearchKeyNotFound.Error()},
+		{"named provider without keys", queries, []string{"Brave"}, "no API keys configured for provider: brave"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runBench(tt.file, tt.providers)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("runBench() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
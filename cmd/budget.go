@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// hasLengthBudget reports whether a word or character budget is configured
+func (app *App) hasLengthBudget() bool {
+	return app.cfg.MaxWords > 0 || app.cfg.MaxChars > 0
+}
+
+// lengthInstruction returns the system prompt instruction for the configured budget
+func (app *App) lengthInstruction() string {
+	var limits []string
+	if app.cfg.MaxWords > 0 {
+		limits = append(limits, fmt.Sprintf("%d words", app.cfg.MaxWords))
+	}
+	if app.cfg.MaxChars > 0 {
+		limits = append(limits, fmt.Sprintf("%d characters", app.cfg.MaxChars))
+	}
+	if len(limits) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Keep your answer under %s.", strings.Join(limits, " and "))
+}
+
+// applyLengthBudget trims content to the configured budget
+func (app *App) applyLengthBudget(content string) string {
+	return truncateToBudget(content, app.cfg.MaxWords, app.cfg.MaxChars)
+}
+
+// truncateToBudget hard-trims content to at most maxWords words and maxChars characters
+// (zero means no limit, and the "…" marking a cut counts towards maxChars), preferring
+// to cut at a sentence boundary
+func truncateToBudget(content string, maxWords, maxChars int) string {
+	content = strings.TrimSpace(content)
+	cut := len(content)
+
+	if maxWords > 0 {
+		words := 0
+		inWord := false
+		for i, r := range content {
+			if unicode.IsSpace(r) {
+				inWord = false
+				continue
+			}
+			if !inWord {
+				words++
+				inWord = true
+				if words > maxWords {
+					cut = min(cut, i)
+					break
+				}
+			}
+		}
+	}
+
+	if maxChars > 0 {
+		cut = min(cut, len(truncateRunes(content, maxChars)))
+	}
+
+	if cut >= len(content) {
+		return content
+	}
+
+	trimmed := strings.TrimSpace(content[:cut])
+
+	// Prefer ending on a full sentence if that keeps at least half the budget
+	if idx := lastSentenceEnd(trimmed); idx >= len(trimmed)/2 {
+		return trimmed[:idx]
+	}
+
+	// Otherwise cut at the last word boundary (if the cut landed mid-word) and mark it,
+	// leaving room for the ellipsis within maxChars
+	if maxChars > 0 {
+		cut = min(cut, len(truncateRunes(content, maxChars-1)))
+		trimmed = strings.TrimSpace(content[:cut])
+	}
+	next, _ := utf8.DecodeRuneInString(content[cut:])
+	prev, _ := utf8.DecodeLastRuneInString(content[:cut])
+	if !unicode.IsSpace(next) && !unicode.IsSpace(prev) {
+		if idx := strings.LastIndexFunc(trimmed, unicode.IsSpace); idx > 0 {
+			trimmed = strings.TrimSpace(trimmed[:idx])
+		}
+	}
+	return trimmed + "…"
+}
+
+// truncateRunes returns s cut to at most max runes. Content is always cut with this
+// rather than by byte index, which can split a multibyte character.
+func truncateRunes(s string, max int) string {
+	if max <= 0 {
+		return ""
+	}
+	runes := 0
+	for i := range s {
+		if runes == max {
+			return s[:i]
+		}
+		runes++
+	}
+	return s
+}
+
+// truncateWithEllipsis cuts s to max runes, appending "..." when anything was removed
+func truncateWithEllipsis(s string, max int) string {
+	if t := truncateRunes(s, max); len(t) < len(s) {
+		return t + "..."
+	}
+	return s
+}
+
+// lastSentenceEnd returns the index just past the last sentence terminator in s, or -1
+func lastSentenceEnd(s string) int {
+	for i := len(s) - 1; i >= 0; i-- {
+		switch s[i] {
+		case '.', '!', '?':
+			if i == len(s)-1 || s[i+1] == ' ' || s[i+1] == '\n' {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
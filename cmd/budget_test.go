@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+)
+
+func TestTruncateToBudget(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		maxWords int
+		maxChars int
+		want     string
+	}{
+		{"no limits", "One. Two.", 0, 0, "One. Two."},
+		{"under budget", "Short answer.", 5, 100, "Short answer."},
+		{"words at sentence", "First sentence here. Second one is longer than that.", 5, 0, "First sentence here."},
+		{"words mid sentence", "alpha beta gamma delta epsilon", 3, 0, "alpha beta gamma…"},
+		{"chars at sentence", "Go is fast. It is also simple to learn.", 0, 20, "Go is fast."},
+		{"chars mid word", "abcdef ghijkl mnopqr", 0, 10, "abcdef…"},
+		{"multibyte chars", "héllo wörld ünïcode", 0, 11, "héllo…"},
+		{"ellipsis fits the budget", "héllo wörld ünïcode", 0, 12, "héllo wörld…"},
+		{"one char", "abc", 0, 1, "…"},
+		{"tighter of both", "one two three four. five six", 10, 8, "one two…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateToBudget(tt.content, tt.maxWords, tt.maxChars)
+			if got != tt.want {
+				t.Errorf("truncateToBudget(%q, %d, %d) = %q, want %q", tt.content, tt.maxWords, tt.maxChars, got, tt.want)
+			}
+			if n := utf8.RuneCountInString(got); tt.maxChars > 0 && n > tt.maxChars {
+				t.Errorf("truncateToBudget(%q, %d, %d) has %d runes, want at most %d", tt.content, tt.maxWords, tt.maxChars, n, tt.maxChars)
+			}
+		})
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		max  int
+		want string
+	}{
+		{"ascii", "hello world", 5, "hello"},
+		{"shorter than max", "héllo", 10, "héllo"},
+		{"accented", "héllo", 2, "hé"},
+		{"cjk", "日本語のテキスト", 3, "日本語"},
+		{"emoji", "🙂🙃🙂", 1, "🙂"},
+		{"zero", "abc", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateRunes(tt.s, tt.max)
+			if got != tt.want {
+				t.Errorf("truncateRunes(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncateRunes(%q, %d) = %q is not valid UTF-8", tt.s, tt.max, got)
+			}
+		})
+	}
+
+	if got := truncateWithEllipsis("日本語のテキスト", 3); got != "日本語..." {
+		t.Errorf("truncateWithEllipsis() = %q, want %q", got, "日本語...")
+	}
+	if got := truncateWithEllipsis("日本語", 3); got != "日本語" {
+		t.Errorf("truncateWithEllipsis() = %q, want unchanged", got)
+	}
+}
+
+func TestBuildSystemPrompt(t *testing.T) {
+	app := &App{cfg: &config.Config{MaxWords: 50, AnswerLang: "pt-br"}}
+	got := app.buildSystemPrompt("Base.")
+	want := "Base.\n\nKeep your answer under 50 words.\n\n" + fmt.Sprintf(AnswerLanguageInstruction, "Brazilian Portuguese")
+	if got != want {
+		t.Errorf("buildSystemPrompt() = %q, want %q", got, want)
+	}
+
+	app.cfg = &config.Config{}
+	if got := app.buildSystemPrompt("Base."); got != "Base." {
+		t.Errorf("buildSystemPrompt() without options = %q, want the base prompt", got)
+	}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+)
+
+var (
+	errNoClipboardTool  = errors.New("no clipboard image tool found (install pngpaste on macOS, xclip or wl-clipboard on Linux)")
+	errNoClipboardImage = errors.New("clipboard does not contain an image")
+)
+
+// clipboardCommand is a command that writes the clipboard image as PNG to stdout
+type clipboardCommand struct {
+	name string
+	args []string
+}
+
+// clipboardCommands returns candidate clipboard image readers for the current OS, in preference order
+func clipboardCommands() []clipboardCommand {
+	switch runtime.GOOS {
+	case "darwin":
+		return []clipboardCommand{
+			{name: "pngpaste", args: []string{"-"}},
+		}
+	case "windows":
+		script := `Add-Type -AssemblyName System.Windows.Forms; ` +
+			`$img = [System.Windows.Forms.Clipboard]::GetImage(); ` +
+			`if ($img -eq $null) { exit 1 }; ` +
+			`$ms = New-Object System.IO.MemoryStream; ` +
+			`$img.Save($ms, [System.Drawing.Imaging.ImageFormat]::Png); ` +
+			`$out = [Console]::OpenStandardOutput(); $out.Write($ms.ToArray(), 0, $ms.Length)`
+		return []clipboardCommand{
+			{name: "powershell", args: []string{"-NoProfile", "-Command", script}},
+		}
+	default:
+		return []clipboardCommand{
+			{name: "wl-paste", args: []string{"--type", "image/png"}},
+			{name: "xclip", args: []string{"-selection", "clipboard", "-t", "image/png", "-o"}},
+		}
+	}
+}
+
+// readClipboardImage returns the image currently on the system clipboard
+func readClipboardImage() ([]byte, error) {
+	found := false
+	for _, c := range clipboardCommands() {
+		if _, err := exec.LookPath(c.name); err != nil {
+			continue
+		}
+		found = true
+		out, err := exec.Command(c.name, c.args...).Output()
+		if err == nil && len(out) > 0 {
+			return out, nil
+		}
+	}
+	if !found {
+		return nil, errNoClipboardTool
+	}
+	return nil, errNoClipboardImage
+}
@@ -0,0 +1,468 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elk-language/go-prompt"
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+	"github.com/quocvuong92/azure-ai-cli/internal/display"
+)
+
+// slashCommand describes an interactive command. The registry drives dispatch,
+// /help and auto-completion, so adding a command here updates all three.
+type slashCommand struct {
+	name        string
+	aliases     []string
+	description string
+	subcommands []subcommand
+	// run handles the command; parts is the input split into the command and the rest.
+	// Returns true to exit interactive mode.
+	run func(s *InteractiveSession, parts []string) bool
+	// complete, when set, suggests values for the argument typed so far, e.g.
+	// completeSession for /load
+	complete func(arg string) []prompt.Suggest
+}
+
+// subcommand is an extra form of a command shown in /help and/or offered by the completer
+type subcommand struct {
+	usage       string // Shown in /help when non-empty, e.g. "/clear keep <N>"
+	suggest     string // Offered by the completer when non-empty, e.g. "/clear keep"
+	description string
+}
+
+// slashCommands is the registry of interactive commands, in /help order
+var slashCommands []slashCommand
+
+func init() {
+	slashCommands = []slashCommand{
+		{
+			name:        "/exit",
+			aliases:     []string{"/quit", "/q"},
+			description: "Exit interactive mode",
+			run: func(s *InteractiveSession, parts []string) bool {
+				fmt.Println("Goodbye!")
+				return true
+			},
+		},
+		{
+			name:        "/clear",
+			aliases:     []string{"/c"},
+			description: "Clear conversation history",
+			subcommands: []subcommand{
+				{usage: "/clear keep <N>", suggest: "/clear keep", description: "Clear history but keep the last N messages"},
+			},
+			run: func(s *InteractiveSession, parts []string) bool {
+				s.resetUsage()
+				if len(parts) > 1 {
+					s.app.handleClearKeep(parts[1], &s.messages)
+					return false
+				}
+				s.messages = []api.Message{
+					{Role: "system", Content: s.app.buildSystemPrompt(s.app.cfg.GetSystemPrompt())},
+				}
+				fmt.Println("Conversation cleared.")
+				return false
+			},
+		},
+		{
+			name:        "/web",
+			description: "Show web search status",
+			subcommands: append([]subcommand{
+				{usage: "/web <query>", description: "Search web and ask about results"},
+				{usage: "/web @<provider> <query>", description: "Search with a provider for this query only"},
+				{usage: "/web on", suggest: "/web on", description: "Enable auto web search for all messages"},
+				{usage: "/web off", suggest: "/web off", description: "Disable auto web search"},
+				{usage: "/web <provider>", description: fmt.Sprintf("Switch provider (%s)", strings.Join(config.SearchProviders, ", "))},
+			}, providerSuggestions()...),
+			run: func(s *InteractiveSession, parts []string) bool {
+				s.app.handleWebCommand(parts, &s.messages, s.client, s.exec)
+				return false
+			},
+		},
+		{
+			name:        "/model",
+			description: "Show current model",
+			subcommands: []subcommand{
+				{usage: "/model <name>", description: "Switch model"},
+				{usage: "/model list", suggest: "/model list", description: "List models with tools/vision/streaming support"},
+			},
+			run: func(s *InteractiveSession, parts []string) bool {
+				s.app.handleModelCommand(parts, s.client)
+				return false
+			},
+		},
+		{
+			name:        "/edit-last",
+			description: "Edit the last message in $EDITOR and resend it",
+			subcommands: []subcommand{
+				{usage: "/edit-last <text>", description: "Replace the last message with text and resend it"},
+			},
+			run: func(s *InteractiveSession, parts []string) bool {
+				arg := ""
+				if len(parts) > 1 {
+					arg = parts[1]
+				}
+				s.editLast(arg)
+				return false
+			},
+		},
+		{
+			name:        "/replay",
+			description: "Re-send the last message under the current system prompt and model",
+			subcommands: []subcommand{
+				{usage: "/replay all", suggest: "/replay all", description: "Re-send every message, replacing all answers"},
+			},
+			run: func(s *InteractiveSession, parts []string) bool {
+				all := len(parts) > 1 && strings.EqualFold(strings.TrimSpace(parts[1]), "all")
+				if len(parts) > 1 && !all {
+					fmt.Println("Usage: /replay | /replay all")
+					return false
+				}
+				s.replay(all)
+				return false
+			},
+		},
+		{
+			name:        "/undo",
+			description: "Remove the last exchange (your message, tool calls and the answer) from history",
+			run: func(s *InteractiveSession, parts []string) bool {
+				s.undo()
+				return false
+			},
+		},
+		{
+			name:        "/less",
+			description: "Open the last answer, rendered, in a pager ($PAGER or less)",
+			run: func(s *InteractiveSession, parts []string) bool {
+				s.pageLastAnswer()
+				return false
+			},
+		},
+		{
+			name:        "/system",
+			description: "Show the system prompt",
+			subcommands: []subcommand{
+				{usage: "/system <text>", description: "Replace the system prompt for the rest of the session"},
+			},
+			run: func(s *InteractiveSession, parts []string) bool {
+				if len(parts) > 1 && strings.TrimSpace(parts[1]) != "" {
+					s.setSystemPrompt(strings.TrimSpace(parts[1]))
+					fmt.Println("System prompt updated.")
+					return false
+				}
+				if len(s.messages) > 0 && s.messages[0].Role == "system" {
+					fmt.Println(s.messages[0].Content)
+				} else {
+					fmt.Println("No system prompt.")
+				}
+				return false
+			},
+		},
+		{
+			name:        "/show-prompt",
+			description: "Show the messages the model receives next turn",
+			run: func(s *InteractiveSession, parts []string) bool {
+				fmt.Print(formatPrompt(s.messages))
+				return false
+			},
+		},
+		{
+			name:        "/tokens",
+			description: "Show token usage for this session and the current history size",
+			run: func(s *InteractiveSession, parts []string) bool {
+				display.ShowSessionUsage(s.usage.PromptTokens, s.usage.CompletionTokens, s.usage.TotalTokens,
+					s.requests, len(s.messages), api.EstimateTokens(s.messages))
+				return false
+			},
+		},
+		{
+			name:        "/save",
+			description: "Save the conversation to resume later",
+			subcommands: []subcommand{
+				{usage: "/save <name>", description: "Save the conversation under name"},
+			},
+			run: func(s *InteractiveSession, parts []string) bool {
+				if len(parts) < 2 || strings.TrimSpace(parts[1]) == "" {
+					fmt.Println("Usage: /save <name>")
+					return false
+				}
+				s.saveSession(strings.TrimSpace(parts[1]))
+				return false
+			},
+		},
+		{
+			name:        "/load",
+			description: "Replace the conversation with a saved one",
+			subcommands: []subcommand{
+				{usage: "/load <name>", description: "Load the session saved under name"},
+			},
+			run: func(s *InteractiveSession, parts []string) bool {
+				if len(parts) < 2 || strings.TrimSpace(parts[1]) == "" {
+					fmt.Println("Usage: /load <name>")
+					return false
+				}
+				s.loadSession(strings.TrimSpace(parts[1]))
+				return false
+			},
+			complete: completeSession,
+		},
+		{
+			name:        "/sessions",
+			description: "List saved sessions",
+			run: func(s *InteractiveSession, parts []string) bool {
+				s.showSessions()
+				return false
+			},
+		},
+		{
+			name:        "/stage",
+			description: "Stage a line to send later as part of one message",
+			subcommands: []subcommand{
+				{usage: "/stage <text>", description: "Append text to the staged message"},
+			},
+			run: func(s *InteractiveSession, parts []string) bool {
+				if len(parts) < 2 || strings.TrimSpace(parts[1]) == "" {
+					fmt.Println("Usage: /stage <text>")
+					return false
+				}
+				s.stage(parts[1])
+				return false
+			},
+		},
+		{
+			name:        "/staged",
+			description: "Show the staged message",
+			run: func(s *InteractiveSession, parts []string) bool {
+				s.showStaged()
+				return false
+			},
+		},
+		{
+			name:        "/send",
+			description: "Send the staged lines as one message",
+			run: func(s *InteractiveSession, parts []string) bool {
+				s.sendStaged()
+				return false
+			},
+		},
+		{
+			name:        "/discard",
+			description: "Clear the staged message",
+			run: func(s *InteractiveSession, parts []string) bool {
+				n := len(s.staged)
+				s.staged = nil
+				fmt.Printf("Discarded %d staged line(s).\n", n)
+				return false
+			},
+		},
+		{
+			name:        "/paste-image",
+			description: "Attach the clipboard image to the next message",
+			run: func(s *InteractiveSession, parts []string) bool {
+				s.pasteImage()
+				return false
+			},
+		},
+		{
+			name:        "/allow-dangerous",
+			description: "Allow dangerous commands (with confirmation)",
+			run: func(s *InteractiveSession, parts []string) bool {
+				if s.app.cfg.SafeMode {
+					fmt.Println("Command execution is disabled in safe mode.")
+					return false
+				}
+				s.exec.GetPermissionManager().EnableDangerous()
+				display.ShowDangerousEnabled(dangerousCategories())
+				return false
+			},
+		},
+		{
+			name:        "/disallow-dangerous",
+			aliases:     []string{"/lock"},
+			description: "Block dangerous commands again",
+			run: func(s *InteractiveSession, parts []string) bool {
+				s.exec.GetPermissionManager().DisableDangerous()
+				fmt.Println("Dangerous commands blocked again.")
+				return false
+			},
+		},
+		{
+			name:        "/dry-run",
+			description: "Show whether commands are only previewed",
+			subcommands: []subcommand{
+				{usage: "/dry-run on", suggest: "/dry-run on", description: "Show commands and file writes without running them"},
+				{usage: "/dry-run off", suggest: "/dry-run off", description: "Run approved commands again"},
+			},
+			run: func(s *InteractiveSession, parts []string) bool {
+				pm := s.exec.GetPermissionManager()
+				arg := ""
+				if len(parts) > 1 {
+					arg = strings.ToLower(strings.TrimSpace(parts[1]))
+				}
+				switch arg {
+				case "":
+				case "on":
+					pm.SetDryRun(true)
+				case "off":
+					pm.SetDryRun(false)
+				default:
+					fmt.Println("Usage: /dry-run [on|off]")
+					return false
+				}
+				if pm.DryRun() {
+					fmt.Println("Dry run is on: commands and file writes are shown, not run.")
+				} else {
+					fmt.Println("Dry run is off.")
+				}
+				return false
+			},
+		},
+		{
+			name:        "/deny",
+			description: "Always block a command, with any arguments, saved to the denylist file",
+			subcommands: []subcommand{
+				{usage: "/deny <command>", description: "Always block a command, e.g. /deny git push"},
+			},
+			run: func(s *InteractiveSession, parts []string) bool {
+				if len(parts) < 2 || strings.TrimSpace(parts[1]) == "" {
+					fmt.Println("Usage: /deny <command> (e.g. /deny git push)")
+					return false
+				}
+				s.denyCommand(strings.TrimSpace(parts[1]))
+				return false
+			},
+		},
+		{
+			name:        "/tools",
+			description: "List the tools the AI can call and whether each is enabled",
+			run: func(s *InteractiveSession, parts []string) bool {
+				display.ShowTools(s.app.toolListings())
+				return false
+			},
+		},
+		{
+			name:        "/show-permissions",
+			description: "Show command execution permissions",
+			run: func(s *InteractiveSession, parts []string) bool {
+				display.ShowPermissionSettings(s.exec.GetPermissionManager().GetSettings(), s.exec.Timeout())
+				return false
+			},
+		},
+		{
+			name:        "/help",
+			aliases:     []string{"/h"},
+			description: "Show this help",
+			run: func(s *InteractiveSession, parts []string) bool {
+				showCommandHelp()
+				return false
+			},
+		},
+	}
+}
+
+// providerSuggestions returns "/web <provider>" completions for each search provider
+func providerSuggestions() []subcommand {
+	subs := make([]subcommand, len(config.SearchProviders))
+	for i, p := range config.SearchProviders {
+		subs[i] = subcommand{
+			suggest:     "/web " + p,
+			description: fmt.Sprintf("Use %s search provider", providerDisplayName(p)),
+		}
+	}
+	return subs
+}
+
+// findCommand looks up a command by name or alias
+func findCommand(name string) (slashCommand, bool) {
+	for _, c := range slashCommands {
+		if c.name == name {
+			return c, true
+		}
+		for _, a := range c.aliases {
+			if a == name {
+				return c, true
+			}
+		}
+	}
+	return slashCommand{}, false
+}
+
+// handleCommand dispatches a slash command. Returns true if the session should exit.
+func (s *InteractiveSession) handleCommand(input string) bool {
+	parts := strings.SplitN(input, " ", 2)
+	name := strings.ToLower(parts[0])
+
+	c, ok := findCommand(name)
+	if !ok {
+		fmt.Printf("Unknown command: %s\n", name)
+		fmt.Println("Type /help for available commands")
+		return false
+	}
+	return c.run(s, parts)
+}
+
+// commandSuggestions builds completer suggestions from the registry
+func commandSuggestions() []prompt.Suggest {
+	var suggestions []prompt.Suggest
+	for _, c := range slashCommands {
+		suggestions = append(suggestions, prompt.Suggest{Text: c.name, Description: c.description})
+		for _, a := range c.aliases {
+			suggestions = append(suggestions, prompt.Suggest{Text: a, Description: c.description})
+		}
+		for _, sub := range c.subcommands {
+			if sub.suggest != "" {
+				suggestions = append(suggestions, prompt.Suggest{Text: sub.suggest, Description: sub.description})
+			}
+		}
+	}
+	return suggestions
+}
+
+// showCommandHelp prints the command list from the registry
+func showCommandHelp() {
+	fmt.Println("\nCommands:")
+	for _, c := range slashCommands {
+		names := append([]string{c.name}, c.aliases...)
+		fmt.Printf("  %-26s %s\n", strings.Join(names, ", "), c.description)
+		for _, sub := range c.subcommands {
+			if sub.usage != "" {
+				fmt.Printf("  %-26s %s\n", sub.usage, sub.description)
+			}
+		}
+	}
+	fmt.Println()
+}
+
+// pasteImage reads an image from the clipboard and queues it for the next message
+func (s *InteractiveSession) pasteImage() {
+	data, err := readClipboardImage()
+	if err != nil {
+		display.ShowError(err.Error())
+		return
+	}
+	url, err := api.ImageDataURL(data)
+	if err != nil {
+		display.ShowError(fmt.Sprintf("%v: %v", errNoClipboardImage, err))
+		return
+	}
+	s.pendingImages = append(s.pendingImages, url)
+	fmt.Printf("Image attached (%d KB). It will be sent with your next message.\n", (len(data)+1023)/1024)
+}
+
+// denyCommand handles "/deny <command>": the command is blocked for the rest of the
+// session and saved to the denylist file for later ones
+func (s *InteractiveSession) denyCommand(pattern string) {
+	s.exec.GetPermissionManager().AddDenyPattern(pattern)
+	path := s.app.cfg.GetDenylistFile()
+	if path == "" {
+		fmt.Printf("Denied %q for this session (no config directory to save it in).\n", pattern)
+		return
+	}
+	if err := appendPatternFile(path, pattern); err != nil {
+		display.ShowError(fmt.Sprintf("Denied %q for this session, but saving it to %s failed: %v", pattern, path, err))
+		return
+	}
+	fmt.Printf("Denied %q (saved to %s).\n", pattern, path)
+}
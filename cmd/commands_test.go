@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+)
+
+func TestFindCommand(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		found bool
+	}{
+		{"/exit", "/exit", true},
+		{"/q", "/exit", true},
+		{"/c", "/clear", true},
+		{"/h", "/help", true},
+		{"/web", "/web", true},
+		{"/nope", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			c, ok := findCommand(tt.input)
+			if ok != tt.found {
+				t.Fatalf("findCommand(%q) found = %v, want %v", tt.input, ok, tt.found)
+			}
+			if ok && c.name != tt.want {
+				t.Errorf("findCommand(%q) = %q, want %q", tt.input, c.name, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandNamesUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, c := range slashCommands {
+		for _, name := range append([]string{c.name}, c.aliases...) {
+			if seen[name] {
+				t.Errorf("command name %q registered twice", name)
+			}
+			seen[name] = true
+		}
+	}
+}
+
+func TestStagingCommands(t *testing.T) {
+	s := &InteractiveSession{}
+
+	s.handleCommand("/stage first line")
+	s.handleCommand("/stage   second line")
+	s.handleCommand("/stage")
+	if len(s.staged) != 2 || s.staged[0] != "first line" || s.staged[1] != "  second line" {
+		t.Fatalf("staged = %q, want two lines", s.staged)
+	}
+
+	s.handleCommand("/discard")
+	if len(s.staged) != 0 {
+		t.Errorf("staged after /discard = %q, want empty", s.staged)
+	}
+
+	// Sending with nothing staged is a no-op rather than an empty message
+	s.handleCommand("/send")
+	if len(s.messages) != 0 {
+		t.Errorf("messages after empty /send = %d, want 0", len(s.messages))
+	}
+}
+
+func TestSystemCommand(t *testing.T) {
+	s := &InteractiveSession{
+		app:      &App{cfg: &config.Config{}},
+		messages: []api.Message{{Role: "user", Content: "hi"}},
+	}
+
+	s.handleCommand("/system You are a terse Go code reviewer")
+	if len(s.messages) != 2 || s.messages[0].Role != "system" || s.messages[0].Content != "You are a terse Go code reviewer" {
+		t.Fatalf("messages after /system on history without one = %+v", s.messages)
+	}
+
+	s.handleCommand("/system Be playful")
+	if len(s.messages) != 2 || s.messages[0].Content != "Be playful" || s.messages[1].Content != "hi" {
+		t.Errorf("messages after replacing the prompt = %+v", s.messages)
+	}
+	if got := s.app.cfg.GetSystemPrompt(); got != "Be playful" {
+		t.Errorf("GetSystemPrompt() = %q, want the new prompt kept for /clear", got)
+	}
+}
+
+func TestUndoCommand(t *testing.T) {
+	s := &InteractiveSession{messages: []api.Message{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: "u1"},
+		{Role: "assistant", Content: "a1"},
+		{Role: "user", Content: "u2"},
+		{Role: "assistant", ToolCalls: []api.ToolCall{{ID: "call_1"}}},
+		{Role: "tool", Content: "out", ToolCallID: "call_1"},
+		{Role: "assistant", Content: "a2"},
+	}}
+
+	s.handleCommand("/undo")
+	if len(s.messages) != 3 || s.messages[2].Content != "a1" {
+		t.Fatalf("messages after first /undo = %+v", s.messages)
+	}
+	s.handleCommand("/undo")
+	s.handleCommand("/undo")
+	if len(s.messages) != 1 || s.messages[0].Role != "system" {
+		t.Errorf("messages after undoing everything = %+v, want only the system prompt", s.messages)
+	}
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/elk-language/go-prompt"
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+)
+
+// argumentSuggestions completes the argument of a command whose registry entry has a
+// completer, e.g. "/load my-se". It returns the suggestions and the argument text
+// they replace; ok is false when the input is not such a command's argument. No command
+// takes a file path yet, so there is no path completion: /load completes session names.
+func argumentSuggestions(text string) (suggestions []prompt.Suggest, arg string, ok bool) {
+	name, arg, found := strings.Cut(text, " ")
+	if !found {
+		return nil, "", false
+	}
+	c, found := findCommand(strings.ToLower(name))
+	if !found || c.complete == nil {
+		return nil, "", false
+	}
+	return c.complete(arg), arg, true
+}
+
+// completeSession lists saved sessions whose names start with arg, most recent first
+func completeSession(arg string) []prompt.Suggest {
+	dir, err := config.GetSessionsDir()
+	if err != nil {
+		return nil
+	}
+	sessions, err := listSessions(dir)
+	if err != nil {
+		return nil
+	}
+	var suggestions []prompt.Suggest
+	for _, s := range sessions {
+		if strings.HasPrefix(s.Name, arg) {
+			suggestions = append(suggestions, prompt.Suggest{Text: s.Name, Description: "saved " + s.ModTime.Format("2006-01-02 15:04")})
+		}
+	}
+	return suggestions
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/elk-language/go-prompt"
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+)
+
+func suggestionTexts(suggestions []prompt.Suggest) []string {
+	texts := []string{}
+	for _, s := range suggestions {
+		texts = append(texts, s.Text)
+	}
+	return texts
+}
+
+func TestArgumentSuggestions(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	dir, err := config.GetSessionsDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"debug-1", "release"} {
+		if err := writeSession(dir, name, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, arg, ok := argumentSuggestions("/load deb")
+	if !ok || arg != "deb" || len(got) != 1 || got[0].Text != "debug-1" {
+		t.Errorf("argumentSuggestions(/load deb) = %v, %q, %v; want debug-1", suggestionTexts(got), arg, ok)
+	}
+	if _, _, ok := argumentSuggestions("/load"); ok {
+		t.Error("argumentSuggestions completed a command name")
+	}
+	if _, _, ok := argumentSuggestions("/clear ke"); ok {
+		t.Error("argumentSuggestions completed a command without an argument completer")
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+	"github.com/quocvuong92/azure-ai-cli/internal/display"
+)
+
+// newConfigCmd creates the "config" subcommand for persistent settings
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage persistent settings in the config file",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "set-model <name>",
+		Short: "Set the default model used when --model is not given",
+		Long: `Save the default model to the config file (~/.config/azure-ai/config.yaml).
+It takes precedence over the order of AZURE_OPENAI_MODELS; --model still wins.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runSetModel(args[0]); err != nil {
+				display.ShowError(err.Error())
+				os.Exit(1)
+			}
+		},
+	})
+
+	return cmd
+}
+
+func runSetModel(model string) error {
+	cfg := config.NewConfig()
+	cfg.LoadAvailableModels()
+	if !cfg.ValidateModel(model) {
+		return fmt.Errorf("%w: %s. Available: %s", config.ErrInvalidModel, model, cfg.GetAvailableModelsString())
+	}
+
+	path, err := config.SetFileValue(config.ConfigFileKeyModel, model)
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	fmt.Printf("Default model set to %s (%s)\n", model, path)
+	if len(cfg.AvailableModels) == 0 {
+		fmt.Fprintf(os.Stderr, "Note: %s is not set, so the model name was not validated\n", config.EnvAzureModels)
+	}
+	return nil
+}
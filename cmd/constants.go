@@ -6,12 +6,30 @@ const (
 	// when optimizing search queries based on conversation context
 	MaxHistoryMessagesForOptimization = 10
 
-	// MaxMessageLengthForOptimization is the maximum length of assistant messages
+	// MaxMessageLengthForOptimization is the maximum length (in characters) of assistant messages
 	// before truncation when building context for query optimization.
 	// Increased to 800 to preserve more context including version numbers and key details.
 	MaxMessageLengthForOptimization = 5000
+
+	// MaxOptimizedQueryWords is the longest optimized query accepted; longer output
+	// is treated as the model answering or explaining instead of writing a query
+	MaxOptimizedQueryWords = 16
 )
 
+// MaxSummaryInputBytes caps the command output sent to be summarized by --summarize-tool-output;
+// longer output keeps its start and end, where errors and totals usually are
+const MaxSummaryInputBytes = 64 * 1024
+
+// Follow-up sent by --retry-empty after an empty (or, with --min-answer-words, too short) answer
+const RetryNudge = "Your previous reply was empty or incomplete. Please answer my last message fully."
+
+// junkQueryPrefixes mark optimizer output that is a refusal or commentary, not a query
+var junkQueryPrefixes = []string{
+	"i cannot", "i can't", "i can not", "i'm sorry", "i am sorry", "sorry",
+	"i'm unable", "i am unable", "unable to", "as an ai", "i don't", "i do not",
+	"here is", "here's", "search query:",
+}
+
 // Search query optimization system prompt
 const QueryOptimizationPrompt = `You are an expert search query optimizer. Your task is to transform a user's follow-up question into an effective web search query based on the conversation history.
 
@@ -32,6 +50,53 @@ const QueryOptimizationPrompt = `You are an expert search query optimizer. Your
 
 ## Output ONLY the search query, nothing else. No quotes, no explanation.`
 
+// History summarization system prompt
+const HistorySummaryPrompt = `Summarize the conversation below so it can replace the original messages as context for continuing the chat.
+
+Keep: the user's goals, decisions made, facts and numbers established, code or commands that matter, and open questions.
+Drop: greetings, repetition, and anything already resolved that will not matter again.
+
+Write compact bullet points. Output ONLY the summary.`
+
+// Summary message template inserted in place of summarized turns
+const HistorySummaryMessageTemplate = `Summary of earlier conversation:
+
+%s`
+
+// Command output summarization system prompt used by --summarize-tool-output
+const ToolOutputSummaryPrompt = `Summarize the output of the shell command below for an assistant that ran it and will act on the result.
+
+Keep: errors and warnings with their file names and line numbers, failing test names, counts and totals, paths, versions, and anything unexpected.
+Drop: repeated lines, progress output, and routine success messages.
+
+Quote important lines verbatim. Output ONLY the summary.`
+
+// Tool result sent in place of output that was summarized
+const ToolOutputSummaryTemplate = `The command output (%d bytes) was summarized:
+
+%s`
+
+// Search need classification system prompt used by --smart-web
+const SearchNeededPrompt = `You decide whether a follow-up message needs a new web search.
+
+Answer YES if it asks about a new topic, needs current or updated facts, or needs details not present in the conversation.
+Answer NO if it can be answered from the conversation so far (clarifications, rephrasing, opinions, summaries, code based on prior answers).
+
+Output ONLY YES or NO.`
+
+// Instruction appended to the system prompt by --answer-lang
+const AnswerLanguageInstruction = "Always write your answer in %s, whatever the language of the question or of any search results. Keep code, commands and quoted source titles unchanged."
+
+// Search intent system prompt used by --search-intent
+const SearchIntentPrompt = `You decide whether the user's latest message needs a web search and, if so, what to search for.
+
+Set need_search to true if the message asks about a new topic, needs current or updated facts, or needs details not present in the conversation.
+Set need_search to false if it can be answered from the conversation so far or from general knowledge (greetings, clarifications, rephrasing, opinions, code based on prior answers).
+
+When need_search is true, search_query must be a self-contained web search query of 3-8 keywords that names the specific entities, versions and technologies involved, without pronouns or conversational language.
+
+Output ONLY a JSON object: {"need_search": true, "search_query": "..."}`
+
 // Web search prompt template
 const WebSearchPromptTemplate = `You are a helpful assistant. Use the following web search results to answer the user's question.
 Cite sources when possible using [1], [2], etc.
@@ -48,3 +113,25 @@ Instructions:
 const WebContextMessageTemplate = `Web search results for additional context (cite using [1], [2], etc. if relevant):
 
 %s`
+
+// System prompt addition for --json-mode when the prompt does not mention JSON
+const JSONModeHint = "Respond with a single valid JSON object and nothing else."
+
+// Tool result for calls skipped when the user paused a turn with Ctrl+C
+const SkippedToolCallMessage = "Not run: the user paused the turn before this call."
+
+// Tool result for command tool calls in --safe-mode
+const SafeModeToolMessage = "Not run: command execution is disabled in this session. Answer without running commands."
+
+// Tool result for command and file-write tool calls in --dry-run
+const DryRunToolMessage = "Dry run: not executed. The user is previewing what would run; nothing was changed."
+
+// Planning system prompt used by --plan before the first tool-using turn
+const PlanPrompt = `Before doing anything, write a plan for the user's request as numbered steps.
+For each step that would run a command, name the command. Do not call any tools and do not carry out any step yet.
+The user will review the plan and approve it before you start.
+
+Output ONLY the numbered plan.`
+
+// Message added after the user approves a --plan plan
+const PlanApprovedMessage = "The plan is approved. Carry it out step by step."
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// applyDefaultFlags puts the AZURE_AI_FLAGS / default_flags defaults before args, so
+// flags given on the command line are parsed later and win. A default for a flag that
+// takes a value is dropped when args give that flag too, so repeatable and list flags
+// are replaced rather than merged. Subcommands have their own flags and are left alone.
+func applyDefaultFlags(rootCmd *cobra.Command, defaults, args []string) {
+	if len(defaults) == 0 {
+		return
+	}
+	if c, _, err := rootCmd.Find(args); err != nil || c != rootCmd {
+		return
+	}
+	rootCmd.SetArgs(append(withoutOverridden(rootCmd.Flags(), defaults, args), args...))
+}
+
+// configFlagPath returns the --config value in args, if any
+func configFlagPath(args []string) (string, bool) {
+	for i := 0; i < len(args) && args[i] != "--"; i++ {
+		if path, ok := strings.CutPrefix(args[i], "--config="); ok {
+			return path, true
+		}
+		if args[i] == "--config" && i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	return "", false
+}
+
+// withoutOverridden returns defaults minus the value flags (and their values) that args set
+func withoutOverridden(flags *pflag.FlagSet, defaults, args []string) []string {
+	given := map[string]bool{}
+	for i := 0; i < len(args) && args[i] != "--"; i++ {
+		names, valueNext := flagNames(flags, args[i])
+		for _, name := range names {
+			given[name] = true
+		}
+		if valueNext {
+			i++
+		}
+	}
+
+	var kept []string
+	for i := 0; i < len(defaults); i++ {
+		names, valueNext := flagNames(flags, defaults[i])
+		end := i + 1
+		if valueNext && end < len(defaults) {
+			end++
+		}
+		if !overridden(flags, names, given) {
+			kept = append(kept, defaults[i:end]...)
+		}
+		i = end - 1
+	}
+	return kept
+}
+
+// overridden reports whether any of the value flags in names was given on the command line.
+// Boolean flags are never dropped: the later one simply wins.
+func overridden(flags *pflag.FlagSet, names []string, given map[string]bool) bool {
+	for _, name := range names {
+		if f := flags.Lookup(name); f != nil && f.NoOptDefVal == "" && given[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// flagNames returns the names of the flags set by arg ("--name", "--name=v", "-x" or a
+// group like "-sr"), and whether the next argument is the value of the last of them
+func flagNames(flags *pflag.FlagSet, arg string) ([]string, bool) {
+	if long, ok := strings.CutPrefix(arg, "--"); ok && long != "" {
+		name, _, hasValue := strings.Cut(long, "=")
+		f := flags.Lookup(name)
+		if f == nil {
+			return nil, false
+		}
+		return []string{f.Name}, !hasValue && f.NoOptDefVal == ""
+	}
+	short, ok := strings.CutPrefix(arg, "-")
+	if !ok || short == "" {
+		return nil, false
+	}
+	var names []string
+	for i, c := range short {
+		f := flags.ShorthandLookup(string(c))
+		if f == nil {
+			return names, false
+		}
+		names = append(names, f.Name)
+		if f.NoOptDefVal == "" {
+			// The rest of the group, or else the next argument, is the value
+			return names, i+1 == len(short)
+		}
+	}
+	return names, false
+}
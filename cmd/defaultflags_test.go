@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// flagValues are the flags of newFlagTestCmd after parsing
+type flagValues struct {
+	system  string
+	model   string
+	stream  bool
+	domains []string
+}
+
+func newFlagTestCmd(v *flagValues) *cobra.Command {
+	cmd := &cobra.Command{Use: "azure-ai", Run: func(*cobra.Command, []string) {}}
+	cmd.Flags().StringVar(&v.system, "system", "", "")
+	cmd.Flags().StringVarP(&v.model, "model", "m", "", "")
+	cmd.Flags().BoolVarP(&v.stream, "stream", "s", false, "")
+	cmd.Flags().StringSliceVar(&v.domains, "include-domain", nil, "")
+	cmd.Flags().String("config", "", "")
+	return cmd
+}
+
+func TestDefaultFlagsPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	probed := filepath.Join(dir, "probed.yaml")
+	named := filepath.Join(dir, "named.yaml")
+	files := map[string]string{
+		probed: "default_flags: -m probed-model\n",
+		named:  "default_flags: --system 'from named file' --include-domain file.com\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		env  string
+		args []string
+		want flagValues
+	}{
+		{"probed config file", "", []string{}, flagValues{model: "probed-model"}},
+		{"--config file replaces probed file", "", []string{"--config", named},
+			flagValues{system: "from named file", domains: []string{"file.com"}}},
+		{"env replaces config file", `-s --system "from env" -m env-model`, []string{"--config=" + named},
+			flagValues{system: "from env", model: "env-model", stream: true}},
+		{"explicit flag beats env", `--system "from env" -mgpt-4o`, []string{"--system", "typed", "-m", "typed-model"},
+			flagValues{system: "typed", model: "typed-model"}},
+		{"explicit bool beats env", "-s", []string{"--stream=false"}, flagValues{}},
+		{"explicit list replaces default", "--include-domain a.com,b.com", []string{"--include-domain", "c.com"},
+			flagValues{domains: []string{"c.com"}}},
+		{"default list kept without explicit one", "--include-domain a.com", []string{"-s"},
+			flagValues{stream: true, domains: []string{"a.com"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(config.EnvDefaultFlags, tt.env)
+			cfg, err := config.NewConfigFromFile(probed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if path, ok := configFlagPath(tt.args); ok {
+				if err := cfg.LoadFile(path); err != nil {
+					t.Fatal(err)
+				}
+			}
+			defaults, err := cfg.DefaultFlags()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got flagValues
+			cmd := newFlagTestCmd(&got)
+			cmd.SetArgs(tt.args)
+			applyDefaultFlags(cmd, defaults, tt.args)
+			if err := cmd.Execute(); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("flags = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", ""},
+		{
+			"changed line",
+			"a\nb\nc\n",
+			"a\nB\nc\n",
+			"--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			"new file",
+			"",
+			"x\ny\n",
+			"--- old\n+++ new\n@@ -0,0 +1,2 @@\n+x\n+y\n",
+		},
+		{
+			"missing final newline",
+			"a\n",
+			"a\nb",
+			"--- old\n+++ new\n@@ -1,1 +1,2 @@\n a\n+b\n\\ No newline at end of file\n",
+		},
+		{
+			"distant changes get separate hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			"one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			"--- old\n+++ new\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("old", "new", tt.old, tt.new); got != tt.want {
+				t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffStat(t *testing.T) {
+	// A removed "--" line must not be mistaken for the file header
+	diff := unifiedDiff("a/x", "b/x", "--\nkeep\n", "keep\nnew\nnewer\n")
+	added, removed := diffStat(diff)
+	if added != 2 || removed != 1 {
+		t.Errorf("diffStat() = +%d -%d, want +2 -1\n%s", added, removed, diff)
+	}
+	if !strings.HasPrefix(diff, "--- a/x\n+++ b/x\n") {
+		t.Errorf("diff header = %q", diff)
+	}
+}
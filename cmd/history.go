@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/display"
+)
+
+// summaryCutoff returns the index splitting messages into [1:cut] to summarize and [cut:] to keep.
+// The kept tail uses at most half the budget so summarization is not needed again right away,
+// and never starts with a tool result whose tool call would be summarized away.
+// Returns 0 when the history is within budget or there is nothing to summarize.
+func summaryCutoff(messages []api.Message, maxTokens int) int {
+	if maxTokens <= 0 || len(messages) < 3 || api.EstimateTokens(messages) <= maxTokens {
+		return 0
+	}
+
+	cut := len(messages)
+	kept := 0
+	for cut > 2 {
+		size := api.EstimateTokens(messages[cut-1 : cut])
+		if kept+size > maxTokens/2 {
+			break
+		}
+		kept += size
+		cut--
+	}
+	for cut < len(messages) && messages[cut].Role == "tool" {
+		cut++
+	}
+	if cut >= len(messages) {
+		// Always keep the latest message so the model has something to answer
+		cut = len(messages) - 1
+	}
+	if cut <= 1 {
+		return 0
+	}
+	return cut
+}
+
+// formatTranscript renders messages as plain text for the summarizer
+func formatTranscript(messages []api.Message) string {
+	var sb strings.Builder
+	for _, m := range messages {
+		content := m.Content
+		if content == "" && len(m.ToolCalls) > 0 {
+			var calls []string
+			for _, tc := range m.ToolCalls {
+				calls = append(calls, fmt.Sprintf("%s(%s)", tc.Function.Name, tc.Function.Arguments))
+			}
+			content = "called " + strings.Join(calls, ", ")
+		}
+		if len(m.Images) > 0 {
+			content += fmt.Sprintf(" [%d image(s)]", len(m.Images))
+		}
+		fmt.Fprintf(&sb, "%s: %s\n\n", m.Role, content)
+	}
+	return sb.String()
+}
+
+// summarizeHistory replaces the oldest turns with a model-written summary when the
+// history exceeds --max-history-tokens. Errors leave the history untouched.
+func (app *App) summarizeHistory(messages *[]api.Message, client *api.AzureClient) {
+	cut := summaryCutoff(*messages, app.cfg.MaxHistoryTokens)
+	if cut == 0 {
+		return
+	}
+
+	before := api.EstimateTokens(*messages)
+	old := (*messages)[1:cut]
+
+	sp := display.NewSpinner("Summarizing history...")
+	sp.Start()
+	resp, err := client.QueryWithHistory([]api.Message{
+		{Role: "system", Content: HistorySummaryPrompt},
+		{Role: "user", Content: formatTranscript(old)},
+	})
+	sp.Stop()
+	if err != nil {
+		display.ShowError(fmt.Sprintf("History summarization failed: %v", err))
+		return
+	}
+	summary := strings.TrimSpace(resp.GetContent())
+	if summary == "" {
+		return
+	}
+
+	compacted := make([]api.Message, 0, len(*messages)-cut+2)
+	compacted = append(compacted, (*messages)[0])
+	compacted = append(compacted, api.Message{
+		Role:    "system",
+		Content: fmt.Sprintf(HistorySummaryMessageTemplate, summary),
+	})
+	compacted = append(compacted, (*messages)[cut:]...)
+	*messages = compacted
+
+	display.ShowHistorySummarized(len(old), before, api.EstimateTokens(compacted))
+}
+
+// trimHistory keeps the system prompt plus the newest messages that fit --max-context
+// together with the message about to be sent, when --trim-context is set. An assistant
+// tool call and its results are dropped together so the API never sees an orphaned tool
+// message. If next alone doesn't fit, all history is dropped and the request warns.
+func (app *App) trimHistory(messages *[]api.Message, next string) {
+	if app.cfg.MaxContextTokens <= 0 || !app.cfg.TrimContext {
+		return
+	}
+	before := api.EstimateTokens(*messages)
+	withNext := append((*messages)[:len(*messages):len(*messages)], api.Message{Role: "user", Content: next})
+	trimmed, dropped := api.TrimToTokens(withNext, app.cfg.MaxContextTokens)
+	if dropped == 0 {
+		return
+	}
+	*messages = trimmed[:len(trimmed)-1]
+	display.ShowHistoryTrimmed(dropped, before, api.EstimateTokens(*messages))
+}
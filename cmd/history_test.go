@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+)
+
+func TestSummaryCutoff(t *testing.T) {
+	long := strings.Repeat("x", 400) // ~100 tokens + overhead
+	history := []api.Message{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: long},
+		{Role: "assistant", Content: long},
+		{Role: "user", Content: long},
+		{Role: "assistant", ToolCalls: []api.ToolCall{{ID: "call_1"}}},
+		{Role: "tool", Content: long, ToolCallID: "call_1"},
+		{Role: "assistant", Content: long},
+	}
+
+	tests := []struct {
+		name      string
+		maxTokens int
+		want      int
+	}{
+		{"disabled", 0, 0},
+		{"within budget", 10000, 0},
+		{"keeps half the budget", 450, 4},
+		{"skips orphaned tool result", 418, 6},
+		{"always keeps latest message", 50, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summaryCutoff(history, tt.maxTokens); got != tt.want {
+				t.Errorf("summaryCutoff(%d) = %d, want %d", tt.maxTokens, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimHistory(t *testing.T) {
+	long := strings.Repeat("x", 400) // ~100 tokens + overhead
+	history := []api.Message{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: long},
+		{Role: "assistant", ToolCalls: []api.ToolCall{{ID: "call_1"}}},
+		{Role: "tool", Content: long, ToolCallID: "call_1"},
+		{Role: "assistant", Content: long},
+		{Role: "user", Content: long},
+		{Role: "assistant", Content: long},
+	}
+
+	tests := []struct {
+		name       string
+		maxContext int
+		trim       bool
+		wantLen    int
+	}{
+		{"disabled", 0, true, 7},
+		{"warn only without --trim-context", 400, false, 7},
+		{"within budget", 10000, true, 7},
+		{"drops tool call with its result", 400, true, 4},
+		{"keeps the latest message", 120, true, 2},
+		{"next message alone over budget", 10, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &App{cfg: &config.Config{MaxContextTokens: tt.maxContext, TrimContext: tt.trim}}
+			messages := append([]api.Message(nil), history...)
+			app.trimHistory(&messages, "next question")
+			if len(messages) != tt.wantLen {
+				t.Fatalf("trimHistory(%d) left %d messages, want %d", tt.maxContext, len(messages), tt.wantLen)
+			}
+			if messages[0].Role != "system" || (len(messages) > 1 && messages[1].Role == "tool") {
+				t.Errorf("trimHistory(%d) = %+v, want the system prompt first and no orphaned tool result", tt.maxContext, messages)
+			}
+		})
+	}
+}
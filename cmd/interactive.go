@@ -2,8 +2,9 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/elk-language/go-prompt"
@@ -11,19 +12,28 @@ import (
 	"github.com/quocvuong92/azure-ai-cli/internal/api"
 	"github.com/quocvuong92/azure-ai-cli/internal/config"
 	"github.com/quocvuong92/azure-ai-cli/internal/display"
-	"github.com/quocvuong92/azure-ai-cli/internal/executor"
 )
 
 // InteractiveSession holds the state for interactive mode
 type InteractiveSession struct {
 	app      *App
 	client   *api.AzureClient
-	exec     *executor.Executor
+	exec     *shellExecutor
 	messages []api.Message
 	exitFlag bool
+	// pendingImages are image data URLs attached to the next chat message
+	pendingImages []string
+	// promptTemplate is the prompt prefix before placeholder expansion
+	promptTemplate string
+	// staged holds lines collected with /stage until /send submits them as one message
+	staged []string
+	// usage sums the tokens of every model request since the session started or /clear
+	usage    api.Usage
+	requests int
 }
 
-// completer provides auto-suggestions for commands
+// completer provides auto-suggestions for commands, and for the arguments of
+// commands that complete them (such as file paths)
 func (s *InteractiveSession) completer(d prompt.Document) ([]prompt.Suggest, istrings.RuneNumber, istrings.RuneNumber) {
 	// Only show suggestions when input starts with "/"
 	text := d.TextBeforeCursor()
@@ -35,24 +45,12 @@ func (s *InteractiveSession) completer(d prompt.Document) ([]prompt.Suggest, ist
 		return []prompt.Suggest{}, startIndex, endIndex
 	}
 
-	suggestions := []prompt.Suggest{
-		{Text: "/exit", Description: "Exit interactive mode"},
-		{Text: "/quit", Description: "Exit interactive mode"},
-		{Text: "/q", Description: "Exit interactive mode"},
-		{Text: "/clear", Description: "Clear conversation history"},
-		{Text: "/c", Description: "Clear conversation history"},
-		{Text: "/help", Description: "Show available commands"},
-		{Text: "/h", Description: "Show available commands"},
-		{Text: "/web on", Description: "Enable auto web search"},
-		{Text: "/web off", Description: "Disable auto web search"},
-		{Text: "/web tavily", Description: "Use Tavily search provider"},
-		{Text: "/web linkup", Description: "Use Linkup search provider"},
-		{Text: "/web brave", Description: "Use Brave search provider"},
-		{Text: "/model", Description: "Show/switch model"},
-		{Text: "/allow-dangerous", Description: "Enable dangerous commands (with confirmation)"},
-		{Text: "/show-permissions", Description: "Show command execution permissions"},
+	if suggestions, arg, ok := argumentSuggestions(text); ok {
+		return suggestions, endIndex - istrings.RuneCountInString(arg), endIndex
 	}
 
+	suggestions := commandSuggestions()
+
 	return prompt.FilterHasPrefix(suggestions, w, true), startIndex, endIndex
 }
 
@@ -66,20 +64,45 @@ func (app *App) runInteractive() {
 	fmt.Println("Commands auto-complete as you type")
 	fmt.Println()
 
+	app.registerTools()
+	if app.cfg.SafeMode {
+		fmt.Println("Safe mode: command execution is disabled")
+		fmt.Println()
+	}
+
+	exec := newShellExecutor()
+	exec.EnableCache(!app.cfg.NoCommandCache)
+	exec.GetPermissionManager().SetDryRun(app.cfg.DryRun)
+	if app.cfg.ExecTimeout > 0 {
+		exec.SetTimeout(app.cfg.ExecTimeout)
+	}
+	if path := app.cfg.GetAllowlistFile(); path != "" {
+		if err := exec.GetPermissionManager().LoadAllowlistFile(path); err != nil {
+			display.ShowError(fmt.Sprintf("Failed to load allowlist %s: %v", path, err))
+		}
+	}
+	if path := app.cfg.GetDenylistFile(); path != "" {
+		if err := exec.GetPermissionManager().LoadDenylistFile(path); err != nil {
+			display.ShowError(fmt.Sprintf("Failed to load denylist %s: %v", path, err))
+		}
+	}
+
 	session := &InteractiveSession{
 		app:    app,
-		client: api.NewAzureClient(app.cfg),
-		exec:   executor.NewExecutor(),
+		client: app.getAzureClient(),
+		exec:   exec,
 		messages: []api.Message{
-			{Role: "system", Content: config.DefaultSystemMessage},
+			{Role: "system", Content: app.buildSystemPrompt(app.cfg.GetSystemPrompt())},
 		},
-		exitFlag: false,
+		exitFlag:       false,
+		promptTemplate: app.cfg.GetPromptPrefix(),
 	}
+	session.client.SetUsageCallback(session.addUsage)
 
 	p := prompt.New(
 		session.executor,
 		prompt.WithCompleter(session.completer),
-		prompt.WithPrefix("> "),
+		prompt.WithPrefixCallback(session.promptPrefix),
 		prompt.WithTitle("Azure AI CLI"),
 		prompt.WithPrefixTextColor(prompt.Green),
 		prompt.WithSuggestionBGColor(prompt.DarkGray),
@@ -118,6 +141,25 @@ func (app *App) runInteractive() {
 	p.Run()
 }
 
+// promptPrefix renders the prompt before each input line so it follows /model and /web changes
+func (s *InteractiveSession) promptPrefix() string {
+	cfg := s.app.cfg
+	return formatPromptPrefix(s.promptTemplate, cfg.Model, cfg.WebSearchProvider, cfg.WebSearch)
+}
+
+// formatPromptPrefix expands the {model}, {provider} and {web} (on/off) placeholders
+func formatPromptPrefix(tmpl, model, provider string, web bool) string {
+	webStatus := "off"
+	if web {
+		webStatus = "on"
+	}
+	return strings.NewReplacer(
+		"{model}", model,
+		"{provider}", provider,
+		"{web}", webStatus,
+	).Replace(tmpl)
+}
+
 // executor handles the execution of each input line
 func (s *InteractiveSession) executor(input string) {
 	// Check if we should exit
@@ -130,100 +172,279 @@ func (s *InteractiveSession) executor(input string) {
 		return
 	}
 
+	s.app.timings.reset()
+	defer s.app.showTimings()
+
 	// Handle commands
 	if strings.HasPrefix(input, "/") {
-		if s.app.handleCommand(input, &s.messages, s.client, s.exec) {
+		if s.handleCommand(input) {
 			s.exitFlag = true
 		}
 		return
 	}
 
-	// Web search mode: automatically search for every message
-	if s.app.cfg.WebSearch {
-		s.app.handleWebSearch(input, &s.messages, s.client, s.exec)
-		return
+	s.send(input)
+}
+
+// send sends a chat message, via web search when enabled, and records the exchange in history
+func (s *InteractiveSession) send(input string) {
+	s.app.summarizeHistory(&s.messages, s.client)
+	s.app.trimHistory(&s.messages, input)
+
+	// Web search mode: automatically search for every message.
+	// Messages with pasted images skip the search so the images reach the model.
+	// With --smart-web, follow-ups the model can answer from context skip it too;
+	// --search-intent decides that and writes the search query in one call.
+	if s.app.cfg.WebSearch && len(s.pendingImages) == 0 {
+		search, searchQuery := true, ""
+		if s.app.cfg.SearchIntent {
+			search, searchQuery = s.app.searchIntent(input, s.messages, s.client)
+		} else if s.app.cfg.SmartWeb {
+			search = s.app.needsWebSearch(input, s.messages, s.client)
+		}
+		if search {
+			s.app.handleWebSearch(input, searchQuery, &s.messages, s.client, s.exec)
+			return
+		}
 	}
 
 	// Regular chat with tool support
-	s.messages = append(s.messages, api.Message{Role: "user", Content: input})
+	s.messages = append(s.messages, api.Message{Role: "user", Content: input, Images: s.pendingImages})
 	fmt.Println()
-	response, err := s.app.sendInteractiveMessageWithTools(s.client, s.exec, &s.messages)
+	response, added, err := s.app.sendInteractiveMessageWithTools(s.client, s.exec, &s.messages, nil)
 	if err != nil {
 		display.ShowError(err.Error())
+		// Drop the tool exchange and the message itself so the turn can be retried
+		s.messages = removeMessages(s.messages, added)
 		s.messages = s.messages[:len(s.messages)-1]
 		return
 	}
+	s.pendingImages = nil
 	if response != "" {
 		s.messages = append(s.messages, api.Message{Role: "assistant", Content: response})
 	}
 	fmt.Println()
 }
 
-func (app *App) handleCommand(input string, messages *[]api.Message, client *api.AzureClient, exec *executor.Executor) bool {
-	parts := strings.SplitN(input, " ", 2)
-	cmd := strings.ToLower(parts[0])
+// setSystemPrompt replaces the system message, inserting one if the history has none.
+// /clear keeps the new prompt too.
+func (s *InteractiveSession) setSystemPrompt(prompt string) {
+	s.app.cfg.SystemPrompt = prompt
+	system := api.Message{Role: "system", Content: s.app.buildSystemPrompt(prompt)}
+	if len(s.messages) > 0 && s.messages[0].Role == "system" {
+		s.messages[0] = system
+		return
+	}
+	s.messages = append([]api.Message{system}, s.messages...)
+}
+
+// lastUserMessage returns the index of the most recent user message, or -1 if there is none
+func lastUserMessage(messages []api.Message) int {
+	for i := len(messages) - 1; i > 0; i-- {
+		if messages[i].Role == "user" {
+			return i
+		}
+	}
+	return -1
+}
 
-	switch cmd {
-	case "/exit", "/quit", "/q":
-		fmt.Println("Goodbye!")
-		return true
+// undo drops the last user message and everything after it: tool calls, their
+// results and the answer
+func (s *InteractiveSession) undo() {
+	idx := replayStart(s.messages, false)
+	if idx < 0 {
+		fmt.Println("Nothing to undo: only the system prompt remains.")
+		return
+	}
+	removed := len(s.messages) - idx
+	last := s.messages[lastUserMessage(s.messages)]
+	s.messages = s.messages[:idx]
+	fmt.Printf("Removed %d message(s), starting with: %s\n", removed, truncateWithEllipsis(last.Content, 60))
+}
 
-	case "/clear", "/c":
-		*messages = []api.Message{
-			{Role: "system", Content: config.DefaultSystemMessage},
+// editLast lets the user correct the last message, drops it and everything after it, and resends it.
+// The new text is taken from arg when given, otherwise from $EDITOR.
+func (s *InteractiveSession) editLast(arg string) {
+	idx := lastUserMessage(s.messages)
+	if idx < 0 {
+		fmt.Println("No previous message to edit.")
+		return
+	}
+	last := s.messages[idx]
+
+	edited := strings.TrimSpace(arg)
+	if edited == "" {
+		var err error
+		edited, err = editInEditor(last.Content)
+		if err != nil {
+			display.ShowError(fmt.Sprintf("Failed to edit message: %v", err))
+			return
 		}
-		fmt.Println("Conversation cleared.")
-
-	case "/help", "/h":
-		fmt.Println("\nCommands:")
-		fmt.Printf("  %-24s %s\n", "/exit, /quit, /q", "Exit interactive mode")
-		fmt.Printf("  %-24s %s\n", "/clear, /c", "Clear conversation history")
-		fmt.Printf("  %-24s %s\n", "/web <query>", "Search web and ask about results")
-		fmt.Printf("  %-24s %s\n", "/web on", "Enable auto web search for all messages")
-		fmt.Printf("  %-24s %s\n", "/web off", "Disable auto web search")
-		fmt.Printf("  %-24s %s\n", "/web <provider>", "Switch provider (tavily, linkup, brave)")
-		fmt.Printf("  %-24s %s\n", "/model <name>", "Switch model")
-		fmt.Printf("  %-24s %s\n", "/model", "Show current model")
-		fmt.Printf("  %-24s %s\n", "/allow-dangerous", "Allow dangerous commands (with confirmation)")
-		fmt.Printf("  %-24s %s\n", "/show-permissions", "Show command execution permissions")
-		fmt.Printf("  %-24s %s\n", "/help, /h", "Show this help")
-		fmt.Println()
+	}
+	if edited == "" {
+		fmt.Println("Edit cancelled (empty message).")
+		return
+	}
+
+	s.messages = s.messages[:idx]
+	if len(last.Images) > 0 {
+		s.pendingImages = append(append([]string{}, last.Images...), s.pendingImages...)
+	}
+	fmt.Printf("Resending: %s\n", edited)
+	s.send(edited)
+}
+
+// replayStart returns where a replay cuts the history: at the last user message,
+// or with all at the first one, including the web search context sent just
+// before it (send searches again). Returns -1 when there is nothing to replay.
+func replayStart(messages []api.Message, all bool) int {
+	start := lastUserMessage(messages)
+	if all {
+		for i := 1; i < len(messages); i++ {
+			if messages[i].Role == "user" {
+				start = i
+				break
+			}
+		}
+	}
+	if start < 0 {
+		return -1
+	}
+
+	webContextPrefix, _, _ := strings.Cut(WebContextMessageTemplate, "\n")
+	for start > 1 && messages[start-1].Role == "system" && strings.HasPrefix(messages[start-1].Content, webContextPrefix) {
+		start--
+	}
+	return start
+}
+
+// replay re-sends the last user message, or with all every user message in order,
+// under the current system prompt and model, replacing the answers they produced
+func (s *InteractiveSession) replay(all bool) {
+	start := replayStart(s.messages, all)
+	if start < 0 {
+		fmt.Println("No previous message to replay.")
+		return
+	}
 
-	case "/model":
-		app.handleModelCommand(parts)
+	var turns []api.Message
+	for _, msg := range s.messages[start:] {
+		if msg.Role == "user" {
+			turns = append(turns, msg)
+		}
+	}
+	s.messages = s.messages[:start]
+
+	// Images attached for the next message wait until the replay is done
+	pending := s.pendingImages
+	for i, turn := range turns {
+		if len(turns) > 1 {
+			fmt.Printf("Replaying %d/%d: %s\n", i+1, len(turns), turn.Content)
+		} else {
+			fmt.Printf("Replaying: %s\n", turn.Content)
+		}
+		s.pendingImages = append([]string(nil), turn.Images...)
+		s.send(turn.Content)
+	}
+	s.pendingImages = pending
+}
 
-	case "/web":
-		app.handleWebCommand(parts, messages, client, exec)
+// addUsage adds one request's token usage to the session totals
+func (s *InteractiveSession) addUsage(usage api.Usage) {
+	s.usage.PromptTokens += usage.PromptTokens
+	s.usage.CompletionTokens += usage.CompletionTokens
+	s.usage.TotalTokens += usage.TotalTokens
+	s.requests++
+}
 
-	case "/allow-dangerous":
-		exec.GetPermissionManager().EnableDangerous()
-		fmt.Println("⚠️  Dangerous commands enabled for this session")
-		fmt.Println("Note: You will still be asked to confirm before execution")
+// resetUsage clears the session token totals
+func (s *InteractiveSession) resetUsage() {
+	s.usage = api.Usage{}
+	s.requests = 0
+}
 
-	case "/show-permissions":
-		settings := exec.GetPermissionManager().GetSettings()
-		display.ShowPermissionSettings(settings)
+// stage appends a line to the staged message
+func (s *InteractiveSession) stage(text string) {
+	s.staged = append(s.staged, text)
+	fmt.Printf("Staged line %d. Use /send to submit, /staged to review, /discard to clear.\n", len(s.staged))
+}
 
-	default:
-		fmt.Printf("Unknown command: %s\n", cmd)
-		fmt.Println("Type /help for available commands")
+// showStaged prints the staged lines with their numbers
+func (s *InteractiveSession) showStaged() {
+	if len(s.staged) == 0 {
+		fmt.Println("Nothing staged.")
+		return
+	}
+	for i, line := range s.staged {
+		fmt.Printf("%3d  %s\n", i+1, line)
+	}
+}
+
+// sendStaged submits the staged lines as a single user message
+func (s *InteractiveSession) sendStaged() {
+	if len(s.staged) == 0 {
+		fmt.Println("Nothing staged. Use /stage <text> first.")
+		return
+	}
+	message := strings.Join(s.staged, "\n")
+	s.staged = nil
+	s.send(message)
+}
+
+// handleClearKeep handles "/clear keep <N>", keeping the system message and the last N messages
+func (app *App) handleClearKeep(arg string, messages *[]api.Message) {
+	fields := strings.Fields(arg)
+	if len(fields) != 2 || strings.ToLower(fields[0]) != "keep" {
+		fmt.Println("Usage: /clear | /clear keep <N>")
+		return
+	}
+	n, err := strconv.Atoi(fields[1])
+	if err != nil || n < 0 {
+		fmt.Printf("Invalid number of messages: %s\n", fields[1])
+		return
+	}
+
+	var removed int
+	*messages, removed = keepRecentMessages(*messages, n)
+	fmt.Printf("Removed %d message(s), kept %d.\n", removed, len(*messages)-1)
+}
+
+// keepRecentMessages keeps the leading system message plus the last n messages.
+// Tool results whose originating tool call was dropped are removed too, since the API rejects them.
+// Returns the trimmed slice and how many messages were removed.
+func keepRecentMessages(messages []api.Message, n int) ([]api.Message, int) {
+	if len(messages) == 0 {
+		return messages, 0
+	}
+	history := messages[1:]
+	if n < len(history) {
+		history = history[len(history)-n:]
+	}
+	for len(history) > 0 && history[0].Role == "tool" {
+		history = history[1:]
 	}
 
-	return false
+	kept := append([]api.Message{messages[0]}, history...)
+	return kept, len(messages) - len(kept)
 }
 
-func (app *App) handleModelCommand(parts []string) {
+func (app *App) handleModelCommand(parts []string, client *api.AzureClient) {
 	if len(parts) > 1 {
 		newModel := strings.TrimSpace(parts[1])
-		if newModel == "" {
+		if strings.ToLower(newModel) == "list" {
+			app.showModelList(client)
+		} else if newModel == "" {
 			fmt.Printf("Current model: %s\n", app.cfg.Model)
 			if len(app.cfg.AvailableModels) > 0 {
 				fmt.Printf("Available: %s\n", app.cfg.GetAvailableModelsString())
 			}
-		} else if len(app.cfg.AvailableModels) > 0 && !app.cfg.ValidateModel(newModel) {
+		} else if app.cfg.IsUnlistedModel(newModel) && !app.cfg.AllowUnlistedModel {
 			fmt.Printf("Invalid model: %s\n", newModel)
 			fmt.Printf("Available: %s\n", app.cfg.GetAvailableModelsString())
 		} else {
+			if app.cfg.IsUnlistedModel(newModel) {
+				display.ShowUnlistedModel(newModel, app.cfg.GetAvailableModelsString())
+			}
 			app.cfg.Model = newModel
 			fmt.Printf("Switched to model: %s\n", app.cfg.Model)
 		}
@@ -235,15 +456,36 @@ func (app *App) handleModelCommand(parts []string) {
 	}
 }
 
-func (app *App) handleWebCommand(parts []string, messages *[]api.Message, client *api.AzureClient, exec *executor.Executor) {
+// showModelList displays the configured models with their cached capabilities
+func (app *App) showModelList(client *api.AzureClient) {
+	models := app.cfg.AvailableModels
+	if len(models) == 0 {
+		models = []string{app.cfg.Model}
+	}
+
+	infos := make([]display.ModelCapability, len(models))
+	for i, m := range models {
+		caps := client.GetCapabilities(m)
+		infos[i] = display.ModelCapability{
+			Name:      m,
+			Current:   m == app.cfg.Model,
+			Tools:     caps.Tools,
+			Vision:    caps.Vision,
+			Streaming: caps.Streaming,
+		}
+	}
+	display.ShowModelCapabilities(infos)
+}
+
+func (app *App) handleWebCommand(parts []string, messages *[]api.Message, client *api.AzureClient, exec *shellExecutor) {
 	if len(parts) < 2 {
 		status := "off"
 		if app.cfg.WebSearch {
 			status = fmt.Sprintf("on (provider: %s)", app.cfg.WebSearchProvider)
 		}
 		fmt.Printf("Web search: %s\n", status)
-		fmt.Println("Available providers: tavily, linkup, brave")
-		fmt.Println("Usage: /web <query> | /web on | /web off | /web provider <name>")
+		fmt.Printf("Available providers: %s\n", strings.Join(config.SearchProviders, ", "))
+		fmt.Println("Usage: /web <query> | /web @<provider> <query> | /web on | /web off | /web provider <name>")
 		return
 	}
 
@@ -260,108 +502,103 @@ func (app *App) handleWebCommand(parts []string, messages *[]api.Message, client
 		providerParts := strings.SplitN(parts[1], " ", 2)
 		if len(providerParts) > 1 {
 			newProvider := strings.ToLower(strings.TrimSpace(providerParts[1]))
-			if newProvider == "tavily" || newProvider == "linkup" || newProvider == "brave" {
+			if config.IsValidSearchProvider(newProvider) {
 				app.cfg.WebSearchProvider = newProvider
 				fmt.Printf("Web search provider changed to: %s\n", app.cfg.WebSearchProvider)
 			} else {
 				fmt.Printf("Invalid provider: %s\n", newProvider)
-				fmt.Println("Available providers: tavily, linkup, brave")
+				fmt.Printf("Available providers: %s\n", strings.Join(config.SearchProviders, ", "))
 			}
 		} else {
 			fmt.Printf("Current provider: %s\n", app.cfg.WebSearchProvider)
-			fmt.Println("Available providers: tavily, linkup, brave")
+			fmt.Printf("Available providers: %s\n", strings.Join(config.SearchProviders, ", "))
 			fmt.Println("Usage: /web provider <name>")
 		}
-	case "tavily", "linkup", "brave":
-		// Allow shorthand: /web tavily, /web linkup, /web brave
+	case "tavily", "linkup", "brave", "perplexity", "searxng":
+		// Allow shorthand: /web tavily, /web linkup, /web brave, /web perplexity, /web searxng
 		app.cfg.WebSearchProvider = strings.ToLower(arg)
 		fmt.Printf("Web search provider changed to: %s\n", app.cfg.WebSearchProvider)
 	default:
-		app.handleWebSearch(arg, messages, client, exec)
+		app.handleWebSearch(arg, "", messages, client, exec)
 	}
 }
 
-func (app *App) sendInteractiveMessage(client *api.AzureClient, messages []api.Message) (string, error) {
-	if app.cfg.Stream {
-		var fullContent strings.Builder
-		firstChunk := true
-
-		sp := display.NewSpinner("Thinking...")
-		sp.Start()
-
-		err := client.QueryStreamWithHistory(messages,
-			func(content string) {
-				if firstChunk {
-					firstChunk = false
-					if app.cfg.Render {
-						sp.UpdateMessage("Receiving...")
-					} else {
-						sp.Stop()
-					}
-				}
-				if app.cfg.Render {
-					fullContent.WriteString(content)
-				} else {
-					fmt.Print(content)
-				}
-			},
-			nil,
-		)
-
-		sp.Stop()
-
+// sendInteractiveMessageWithTools runs the tool-calling loop. If sp is non-nil it is reused
+// for the first request (e.g. continuing a web search spinner) and stopped once a response arrives.
+// It returns the final answer and the indices of the messages it appended to history (the
+// approved plan and the tool calls and results), which are left in place on error as well.
+func (app *App) sendInteractiveMessageWithTools(client *api.AzureClient, exec *shellExecutor, messages *[]api.Message, sp *display.Spinner) (string, []int, error) {
+	// Ctrl+C pauses the turn at the next step so it can be steered or aborted
+	ctx, interrupts := watchInterrupts(context.Background())
+	defer interrupts.stop()
+	defer interrupts.dropPending(ctx)
+	tools := api.GetDefaultTools()
+	app.turnSearches = 0
+	defer exec.ClearCache() // Cached command results only live for one turn
+	var added []int
+
+	// --plan: get the plan approved before the first turn may use tools
+	if app.cfg.Plan && !app.planApproved {
+		planAdded, err := app.approvePlan(ctx, client, messages, sp)
+		added = append(added, planAdded...)
 		if err != nil {
-			return "", err
+			return "", added, err
 		}
-
-		if app.cfg.Render {
-			display.ShowContentRendered(fullContent.String())
-			return fullContent.String(), nil
-		}
-		fmt.Println()
-		return fullContent.String(), nil
-	}
-
-	// Non-streaming
-	sp := display.NewSpinner("Thinking...")
-	sp.Start()
-
-	resp, err := client.QueryWithHistory(messages)
-	sp.Stop()
-
-	if err != nil {
-		return "", err
-	}
-
-	content := resp.GetContent()
-	if app.cfg.Render {
-		display.ShowContentRendered(content)
-	} else {
-		display.ShowContent(content)
+		sp = nil
 	}
 
-	return content, nil
-}
-
-func (app *App) sendInteractiveMessageWithTools(client *api.AzureClient, exec *executor.Executor, messages *[]api.Message) (string, error) {
-	ctx := context.Background()
-	tools := api.GetDefaultTools()
-
 	// Keep calling the API until there are no more tool calls
+	var nudge []api.Message // --retry-empty follow-up for the next request only
+	retried := false
 	for {
-		sp := display.NewSpinner("Thinking...")
-		sp.Start()
+		if sp == nil {
+			sp = display.NewSpinner("Thinking...")
+			sp.Start()
+		} else {
+			sp.UpdateMessage("Thinking...")
+		}
 
-		resp, err := client.QueryWithHistoryAndToolsContext(ctx, *messages, tools)
+		done := app.timings.track("generate")
+		var resp *api.ChatResponse
+		var err error
+		printed := false
+		request := withPromptMacros(append((*messages)[:len(*messages):len(*messages)], nudge...))
+		nudge = nil
+		if app.cfg.Stream {
+			resp, printed, err = app.streamWithTools(ctx, client, request, tools, sp)
+		} else {
+			resp, err = client.QueryWithHistoryAndToolsContext(ctx, request, tools)
+		}
+		done()
 		sp.Stop()
+		sp = nil
 
+		var interrupted *api.StreamInterruptedError
+		if errors.As(err, &interrupted) && interrupted.Partial != "" && !interrupted.HadToolCalls {
+			// Keep the partial answer in history; the user can ask to continue
+			content := app.applyLengthBudget(interrupted.Partial)
+			if !printed {
+				display.ShowContent(content)
+			}
+			display.ShowError(err.Error())
+			return content, added, nil
+		}
+		if ctx.Err() != nil {
+			return "", added, errTurnAborted
+		}
 		if err != nil {
-			return "", err
+			return "", added, err
 		}
 
 		// Check if there are tool calls
 		if len(resp.Choices) > 0 && resp.Choices[0].HasToolCalls() {
 			toolCalls := resp.Choices[0].GetToolCalls()
+			app.showToolTurnContent(resp.Choices[0].Message.Content, printed)
+			if app.cfg.ShowToolCalls {
+				for _, tc := range toolCalls {
+					display.ShowToolCall(tc.Function.Name, tc.Function.Arguments)
+				}
+			}
 
 			// Add assistant message with tool calls to history
 			// Include content from response (may be empty, but structure matches API response)
@@ -373,81 +610,57 @@ func (app *App) sendInteractiveMessageWithTools(client *api.AzureClient, exec *e
 			if resp.Choices[0].Message.Content != "" {
 				assistantMsg.Content = resp.Choices[0].Message.Content
 			}
+			added = append(added, len(*messages))
 			*messages = append(*messages, assistantMsg)
 
-			// Process each tool call
-			for _, toolCall := range toolCalls {
-				if toolCall.Function.Name == "execute_command" {
-					// Parse arguments
-					var args struct {
-						Command   string `json:"command"`
-						Reasoning string `json:"reasoning"`
+			// Several file writes in one response are reviewed together first
+			app.reviewFileWrites(toolCalls, exec.GetPermissionManager().DryRun())
+
+			// Process each tool call; every call must get a tool message in reply.
+			// Before each call and after the last, a Ctrl+C pause lets the user steer.
+			for i := 0; i <= len(toolCalls); i++ {
+				choice, steering := interrupts.pause(ctx)
+				if choice != steerContinue {
+					for _, skipped := range skippedToolResults(toolCalls[i:]) {
+						added = append(added, len(*messages))
+						*messages = append(*messages, skipped)
 					}
-					if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args); err != nil {
-						display.ShowError(fmt.Sprintf("Failed to parse tool arguments: %v", err))
-						continue
+					if choice == steerAbort {
+						return "", added, errTurnAborted
 					}
-
-					// Check permission
-					allowed, needsConfirm, reason := exec.GetPermissionManager().CheckPermission(args.Command)
-
-					var result *executor.ExecutionResult
-					var toolResult string
-
-					if !allowed && !needsConfirm {
-						// Blocked
-						display.ShowCommandBlocked(args.Command, reason)
-						toolResult = fmt.Sprintf("Command blocked: %s", reason)
-					} else {
-						// Ask for confirmation if needed
-						if needsConfirm {
-							allow, always := display.AskCommandConfirmation(args.Command, args.Reasoning)
-							if !allow {
-								toolResult = "Command execution denied by user"
-								*messages = append(*messages, api.Message{
-									Role:       "tool",
-									Content:    toolResult,
-									ToolCallID: toolCall.ID,
-								})
-								continue
-							}
-							if always {
-								exec.GetPermissionManager().AddToAllowlist(args.Command)
-							}
-						}
-
-						// Execute the command
-						display.ShowCommandExecuting(args.Command)
-						result, err = exec.Execute(ctx, args.Command)
-
-						if err != nil || !result.IsSuccess() {
-							display.ShowCommandError(args.Command, result.Error)
-							toolResult = result.FormatResult()
-						} else {
-							display.ShowCommandOutput(result.Output)
-							toolResult = result.Output
-							if toolResult == "" {
-								toolResult = "Command executed successfully (no output)"
-							}
-						}
-					}
-
-					// Add tool result to messages
-					*messages = append(*messages, api.Message{
-						Role:       "tool",
-						Content:    toolResult,
-						ToolCallID: toolCall.ID,
-					})
+					added = append(added, len(*messages))
+					*messages = append(*messages, api.Message{Role: "user", Content: steering})
+					break
+				}
+				if i == len(toolCalls) {
+					break
 				}
+
+				toolCall := toolCalls[i]
+				toolResult := app.handleToolCall(ctx, exec, toolCall)
+				added = append(added, len(*messages))
+				*messages = append(*messages, api.Message{
+					Role:       "tool",
+					Content:    toolResult,
+					ToolCallID: toolCall.ID,
+				})
 			}
 
 			// Continue loop to get AI's response to the tool results
 			continue
 		}
 
-		// No tool calls, display the final response
-		content := resp.GetContent()
-		if content != "" {
+		// An empty (or too short) answer gets one more try with a nudge (--retry-empty)
+		if !retried && app.shouldRetryAnswer(resp) {
+			retried = true
+			display.ShowAnswerRetry()
+			nudge = retryNudge(resp.GetContent())
+			continue
+		}
+
+		// No tool calls, display the final response unless it was streamed already
+		content := app.applyLengthBudget(resp.GetContent())
+		if content != "" && !printed {
 			if app.cfg.Render {
 				display.ShowContentRendered(content)
 			} else {
@@ -455,6 +668,6 @@ func (app *App) sendInteractiveMessageWithTools(client *api.AzureClient, exec *e
 			}
 		}
 
-		return content, nil
+		return content, added, nil
 	}
 }
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+)
+
+func TestKeepRecentMessages(t *testing.T) {
+	history := []api.Message{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: "u1"},
+		{Role: "assistant", ToolCalls: []api.ToolCall{{ID: "call_1"}}},
+		{Role: "tool", Content: "out", ToolCallID: "call_1"},
+		{Role: "assistant", Content: "a1"},
+		{Role: "user", Content: "u2"},
+		{Role: "assistant", Content: "a2"},
+	}
+
+	tests := []struct {
+		name        string
+		n           int
+		wantLen     int
+		wantRemoved int
+		wantFirst   string
+	}{
+		{"keep all", 10, 7, 0, "u1"},
+		{"keep two", 2, 3, 4, "u2"},
+		{"drops orphaned tool result", 4, 4, 3, "a1"},
+		{"keep none", 0, 1, 6, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msgs := append([]api.Message(nil), history...)
+			got, removed := keepRecentMessages(msgs, tt.n)
+			if len(got) != tt.wantLen || removed != tt.wantRemoved {
+				t.Fatalf("keepRecentMessages(n=%d) len = %d removed = %d, want len %d removed %d", tt.n, len(got), removed, tt.wantLen, tt.wantRemoved)
+			}
+			if got[0].Role != "system" {
+				t.Errorf("first message role = %q, want system", got[0].Role)
+			}
+			if tt.wantFirst != "" && got[1].Content != tt.wantFirst {
+				t.Errorf("first kept message = %q, want %q", got[1].Content, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestLastUserMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []api.Message
+		want     int
+	}{
+		{"system only", []api.Message{{Role: "system"}}, -1},
+		{"after answer", []api.Message{{Role: "system"}, {Role: "user"}, {Role: "assistant"}}, 1},
+		{"after tool calls", []api.Message{
+			{Role: "system"}, {Role: "user"}, {Role: "assistant"},
+			{Role: "user"}, {Role: "assistant", ToolCalls: []api.ToolCall{{ID: "call_1"}}},
+			{Role: "tool", ToolCallID: "call_1"}, {Role: "assistant"},
+		}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lastUserMessage(tt.messages); got != tt.want {
+				t.Errorf("lastUserMessage() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatPromptPrefix(t *testing.T) {
+	tests := []struct {
+		name string
+		tmpl string
+		web  bool
+		want string
+	}{
+		{"default", "> ", false, "> "},
+		{"model", "{model}> ", false, "gpt-4o> "},
+		{"provider and web on", "{model} [{provider} web:{web}]> ", true, "gpt-4o [brave web:on]> "},
+		{"web off", "web:{web} > ", false, "web:off > "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatPromptPrefix(tt.tmpl, "gpt-4o", "brave", tt.web); got != tt.want {
+				t.Errorf("formatPromptPrefix(%q) = %q, want %q", tt.tmpl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplayStart(t *testing.T) {
+	webContext := fmt.Sprintf(WebContextMessageTemplate, "[1] result")
+	tests := []struct {
+		name     string
+		messages []api.Message
+		all      bool
+		want     int
+	}{
+		{"empty history", []api.Message{{Role: "system"}}, false, -1},
+		{"last turn", []api.Message{
+			{Role: "system"}, {Role: "user"}, {Role: "assistant"}, {Role: "user"}, {Role: "assistant"},
+		}, false, 3},
+		{"all turns keep summary", []api.Message{
+			{Role: "system"}, {Role: "system", Content: "Summary of earlier conversation:"}, {Role: "user"}, {Role: "assistant"}, {Role: "user"},
+		}, true, 2},
+		{"drops web context", []api.Message{
+			{Role: "system"}, {Role: "user"}, {Role: "assistant"}, {Role: "system", Content: webContext}, {Role: "user"}, {Role: "assistant"},
+		}, false, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := replayStart(tt.messages, tt.all); got != tt.want {
+				t.Errorf("replayStart(all=%v) = %d, want %d", tt.all, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLastAnswer(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []api.Message
+		want     string
+	}{
+		{"none", []api.Message{{Role: "system", Content: "sys"}, {Role: "user", Content: "q"}}, ""},
+		{"latest", []api.Message{{Role: "system"}, {Role: "assistant", Content: "a1"}, {Role: "user"}, {Role: "assistant", Content: "a2"}}, "a2"},
+		{"skips tool calls", []api.Message{
+			{Role: "system"}, {Role: "assistant", Content: "a1"}, {Role: "user"},
+			{Role: "assistant", ToolCalls: []api.ToolCall{{ID: "call_1"}}}, {Role: "tool", Content: "out"},
+		}, "a1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lastAnswer(tt.messages); got != tt.want {
+				t.Errorf("lastAnswer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatPrompt(t *testing.T) {
+	call := api.ToolCall{ID: "call_1"}
+	call.Function.Name = "execute_command"
+	call.Function.Arguments = `{"command":"ls"}`
+	messages := []api.Message{
+		{Role: "system", Content: "Be precise."},
+		{Role: "user", Content: "What is here?\n", Images: []string{"data:image/png;base64,AA=="}},
+		{Role: "assistant", ToolCalls: []api.ToolCall{call}},
+		{Role: "tool", ToolCallID: "call_1", Content: "main.go"},
+	}
+
+	want := `--- system ---
+Be precise.
+--- user ---
+What is here?
+[1 image(s) attached]
+--- assistant ---
+execute_command({"command":"ls"})
+--- tool (call_1) ---
+main.go
+`
+	if got := formatPrompt(messages); got != want {
+		t.Errorf("formatPrompt() =\n%s\nwant\n%s", got, want)
+	}
+}
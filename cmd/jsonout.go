@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/display"
+)
+
+// answerUsage is the token usage in --json output
+type answerUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+// answerCitation is one web search source in --json output; Index matches the [n] markers
+type answerCitation struct {
+	Index int    `json:"index"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// answerOutput is the --json document for one query
+type answerOutput struct {
+	Query       string           `json:"query"`
+	Model       string           `json:"model"`
+	Content     string           `json:"content"`
+	Usage       answerUsage      `json:"usage"`
+	SearchQuery string           `json:"search_query,omitempty"`
+	Citations   []answerCitation `json:"citations,omitempty"`
+	FirstToken  int64            `json:"first_token_ms,omitempty"` // Streaming only
+	Choices     []string         `json:"choices,omitempty"`        // All answers with --n; Content is the first
+}
+
+// queryJSON sends the query and returns the answer without printing it, so stdout
+// carries only the final JSON. Streaming responses are buffered until complete.
+func (app *App) queryJSON(client *api.AzureClient, systemPrompt, userMessage string) *answerOutput {
+	sp := display.NewSpinner("Waiting for response...")
+	sp.Start()
+
+	done := app.timings.track("generate")
+	var resp *api.ChatResponse
+	var err error
+	if app.cfg.Choices > 1 {
+		resp, err = client.QueryChoices(context.Background(), systemPrompt, userMessage, app.cfg.Choices)
+	} else if app.cfg.Stream {
+		messages := []api.Message{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userMessage},
+		}
+		start := time.Now()
+		resp, err = client.QueryStreamWithToolsContext(context.Background(), messages, nil, func(string) {
+			app.timings.firstTokenAfter(start)
+		})
+	} else {
+		resp, err = client.Query(systemPrompt, userMessage)
+	}
+	done()
+	if err == nil && app.shouldRetryAnswer(resp) {
+		display.ShowAnswerRetry()
+		resp = app.retryAnswer(client, []api.Message{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userMessage},
+		}, resp)
+	}
+	sp.Stop()
+
+	if err != nil {
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+
+	out := &answerOutput{
+		Model:   app.cfg.Model,
+		Content: app.applyLengthBudget(resp.GetContent()),
+		Usage: answerUsage{
+			InputTokens:  resp.Usage.PromptTokens,
+			OutputTokens: resp.Usage.CompletionTokens,
+			TotalTokens:  resp.Usage.TotalTokens,
+		},
+		FirstToken: app.timings.firstToken.Milliseconds(),
+	}
+	if app.cfg.Choices > 1 {
+		for _, content := range resp.GetContents() {
+			out.Choices = append(out.Choices, app.applyLengthBudget(content))
+		}
+	}
+	if app.cfg.WebSearch {
+		app.addSearchSources(out)
+	}
+	return out
+}
+
+// addSearchSources fills the search query and citations from the last web search
+func (app *App) addSearchSources(out *answerOutput) {
+	out.SearchQuery = app.searchQuery
+	if app.searchResults == nil {
+		return
+	}
+	for i, r := range app.searchResults.Results {
+		out.Citations = append(out.Citations, answerCitation{Index: i + 1, Title: r.Title, URL: r.URL})
+	}
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"os"
+	"os/user"
+	"regexp"
+	"runtime"
+	"time"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+)
+
+// macroPattern matches {{name}} macros in a system prompt
+var macroPattern = regexp.MustCompile(`\{\{\s*([a-z]+)\s*\}\}`)
+
+// promptMacros returns the values of the system prompt macros at time now
+func promptMacros(now time.Time) map[string]string {
+	macros := map[string]string{
+		"date": now.Format("2006-01-02 (Monday)"),
+		"os":   runtime.GOOS,
+		"cwd":  "",
+		"user": os.Getenv("USER"),
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		macros["cwd"] = cwd
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		macros["user"] = u.Username
+	}
+	return macros
+}
+
+// expandMacros replaces {{name}} macros in s with their values; unknown names are left as written
+func expandMacros(s string, macros map[string]string) string {
+	return macroPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if value, ok := macros[macroPattern.FindStringSubmatch(m)[1]]; ok {
+			return value
+		}
+		return m
+	})
+}
+
+// withPromptMacros returns messages with the macros in a leading system message expanded,
+// copying the slice so history keeps the macros for the next send
+func withPromptMacros(messages []api.Message) []api.Message {
+	if len(messages) == 0 || messages[0].Role != "system" || !macroPattern.MatchString(messages[0].Content) {
+		return messages
+	}
+	expanded := append([]api.Message(nil), messages...)
+	expanded[0].Content = expandMacros(expanded[0].Content, promptMacros(time.Now()))
+	return expanded
+}
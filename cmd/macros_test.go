@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+)
+
+func TestExpandMacros(t *testing.T) {
+	macros := map[string]string{"date": "2026-10-16 (Friday)", "os": "linux", "cwd": "/src", "user": "dev"}
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"no macros", "Be concise.", "Be concise."},
+		{"all macros", "Today is {{date}}. {{user}} works in {{cwd}} on {{os}}.", "Today is 2026-10-16 (Friday). dev works in /src on linux."},
+		{"spaces", "Date: {{ date }}", "Date: 2026-10-16 (Friday)"},
+		{"unknown kept", "Keep {{name}} and {{DATE}}", "Keep {{name}} and {{DATE}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandMacros(tt.in, macros); got != tt.want {
+				t.Errorf("expandMacros(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithPromptMacros(t *testing.T) {
+	history := []api.Message{
+		{Role: "system", Content: "Running on {{os}}."},
+		{Role: "user", Content: "What is {{os}}?"},
+	}
+	got := withPromptMacros(history)
+	if strings.Contains(got[0].Content, "{{os}}") {
+		t.Errorf("system message not expanded: %q", got[0].Content)
+	}
+	if got[1].Content != "What is {{os}}?" {
+		t.Errorf("user message changed: %q", got[1].Content)
+	}
+	if history[0].Content != "Running on {{os}}." {
+		t.Errorf("history modified: %q", history[0].Content)
+	}
+}
+
+func TestMacrosSkipSearchResults(t *testing.T) {
+	macros := map[string]string{"user": "dev", "date": "2026-10-16 (Friday)"}
+	results := "Handlebars greets {{user}} on {{date}}"
+
+	app := &App{cfg: &config.Config{SystemPrompt: "Today is {{date}}."}}
+	got := app.oneShotSystemPrompt(&results, macros)
+	if !strings.Contains(got, "Today is 2026-10-16 (Friday).") {
+		t.Errorf("custom prompt not expanded: %q", got)
+	}
+	if !strings.Contains(got, results) {
+		t.Errorf("search results were rewritten: %q", got)
+	}
+	if got := app.oneShotSystemPrompt(nil, macros); got != "Today is 2026-10-16 (Friday)." {
+		t.Errorf("prompt without search = %q", got)
+	}
+
+	// Interactive turns keep search results in a later system message, which is left alone
+	history := []api.Message{
+		{Role: "system", Content: "Hi {{user}}"},
+		{Role: "system", Content: results},
+		{Role: "user", Content: "q"},
+	}
+	if sent := withPromptMacros(history); sent[1].Content != results {
+		t.Errorf("web context message rewritten: %q", sent[1].Content)
+	}
+}
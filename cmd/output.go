@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/display"
+)
+
+// openOutputFile creates the --output file, refusing to replace an existing one unless force is set
+func openOutputFile(path string, force bool) (*os.File, error) {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !force {
+		flags |= os.O_EXCL
+	}
+	f, err := os.OpenFile(path, flags, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("%s already exists (use --force to overwrite)", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	return f, nil
+}
+
+// formatFileAnswer returns the answer as plain markdown for --output, followed by its
+// sources when given. URLs are written in full: the file is not a terminal.
+func formatFileAnswer(answer string, citations []display.Citation, searchQuery string) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(answer, "\n"))
+	b.WriteString("\n")
+	if len(citations) > 0 {
+		b.WriteString("\n## Sources\n\n")
+		if searchQuery != "" {
+			fmt.Fprintf(&b, "Searched for: %s\n\n", searchQuery)
+		}
+		for i, c := range citations {
+			fmt.Fprintf(&b, "[%d] %s - %s\n", i+1, c.Title, c.URL)
+		}
+	}
+	return b.String()
+}
+
+// writeOutput appends text to the --output file, if any
+func (app *App) writeOutput(text string) {
+	if app.output == nil {
+		return
+	}
+	if _, err := app.output.WriteString(text); err != nil {
+		display.ShowError(fmt.Sprintf("Failed to write %s: %v", app.output.Name(), err))
+		os.Exit(1)
+	}
+}
+
+// writeAnswer appends an answer, with its citations when --citations is on, to the --output file
+func (app *App) writeAnswer(answer string) {
+	if app.output == nil {
+		return
+	}
+	var citations []display.Citation
+	searchQuery := ""
+	if app.cfg.WebSearch && app.cfg.Citations {
+		citations = app.citations(answer)
+		if app.cfg.ShowSearchQuery {
+			searchQuery = app.searchQuery
+		}
+	}
+	app.writeOutput(formatFileAnswer(answer, citations, searchQuery))
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+	"github.com/quocvuong92/azure-ai-cli/internal/display"
+)
+
+func TestOpenOutputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answer.md")
+
+	f, err := openOutputFile(path, false)
+	if err != nil {
+		t.Fatalf("openOutputFile() new file error = %v", err)
+	}
+	_, _ = f.WriteString("first")
+	_ = f.Close()
+
+	if _, err := openOutputFile(path, false); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("openOutputFile() existing file error = %v, want --force hint", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "first" {
+		t.Errorf("refused open changed the file to %q", data)
+	}
+
+	f, err = openOutputFile(path, true)
+	if err != nil {
+		t.Fatalf("openOutputFile() with force error = %v", err)
+	}
+	_ = f.Close()
+	if data, _ := os.ReadFile(path); len(data) != 0 {
+		t.Errorf("forced open left %q, want the file truncated", data)
+	}
+}
+
+func TestFormatFileAnswer(t *testing.T) {
+	tests := []struct {
+		name        string
+		answer      string
+		citations   []display.Citation
+		searchQuery string
+		want        string
+	}{
+		{"plain", "Go is a language.\n\n", nil, "", "Go is a language.\n"},
+		{
+			"with sources",
+			"Go 1.24 is out [1].",
+			[]display.Citation{{Title: "Go 1.24 Release Notes", URL: "https://go.dev/doc/go1.24"}},
+			"go 1.24 release",
+			"Go 1.24 is out [1].\n\n## Sources\n\nSearched for: go 1.24 release\n\n[1] Go 1.24 Release Notes - https://go.dev/doc/go1.24\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatFileAnswer(tt.answer, tt.citations, tt.searchQuery); got != tt.want {
+				t.Errorf("formatFileAnswer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBareOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req api.ChatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Stream {
+			_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"Paris\"},\"finish_reason\":\"stop\"}]," +
+				"\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":1,\"total_tokens\":4}}\n\ndata: [DONE]\n\n"))
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"Paris"},"finish_reason":"stop"}],` +
+			`"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`))
+	}))
+	defer server.Close()
+	t.Cleanup(func() { display.SetBare(false) })
+
+	tests := []struct {
+		name      string
+		stream    bool
+		bare      bool
+		wantUsage bool
+	}{
+		{"normal", false, false, true},
+		{"normal bare", false, true, false},
+		{"stream", true, false, true},
+		{"stream bare", true, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{AzureEndpoint: server.URL, AzureAPIKey: "test-key", Model: "gpt-4o", Usage: true, Bare: tt.bare}
+			app := &App{cfg: cfg}
+			display.SetBare(tt.bare)
+			client := api.NewAzureClient(cfg)
+
+			got := captureStdout(t, func() {
+				if tt.stream {
+					app.runStream(client, "system", "capital of France?")
+				} else {
+					app.runNormal(client, "system", "capital of France?")
+				}
+			})
+			if !strings.HasPrefix(got, "Paris\n") {
+				t.Errorf("stdout = %q, want the answer first", got)
+			}
+			if hasUsage := strings.Contains(got, "## Tokens"); hasUsage != tt.wantUsage {
+				t.Errorf("stdout = %q, usage on stdout = %v, want %v", got, hasUsage, tt.wantUsage)
+			}
+			if tt.bare && got != "Paris\n" {
+				t.Errorf("bare stdout = %q, want only the answer", got)
+			}
+		})
+	}
+}
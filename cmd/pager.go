@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/display"
+)
+
+// pagerCommand returns the user's pager from $PAGER, defaulting to "less -R" so colors survive
+func pagerCommand() []string {
+	if fields := strings.Fields(os.Getenv("PAGER")); len(fields) > 0 {
+		return fields
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"more"}
+	}
+	return []string{"less", "-R"}
+}
+
+// showInPager pipes text through the pager and waits for the user to quit it
+func showInPager(text string) error {
+	pager := pagerCommand()
+	cmd := exec.Command(pager[0], pager[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// A plain PAGER=less would show color codes literally
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(os.Environ(), "LESS=-R")
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("pager %s: %w", pager[0], err)
+	}
+	return nil
+}
+
+// lastAnswer returns the content of the most recent assistant answer, or "" if there is none
+func lastAnswer(messages []api.Message) string {
+	for i := len(messages) - 1; i > 0; i-- {
+		if messages[i].Role == "assistant" && messages[i].Content != "" {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
+
+// pageLastAnswer re-renders the last answer and opens it in the pager
+func (s *InteractiveSession) pageLastAnswer() {
+	answer := lastAnswer(s.messages)
+	if answer == "" {
+		fmt.Println("No answer to show yet.")
+		return
+	}
+	if err := showInPager(display.RenderMarkdown(answer)); err != nil {
+		display.ShowError(err.Error())
+	}
+}
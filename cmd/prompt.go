@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+)
+
+// formatPrompt renders messages as the model sees them, one headed block per message,
+// for --show-prompt and /show-prompt. Tool calls are shown as name(arguments) and
+// attached images only by count.
+func formatPrompt(messages []api.Message) string {
+	var b strings.Builder
+	for _, msg := range messages {
+		header := msg.Role
+		if msg.ToolCallID != "" {
+			header += " (" + msg.ToolCallID + ")"
+		}
+		fmt.Fprintf(&b, "--- %s ---\n", header)
+		if msg.Content != "" {
+			b.WriteString(strings.TrimRight(msg.Content, "\n"))
+			b.WriteString("\n")
+		}
+		for _, call := range msg.ToolCalls {
+			fmt.Fprintf(&b, "%s(%s)\n", call.Function.Name, call.Function.Arguments)
+		}
+		if len(msg.Images) > 0 {
+			fmt.Fprintf(&b, "[%d image(s) attached]\n", len(msg.Images))
+		}
+	}
+	return b.String()
+}
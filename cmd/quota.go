@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+	"github.com/quocvuong92/azure-ai-cli/internal/display"
+	"github.com/quocvuong92/azure-ai-cli/internal/quota"
+)
+
+// quotaPeriodFromEnv reads the quota reset period from AZURE_AI_QUOTA_PERIOD and AZURE_AI_QUOTA_RESET_DAY
+func quotaPeriodFromEnv() (quota.Period, error) {
+	resetDay := 0
+	if v := os.Getenv(config.EnvQuotaResetDay); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return quota.Period{}, fmt.Errorf("invalid %s: %s", config.EnvQuotaResetDay, v)
+		}
+		resetDay = n
+	}
+	return quota.ParsePeriod(os.Getenv(config.EnvQuotaPeriod), resetDay)
+}
+
+// recordSearchQuota counts a successful search against the provider's current key.
+// Tracking is best-effort and never fails the search.
+func recordSearchQuota(cfg *config.Config, provider string) {
+	key := cfg.CurrentSearchKey(provider)
+	if key == "" {
+		return
+	}
+	period, err := quotaPeriodFromEnv()
+	if err != nil {
+		log.Printf("Quota tracking disabled: %v", err)
+		return
+	}
+	path, err := config.GetQuotaFile()
+	if err != nil {
+		log.Printf("Quota tracking disabled: %v", err)
+		return
+	}
+	if err := quota.Record(path, provider, key, period, time.Now()); err != nil {
+		log.Printf("Failed to record search quota: %v", err)
+	}
+}
+
+// newQuotaCmd creates the "quota" subcommand showing local search usage estimates
+func newQuotaCmd() *cobra.Command {
+	var reset bool
+
+	cmd := &cobra.Command{
+		Use:   "quota",
+		Short: "Show locally tracked search requests per provider key",
+		Long: `Show how many searches were made with each provider key in the current period.
+
+Counts are a local estimate (providers remain the source of truth) and are
+stored in the state directory. The period is monthly from day 1 by default;
+set AZURE_AI_QUOTA_PERIOD=daily, or AZURE_AI_QUOTA_RESET_DAY=N (1-28) to match
+a billing cycle.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runQuota(reset); err != nil {
+				display.ShowError(err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&reset, "reset", false, "Clear all local counts")
+
+	return cmd
+}
+
+func runQuota(reset bool) error {
+	period, err := quotaPeriodFromEnv()
+	if err != nil {
+		return err
+	}
+	path, err := config.GetQuotaFile()
+	if err != nil {
+		return err
+	}
+
+	if reset {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		fmt.Println("Local quota counts cleared.")
+		return nil
+	}
+
+	usage, err := quota.Load(path)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var rows []display.QuotaUsage
+	for _, provider := range config.SearchProviders {
+		keys := config.NewKeyRotator(config.SearchKeyEnvVars[provider]).GetKeys()
+		for i, key := range keys {
+			hint := key
+			if len(hint) > 4 {
+				hint = hint[len(hint)-4:]
+			}
+			rows = append(rows, display.QuotaUsage{
+				Provider: provider,
+				KeyIndex: i + 1,
+				KeyHint:  hint,
+				Count:    usage.Count(provider, key, period, now),
+			})
+		}
+	}
+
+	display.ShowQuota(period.Start(now).Format(time.DateOnly), rows)
+	return nil
+}
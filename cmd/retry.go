@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+)
+
+// shouldRetryAnswer reports whether --retry-empty asks again for this answer: it is
+// blank (or shorter than --min-answer-words), has no tool calls and wasn't cut off by
+// max_tokens or the content filter, which would only stop the retry the same way
+func (app *App) shouldRetryAnswer(resp *api.ChatResponse) bool {
+	if !app.cfg.RetryEmpty || resp == nil || len(resp.Choices) == 0 {
+		return false
+	}
+	choice := resp.Choices[0]
+	if choice.FinishReason == "length" || choice.FinishReason == "content_filter" || choice.HasToolCalls() {
+		return false
+	}
+	words := len(strings.Fields(resp.GetContent()))
+	return words == 0 || words < app.cfg.MinAnswerWords
+}
+
+// retryNudge returns the messages appended to a request to ask once more: the short
+// answer (left out when empty, which the API rejects) and RetryNudge
+func retryNudge(answer string) []api.Message {
+	var nudge []api.Message
+	if strings.TrimSpace(answer) != "" {
+		nudge = append(nudge, api.Message{Role: "assistant", Content: answer})
+	}
+	return append(nudge, api.Message{Role: "user", Content: RetryNudge})
+}
+
+// retryAnswer asks once more after an empty or short answer to messages (non-streaming). The
+// original response is kept if the retry fails.
+func (app *App) retryAnswer(client *api.AzureClient, messages []api.Message, resp *api.ChatResponse) *api.ChatResponse {
+	done := app.timings.track("generate")
+	retry, err := client.QueryWithHistoryContext(context.Background(), append(messages[:len(messages):len(messages)], retryNudge(resp.GetContent())...))
+	done()
+	if err != nil {
+		log.Printf("Retry after a short answer failed: %v, keeping the first answer", err)
+		return resp
+	}
+	return retry
+}
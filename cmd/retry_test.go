@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+)
+
+func TestShouldRetryAnswer(t *testing.T) {
+	response := func(content, finish string) *api.ChatResponse {
+		return &api.ChatResponse{Choices: []api.Choice{{
+			Message:      api.Message{Role: "assistant", Content: content},
+			FinishReason: finish,
+		}}}
+	}
+	withTools := response("", "tool_calls")
+	withTools.Choices[0].Message.ToolCalls = []api.ToolCall{{ID: "1"}}
+
+	tests := []struct {
+		name     string
+		enabled  bool
+		minWords int
+		resp     *api.ChatResponse
+		want     bool
+	}{
+		{"disabled", false, 0, response("", "stop"), false},
+		{"empty", true, 0, response("", "stop"), true},
+		{"whitespace", true, 0, response(" \n\t", "stop"), true},
+		{"one-word answer kept by default", true, 0, response("Paris.", "stop"), false},
+		{"one word under --min-answer-words", true, 2, response(" Sure. ", "stop"), true},
+		{"enough words", true, 2, response("Yes, it is.", "stop"), false},
+		{"full answer", true, 0, response("Go 1.24 was released in February 2025.", "stop"), false},
+		{"cut off by max_tokens", true, 2, response("The", "length"), false},
+		{"content filter", true, 0, response("", "content_filter"), false},
+		{"tool calls", true, 0, withTools, false},
+		{"no response", true, 0, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &App{cfg: &config.Config{RetryEmpty: tt.enabled, MinAnswerWords: tt.minWords}}
+			if got := app.shouldRetryAnswer(tt.resp); got != tt.want {
+				t.Errorf("shouldRetryAnswer() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAnswer(t *testing.T) {
+	var req api.ChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"A complete answer."}}]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{AzureEndpoint: server.URL, AzureAPIKey: "test-key", Model: "test", RetryEmpty: true}
+	app := &App{cfg: cfg}
+	messages := []api.Message{{Role: "system", Content: "sys"}, {Role: "user", Content: "question"}}
+	short := &api.ChatResponse{Choices: []api.Choice{{Message: api.Message{Role: "assistant", Content: "Hmm"}}}}
+
+	got := app.retryAnswer(api.NewAzureClient(cfg), messages, short)
+	if got.GetContent() != "A complete answer." {
+		t.Errorf("retryAnswer() = %q, want the retried answer", got.GetContent())
+	}
+	if len(req.Messages) != 4 || req.Messages[2].Content != "Hmm" || req.Messages[3].Content != RetryNudge {
+		t.Errorf("retry request messages = %+v, want the short answer and the nudge appended", req.Messages)
+	}
+	if len(messages) != 2 {
+		t.Errorf("caller's messages grew to %d", len(messages))
+	}
+}
+
+func TestRunStreamRetriesBlankAnswer(t *testing.T) {
+	var requests []api.ChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req api.ChatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		if req.Stream {
+			// A blank answer without a usage chunk, as Azure streams by default
+			_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\" \"},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n"))
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"A complete answer."},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		retryEmpty   bool
+		wantRequests int
+		wantAnswer   string
+	}{
+		{"retried", true, 2, "A complete answer."},
+		{"not retried without --retry-empty", false, 1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = nil
+			cfg := &config.Config{AzureEndpoint: server.URL, AzureAPIKey: "test-key", Model: "test", Stream: true, RetryEmpty: tt.retryEmpty}
+			app := &App{cfg: cfg}
+
+			var answer string
+			stderr := captureStderr(t, func() {
+				captureStdout(t, func() {
+					answer = app.runStream(api.NewAzureClient(cfg), "sys", "question")
+				})
+			})
+
+			if len(requests) != tt.wantRequests {
+				t.Fatalf("requests = %d, want %d", len(requests), tt.wantRequests)
+			}
+			if strings.TrimSpace(answer) != tt.wantAnswer {
+				t.Errorf("runStream() = %q, want %q", answer, tt.wantAnswer)
+			}
+			if retried := strings.Contains(stderr, "asking once more"); retried != tt.retryEmpty {
+				t.Errorf("stderr = %q, retry notice shown = %v, want %v", stderr, retried, tt.retryEmpty)
+			}
+			if tt.retryEmpty {
+				if retry := requests[1]; retry.Stream || retry.Messages[len(retry.Messages)-1].Content != RetryNudge {
+					t.Errorf("retry request = %+v, want a non-streaming request ending with the nudge", retry)
+				}
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -16,9 +17,23 @@ import (
 // App holds the application state
 type App struct {
 	cfg           *config.Config
+	configPath    string // --config override of the probed config file
 	verbose       bool
 	listModels    bool
+	listTools     bool
 	searchResults *api.TavilyResponse // Store search results for citations
+	searchQuery   string              // Query actually sent to the search provider
+	timings       phaseTimings        // Per-phase timings for --timing
+	turnSearches  int                 // web_search tool calls made in the current turn
+	webTurn       bool                // The current turn answers a web search; tool searches add to its sources
+	planApproved  bool                // --plan: a plan was approved, later turns run directly
+	output        *os.File            // --output file answers are also written to
+	jsonMode      bool                // --json-mode / --json-schema: answers are structured JSON
+
+	writeApprovals map[string]bool // write_file decisions from a batch review, by tool call ID
+
+	searchClients map[string]api.SearchClient // Per-provider clients reused across a session
+	azureClient   *api.AzureClient            // Shared Azure client, see getAzureClient
 }
 
 // NewApp creates a new App instance with default configuration
@@ -33,10 +48,10 @@ func Execute() {
 	app := NewApp()
 
 	rootCmd := &cobra.Command{
-		Use:   "azure-ai [query]",
+		Use:   "azure-ai [query...]",
 		Short: "A CLI client for Azure OpenAI with web search",
 		Long: `Azure AI CLI is a command-line client for Azure OpenAI API,
-with optional web search powered by Tavily, Linkup, or Brave.
+with optional web search powered by Tavily, Linkup, Brave, Perplexity, or SearXNG.
 
 Supports multiple API keys with automatic rotation for free tier usage.
 
@@ -45,9 +60,10 @@ Examples:
   azure-ai -m gpt-4o "Explain Docker"
   azure-ai --web "Latest news on Go 1.24"
   azure-ai --web --provider brave "Latest AI news"
+  azure-ai "What is Go?" "What is Rust?"  # Several queries, answered in turn
   azure-ai -i                             # Interactive mode
   azure-ai -ir                            # Interactive with markdown rendering`,
-		Args: cobra.MaximumNArgs(1),
+		Args: cobra.ArbitraryArgs,
 		Run: func(cmd *cobra.Command, args []string) {
 			app.run(cmd, args)
 		},
@@ -57,13 +73,92 @@ Examples:
 	rootCmd.Flags().BoolVarP(&app.cfg.Usage, "usage", "u", false, "Show token usage statistics")
 	rootCmd.Flags().BoolVarP(&app.cfg.Stream, "stream", "s", false, "Stream output in real-time")
 	rootCmd.Flags().BoolVarP(&app.cfg.Render, "render", "r", false, "Render markdown with colors and formatting")
-	rootCmd.Flags().BoolVarP(&app.cfg.WebSearch, "web", "w", false, "Search web first (requires TAVILY_API_KEYS, LINKUP_API_KEYS, or BRAVE_API_KEYS)")
+	rootCmd.Flags().BoolVarP(&app.cfg.WebSearch, "web", "w", false, "Search web first (requires TAVILY_API_KEYS, LINKUP_API_KEYS, BRAVE_API_KEYS, PERPLEXITY_API_KEYS, or SEARXNG_URL)")
+	rootCmd.Flags().BoolVar(&app.cfg.Plan, "plan", false, "In interactive mode, show the model's plan for approval before it runs any commands")
+	rootCmd.Flags().BoolVar(&app.cfg.SmartWeb, "smart-web", false, "In interactive web mode, only search on follow-ups when the model says new information is needed")
+	rootCmd.Flags().BoolVar(&app.cfg.SearchIntent, "search-intent", false, "In interactive web mode, let one model call decide whether to search and write the query (replaces --optimize and --smart-web)")
+	rootCmd.Flags().StringVar(&app.cfg.Optimize, "optimize", config.OptimizeFollowups, "Which interactive web searches the model rewrites first: first, followups, always, or never")
+	rootCmd.Flags().BoolVar(&app.cfg.OnlySources, "only-sources", false, "Print web search results (title, URL, snippet, score) without asking the model")
+	rootCmd.Flags().BoolVar(&app.cfg.FailEmpty, "fail-empty", false, "With --only-sources, exit non-zero when there are no results")
+	rootCmd.Flags().StringVarP(&app.cfg.OutputFile, "output", "o", "", "Also write the answer (unrendered, with --citations sources) to this file")
+	rootCmd.Flags().BoolVar(&app.cfg.Force, "force", false, "Overwrite an existing --output file")
+	rootCmd.Flags().BoolVar(&app.cfg.JSONMode, "json-mode", false, "Ask the model to answer with a JSON object (response_format json_object)")
+	rootCmd.Flags().StringVar(&app.cfg.JSONSchemaFile, "json-schema", "", "JSON schema file the answer must match (response_format json_schema)")
+	rootCmd.Flags().BoolVar(&app.cfg.JSON, "json", false, "Print the answer (model, content, usage, citations) or --only-sources results as JSON on stdout")
 	rootCmd.Flags().BoolVarP(&app.cfg.Citations, "citations", "c", false, "Show citations/sources from web search")
+	rootCmd.Flags().BoolVar(&app.cfg.AlwaysCite, "always-cite", false, "Show all sources even if the answer has no [n] citation markers")
+	rootCmd.Flags().BoolVar(&app.cfg.ShowSearchQuery, "show-query", false, "Show the (possibly optimized) search query with citations")
+	rootCmd.Flags().BoolVar(&app.cfg.PickSources, "pick-sources", false, "On a terminal, list search results and choose by number which ones the model sees")
+	rootCmd.Flags().BoolVar(&app.cfg.ShortURLs, "short-urls", false, "Shorten long citation URLs to fit the terminal (full URL stays clickable as a hyperlink)")
+	rootCmd.Flags().BoolVar(&app.cfg.ShowToolCalls, "show-tool-calls", false, "Show tool calls and their arguments as the AI forms them")
 	rootCmd.Flags().BoolVarP(&app.cfg.Interactive, "interactive", "i", false, "Interactive chat mode")
+	rootCmd.Flags().StringVar(&app.cfg.PromptPrefix, "prompt-prefix", "", "Interactive prompt, with {model}, {provider} and {web} placeholders (default \"> \")")
+	rootCmd.Flags().StringVar(&app.cfg.SystemPrompt, "system", "", "System prompt (env: AZURE_OPENAI_SYSTEM_PROMPT, default \"Be precise and concise.\")")
+	rootCmd.Flags().StringVar(&app.cfg.SystemPromptFile, "system-file", "", "Read the system prompt from a file")
 	rootCmd.Flags().StringVarP(&app.cfg.Model, "model", "m", "", "Model/deployment name (defaults to first in AZURE_OPENAI_MODELS)")
-	rootCmd.Flags().StringVarP(&app.cfg.WebSearchProvider, "provider", "p", "", "Web search provider: tavily, linkup, or brave (default: auto-detect)")
+	rootCmd.Flags().StringVar(&app.cfg.KeyVaultURL, "key-vault", "", "Azure Key Vault URL to read the endpoint and key secrets from (env: AZURE_KEYVAULT_URL)")
+	rootCmd.Flags().StringVar(&app.cfg.APIVersion, "api-version", "", "Use the classic deployment URL with this API version, e.g. 2024-10-21 (env: AZURE_OPENAI_API_VERSION)")
+	rootCmd.Flags().StringVar(&app.cfg.AuthMode, "auth-mode", "", "How to send the API key: bearer or api-key (default: api-key with --api-version, else bearer; env: AZURE_OPENAI_AUTH_MODE)")
+	rootCmd.Flags().StringVar(&app.cfg.AzureStreamEndpoint, "stream-endpoint", "", "Endpoint override used only for streaming requests")
+	rootCmd.Flags().StringSliceVar(&app.cfg.FallbackModels, "fallback-models", nil, "Comma-separated models to try when the primary is unavailable (404/429)")
+	rootCmd.Flags().BoolVar(&app.cfg.AllowUnlistedModel, "allow-unlisted-model", false, "Warn instead of failing when --model is not in AZURE_OPENAI_MODELS")
+	rootCmd.Flags().StringVarP(&app.cfg.WebSearchProvider, "provider", "p", "", "Web search provider: tavily, linkup, brave, perplexity, searxng, or all (default: auto-detect)")
+	rootCmd.Flags().StringSliceVar(&app.cfg.ProviderPriority, "provider-priority", nil, "Comma-separated provider auto-detect order, e.g. brave,tavily (env: WEB_SEARCH_PRIORITY)")
+	rootCmd.Flags().BoolVar(&app.cfg.Bare, "bare", false, "Print only the answer on stdout (no spinner, notices, or rendering)")
+	rootCmd.Flags().BoolVar(&app.cfg.NoColor, "no-color", false, "Plain output: no colors, markdown rendering, or spinner (also NO_COLOR for colors)")
+	rootCmd.Flags().BoolVar(&app.cfg.QuietNotices, "quiet-notices", false, "Hide status notices on stderr (searching, key rotation, fallbacks); errors are still shown")
+	rootCmd.Flags().BoolVar(&app.cfg.DebugStream, "debug-stream", false, "Copy raw streaming (SSE) lines to stderr as they arrive, for debugging")
+	rootCmd.Flags().BoolVar(&app.cfg.ShowPrompt, "show-prompt", false, "Print the final system prompt and user message to stderr before sending")
+	rootCmd.Flags().BoolVar(&app.cfg.Timing, "timing", false, "Show elapsed time per phase (optimize, search, generate) on stderr")
+	rootCmd.Flags().Float64Var(&app.cfg.Temperature, "temperature", 0, "Sampling temperature 0-2; lower is more deterministic (default: model default)")
+	rootCmd.Flags().IntVar(&app.cfg.MaxTokens, "max-tokens", 0, "Maximum tokens in the answer (default: model default)")
+	rootCmd.Flags().BoolVar(&app.cfg.CountOnly, "count-only", false, "Print only the prompt's token count, without generating an answer")
+	rootCmd.Flags().BoolVar(&app.cfg.RetryEmpty, "retry-empty", false, "Ask the model once more when an answer is empty")
+	rootCmd.Flags().IntVar(&app.cfg.MinAnswerWords, "min-answer-words", 0, "With --retry-empty, also ask again when an answer has fewer than N words")
+	rootCmd.Flags().IntVar(&app.cfg.Choices, "n", 1, "Number of alternative answers to generate and show for a query (not streamed)")
+	rootCmd.Flags().StringVar(&app.cfg.User, "user", "", "End-user ID sent with requests for Azure abuse monitoring (env: AZURE_OPENAI_USER)")
+	rootCmd.Flags().StringVar(&app.cfg.ExtraBody, "extra-body", "", "JSON object merged into every chat request, e.g. '{\"user\":\"me\",\"parallel_tool_calls\":false}'")
+	rootCmd.Flags().StringVar(&app.cfg.AnswerLang, "answer-lang", "", "Always answer in this language, e.g. de, ja, pt-br (default: the question's language)")
+	rootCmd.Flags().IntVar(&app.cfg.MaxWords, "max-words", 0, "Limit the answer to N words (prompt hint plus hard trim)")
+	rootCmd.Flags().IntVar(&app.cfg.MaxChars, "max-chars", 0, "Limit the answer to N characters (prompt hint plus hard trim)")
+	rootCmd.Flags().IntVar(&app.cfg.MaxResponseBytes, "max-response-bytes", config.DefaultMaxResponseBytes, "Stop a streamed response larger than N bytes, keeping what arrived (0 = unlimited)")
+	rootCmd.Flags().IntVar(&app.cfg.MaxHistoryTokens, "max-history-tokens", 0, "Summarize the oldest interactive turns when history exceeds N estimated tokens")
+	rootCmd.Flags().IntVar(&app.cfg.MaxContextTokens, "max-context", 0, "Warn when a request's estimated prompt exceeds N tokens (0 = off)")
+	rootCmd.Flags().BoolVar(&app.cfg.TrimContext, "trim-context", false, "With --max-context, leave the oldest messages out of oversized requests and trim interactive history")
+	rootCmd.Flags().StringSliceVar(&app.cfg.IncludeDomains, "include-domain", nil, "Only use web results from this domain (repeatable)")
+	rootCmd.Flags().StringSliceVar(&app.cfg.ExcludeDomains, "exclude-domain", nil, "Skip web results from this domain (repeatable)")
+	rootCmd.Flags().IntVar(&app.cfg.SearchMaxResults, "results", config.DefaultSearchResults, fmt.Sprintf("Results per web search (max %d)", config.MaxSearchResults))
+	rootCmd.Flags().IntVar(&app.cfg.MaxSearches, "max-searches", config.DefaultMaxSearches, "Maximum web_search tool calls the AI may make per interactive turn")
 	rootCmd.Flags().BoolVar(&app.listModels, "list-models", false, "List available models")
+	rootCmd.Flags().BoolVar(&app.listTools, "list-tools", false, "List the tools the AI can call and whether each is enabled")
+	rootCmd.Flags().StringVar(&app.configPath, "config", "", "Config file (default $XDG_CONFIG_HOME/azure-ai/config.yaml or ~/.config/azure-ai/config.yaml)")
+	rootCmd.Flags().BoolVar(&app.cfg.SafeMode, "safe-mode", safeModeBuild, "Chat and web search only: the AI cannot run commands (always on in safemode builds)")
+	rootCmd.Flags().BoolVar(&app.cfg.DryRun, "dry-run", false, "Show the commands and file writes the AI asks for without running them")
+	rootCmd.Flags().StringVar(&app.cfg.ToolsFile, "tools-file", "", "JSON file of extra tools (name, description, parameters, command template)")
+	rootCmd.Flags().DurationVar(&app.cfg.ExecTimeout, "exec-timeout", config.DefaultExecTimeout, "Kill commands run for the AI after this long, e.g. 5m")
+	rootCmd.Flags().IntVar(&app.cfg.SummarizeToolOutput, "summarize-tool-output", 0, "Summarize command output larger than N bytes before sending it to the model (0 = off)")
+	rootCmd.Flags().StringVar(&app.cfg.SummaryModel, "summary-model", "", "Model that summarizes command output, e.g. a cheaper deployment (default: --model)")
+	rootCmd.Flags().BoolVar(&app.cfg.NoCommandCache, "no-command-cache", false, "Re-run repeated read-only commands within a turn instead of reusing their output")
+	rootCmd.Flags().StringVar(&app.cfg.AllowlistFile, "allowlist-file", "", "File of always-allowed commands, one per line (trailing * for prefix)")
+	rootCmd.Flags().StringVar(&app.cfg.DenylistFile, "denylist-file", "", "File of always-blocked commands, one per line; /deny adds to it")
 
+	rootCmd.AddCommand(newQuotaCmd())
+	rootCmd.AddCommand(newBenchCmd())
+	rootCmd.AddCommand(newConfigCmd())
+
+	// default_flags are read from the file --config names, so it is loaded before parsing
+	if path, ok := configFlagPath(os.Args[1:]); ok {
+		if err := app.cfg.LoadFile(path); err != nil {
+			display.ShowError(err.Error())
+			os.Exit(1)
+		}
+	}
+	defaults, err := app.cfg.DefaultFlags()
+	if err != nil {
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+	applyDefaultFlags(rootCmd, defaults, os.Args[1:])
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
@@ -77,6 +172,19 @@ func (app *App) run(cmd *cobra.Command, args []string) {
 		log.SetOutput(io.Discard)
 	}
 
+	// Config file: --config replaces the probed default; flags given on the command line win
+	if cmd.Flags().Changed("config") {
+		if err := app.cfg.LoadFile(app.configPath); err != nil {
+			display.ShowError(err.Error())
+			os.Exit(1)
+		}
+	}
+	if err := app.cfg.ApplyFileFlags(cmd.Flags().Changed); err != nil {
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+	log.Printf("Config file: %s", app.cfg.ConfigFile)
+
 	// Handle --list-models flag
 	if app.listModels {
 		_ = app.cfg.Validate()
@@ -89,12 +197,38 @@ func (app *App) run(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	// --only-sources is a pure search frontend
+	if app.cfg.OnlySources {
+		app.cfg.WebSearch = true
+	}
+
 	// Validate config
 	if err := app.cfg.Validate(); err != nil {
 		display.ShowError(err.Error())
 		os.Exit(1)
 	}
 
+	display.SetQuietNotices(app.cfg.QuietNotices)
+	display.SetShortURLs(app.cfg.ShortURLs)
+
+	for _, m := range append([]string{app.cfg.Model}, app.cfg.FallbackModels...) {
+		if app.cfg.IsUnlistedModel(m) {
+			display.ShowUnlistedModel(m, app.cfg.GetAvailableModelsString())
+		}
+	}
+
+	// --no-color prints plain content with no escape codes or spinner
+	if app.cfg.NoColor {
+		app.cfg.Render = false
+		display.SetNoColor(true)
+	}
+
+	// Bare mode keeps stdout to exactly the answer
+	if app.cfg.Bare {
+		app.cfg.Render = false
+		display.SetBare(true)
+	}
+
 	// Initialize markdown renderer if render flag is set
 	if app.cfg.Render {
 		if err := display.InitRenderer(); err != nil {
@@ -102,56 +236,265 @@ func (app *App) run(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	// A safemode build cannot leave safe mode
+	if safeModeBuild {
+		app.cfg.SafeMode = true
+	}
+	if app.cfg.SafeMode && app.cfg.ToolsFile != "" {
+		display.ShowError("--tools-file is not available in safe mode")
+		os.Exit(1)
+	}
+	if app.cfg.CountOnly && app.cfg.Interactive {
+		display.ShowError("--count-only works on one-shot queries, not interactive mode")
+		os.Exit(1)
+	}
+
+	// Handle --list-tools flag
+	if app.listTools {
+		app.registerTools()
+		display.ShowTools(app.toolListings())
+		return
+	}
+
 	// Interactive mode
 	if app.cfg.Interactive {
+		if app.cfg.OutputFile != "" {
+			display.ShowError("--output is not supported in interactive mode; use /save instead")
+			os.Exit(1)
+		}
+		if app.cfg.JSONMode || app.cfg.JSONSchemaFile != "" {
+			display.ShowError("--json-mode and --json-schema are not supported in interactive mode")
+			os.Exit(1)
+		}
 		app.runInteractive()
 		return
 	}
 
+	args, err := expandAlias(app.cfg, args)
+	if err != nil {
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+
+	// Piped input (e.g. "cat file.md | azure-ai summarize this") joins the query
+	input, err := readPipedInput(os.Stdin)
+	if err != nil {
+		display.ShowError(fmt.Sprintf("Failed to read stdin: %v", err))
+		os.Exit(1)
+	}
+	args = mergePipedInput(args, input)
+
 	// Require query if not interactive mode
 	if len(args) == 0 {
 		_ = cmd.Help()
 		os.Exit(1)
 	}
 
-	query := args[0]
+	if app.cfg.OnlySources {
+		app.runOnlySources(args)
+		return
+	}
+
+	format, err := loadResponseFormat(app.cfg.JSONMode, app.cfg.JSONSchemaFile)
+	if err != nil {
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+	if format != nil {
+		app.getAzureClient().SetResponseFormat(format)
+		app.jsonMode = true
+	}
+
+	if app.cfg.OutputFile != "" {
+		if app.cfg.JSON {
+			display.ShowError("--output cannot be combined with --json; redirect stdout instead")
+			os.Exit(1)
+		}
+		f, err := openOutputFile(app.cfg.OutputFile, app.cfg.Force)
+		if err != nil {
+			display.ShowError(err.Error())
+			os.Exit(1)
+		}
+		defer func() { _ = f.Close() }()
+		app.output = f
+	}
+
+	app.answerQueries(args)
+}
+
+// answerQueries answers each positional query in turn. Several queries get a
+// numbered header each; with --json their answers are printed as one array.
+func (app *App) answerQueries(queries []string) {
+	var outputs []*answerOutput
+	for i, query := range queries {
+		if len(queries) > 1 && !app.cfg.JSON {
+			display.ShowQueryHeader(i+1, len(queries), query)
+			if i > 0 {
+				app.writeOutput("\n")
+			}
+			app.writeOutput(fmt.Sprintf("## [%d/%d] %s\n\n", i+1, len(queries), query))
+		}
+		if out := app.answerQuery(query); out != nil {
+			outputs = append(outputs, out)
+		}
+	}
+
+	if app.cfg.JSON {
+		var v interface{} = outputs
+		if len(outputs) == 1 {
+			v = outputs[0]
+		}
+		if err := display.ShowJSON(v); err != nil {
+			display.ShowError(err.Error())
+			os.Exit(1)
+		}
+	}
+}
+
+// answerQuery runs one non-interactive query: optional web search, the model
+// call, citations and timings. Per-query state is reset so several positional
+// queries don't leak sources or timings into each other. With --json nothing
+// is printed on stdout and the answer is returned instead; otherwise it returns nil.
+func (app *App) answerQuery(query string) *answerOutput {
+	app.searchResults = nil
+	app.searchQuery = ""
+	app.timings.reset()
+
 	log.Printf("Query: %s", query)
 	log.Printf("Model: %s", app.cfg.Model)
 	log.Printf("Stream: %v", app.cfg.Stream)
 	log.Printf("WebSearch: %v", app.cfg.WebSearch)
 
-	// Build system prompt and user message
-	systemPrompt := config.DefaultSystemMessage
 	userMessage := query
 
-	// Web search if requested
-	if app.cfg.WebSearch {
+	var webContext *string // Search results for the prompt, when searched
+	if app.oneShotSearch() {
 		searchContext, err := app.performWebSearch(query)
 		if err != nil {
 			display.ShowError(err.Error())
 			os.Exit(1)
 		}
-		systemPrompt = buildWebSearchPrompt(searchContext)
+		// Answer providers already wrote a grounded answer; skip the Azure call
+		if config.IsAnswerProvider(app.cfg.WebSearchProvider) && app.searchResults.Answer != "" {
+			out := app.showSearchAnswer(query)
+			app.showTimings()
+			return out
+		}
+		webContext = &searchContext
+	}
+	systemPrompt := app.oneShotSystemPrompt(webContext, promptMacros(time.Now()))
+	if app.jsonMode {
+		systemPrompt = withJSONHint(systemPrompt)
+	}
+	if app.cfg.ShowPrompt {
+		fmt.Fprint(os.Stderr, formatPrompt([]api.Message{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userMessage},
+		}))
 	}
 
 	// Create Azure client
-	azureClient := api.NewAzureClient(app.cfg)
+	azureClient := app.getAzureClient()
 
 	log.Printf("Sending request to Azure OpenAI...")
 
-	if app.cfg.Stream {
-		app.runStream(azureClient, systemPrompt, userMessage)
+	if app.cfg.CountOnly {
+		return app.countOnly(azureClient, query, systemPrompt, userMessage)
+	}
+
+	if app.cfg.JSON {
+		out := app.queryJSON(azureClient, systemPrompt, userMessage)
+		out.Query = query
+		app.showTimings()
+		return out
+	}
+
+	var answer string
+	if app.cfg.Choices > 1 {
+		answer = app.runChoices(azureClient, systemPrompt, userMessage)
+	} else if app.cfg.Stream {
+		answer = app.runStream(azureClient, systemPrompt, userMessage)
 	} else {
-		app.runNormal(azureClient, systemPrompt, userMessage)
+		answer = app.runNormal(azureClient, systemPrompt, userMessage)
 	}
 
 	// Show citations if web search was used and citations flag is set
-	if app.cfg.WebSearch && app.cfg.Citations && app.searchResults != nil && len(app.searchResults.Results) > 0 {
-		fmt.Println()
-		citations := make([]display.Citation, len(app.searchResults.Results))
-		for i, r := range app.searchResults.Results {
-			citations[i] = display.Citation{Title: r.Title, URL: r.URL}
+	if app.cfg.WebSearch && app.cfg.Citations {
+		app.showCitations(answer)
+	}
+	app.writeAnswer(answer)
+
+	app.showTimings()
+	return nil
+}
+
+// getAzureClient returns the session's Azure client with display callbacks attached,
+// creating it on first use so connections and capability probes are reused
+func (app *App) getAzureClient() *api.AzureClient {
+	if app.azureClient != nil {
+		return app.azureClient
+	}
+	client := api.NewAzureClient(app.cfg)
+	client.SetModelFallbackCallback(display.ShowModelFallback)
+	client.SetContextOverflowCallback(func(estimated, limit, dropped int) {
+		display.ShowContextOverflow(estimated, limit, dropped, app.cfg.TrimContext)
+	})
+	client.SetKeyRotationCallback(func(from, to, total int) {
+		display.ShowKeyRotation("Azure", from, to, total)
+	})
+	if app.cfg.DebugStream {
+		client.SetStreamDebugWriter(os.Stderr)
+	}
+	app.azureClient = client
+	return client
+}
+
+// oneShotSearch reports whether a one-shot query searches the web. -w always searches the
+// query as typed: --search-intent only applies to interactive follow-ups, as a separate
+// call would just add a round-trip here. --count-only skips answer providers, whose
+// search is itself an answer.
+func (app *App) oneShotSearch() bool {
+	if !app.cfg.WebSearch {
+		return false
+	}
+	if app.cfg.CountOnly && config.IsAnswerProvider(app.cfg.WebSearchProvider) {
+		display.ShowCountWithoutSearch(app.cfg.WebSearchProvider)
+		return false
+	}
+	return true
+}
+
+// oneShotSystemPrompt builds the system prompt for a one-shot query, with the search
+// instructions and results when searchContext is set. Macros are expanded in the user's
+// prompt only, before the results are added, so result text is never rewritten.
+func (app *App) oneShotSystemPrompt(searchContext *string, macros map[string]string) string {
+	if searchContext == nil {
+		return app.buildSystemPrompt(expandMacros(app.cfg.GetSystemPrompt(), macros))
+	}
+	systemPrompt := buildWebSearchPrompt(*searchContext)
+	// A custom system prompt still applies, ahead of the search instructions
+	if app.cfg.SystemPrompt != "" {
+		systemPrompt = expandMacros(app.cfg.SystemPrompt, macros) + "\n\n" + systemPrompt
+	}
+	return app.buildSystemPrompt(systemPrompt)
+}
+
+// buildSystemPrompt appends configured instructions (the length budget and answer
+// language) to a base system prompt
+func (app *App) buildSystemPrompt(base string) string {
+	for _, instruction := range []string{app.lengthInstruction(), app.languageInstruction()} {
+		if instruction != "" {
+			base += "\n\n" + instruction
 		}
-		display.ShowCitations(citations)
 	}
+	return base
+}
+
+// languageInstruction asks for answers in the --answer-lang language, or returns ""
+func (app *App) languageInstruction() string {
+	name, ok := config.AnswerLanguages[app.cfg.AnswerLang]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(AnswerLanguageInstruction, name)
 }
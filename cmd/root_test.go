@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+)
+
+func TestAnswerQueries(t *testing.T) {
+	// The model answers by echoing the question
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req api.ChatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{
+				"message":       map[string]string{"role": "assistant", "content": "re: " + req.Messages[len(req.Messages)-1].Content},
+				"finish_reason": "stop",
+			}},
+		})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		queries []string
+		want    string
+	}{
+		{"one query has no header", []string{"go"}, "re: go\n"},
+		{"several queries in turn", []string{"go", "rust"},
+			"## [1/2] go\n\nre: go\n\n## [2/2] rust\n\nre: rust\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{AzureEndpoint: server.URL, AzureAPIKey: "test-key", Model: "gpt-4o"}
+			app := &App{cfg: cfg}
+			if got := captureStdout(t, func() { app.answerQueries(tt.queries) }); got != tt.want {
+				t.Errorf("stdout = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("json array", func(t *testing.T) {
+		cfg := &config.Config{AzureEndpoint: server.URL, AzureAPIKey: "test-key", Model: "gpt-4o", JSON: true}
+		app := &App{cfg: cfg}
+		out := captureStdout(t, func() { app.answerQueries([]string{"go", "rust"}) })
+
+		var got []answerOutput
+		if err := json.Unmarshal([]byte(out), &got); err != nil {
+			t.Fatalf("stdout %q is not a JSON array: %v", out, err)
+		}
+		var pairs [][2]string
+		for _, o := range got {
+			pairs = append(pairs, [2]string{o.Query, o.Content})
+		}
+		if want := [][2]string{{"go", "re: go"}, {"rust", "re: rust"}}; !reflect.DeepEqual(pairs, want) {
+			t.Errorf("answers = %q, want %q", pairs, want)
+		}
+	})
+}
@@ -1,19 +1,32 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/quocvuong92/azure-ai-cli/internal/api"
 	"github.com/quocvuong92/azure-ai-cli/internal/display"
 )
 
-func (app *App) runNormal(client *api.AzureClient, systemPrompt, userMessage string) {
+// runNormal sends a non-streaming query, displays the answer and returns it
+func (app *App) runNormal(client *api.AzureClient, systemPrompt, userMessage string) string {
 	sp := display.NewSpinner("Waiting for response...")
 	sp.Start()
 
+	done := app.timings.track("generate")
 	resp, err := client.Query(systemPrompt, userMessage)
+	done()
+	if err == nil && app.shouldRetryAnswer(resp) {
+		display.ShowAnswerRetry()
+		resp = app.retryAnswer(client, []api.Message{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userMessage},
+		}, resp)
+	}
 	sp.Stop()
 
 	if err != nil {
@@ -21,63 +34,191 @@ func (app *App) runNormal(client *api.AzureClient, systemPrompt, userMessage str
 		os.Exit(1)
 	}
 
+	content := app.applyLengthBudget(resp.GetContent())
 	if app.cfg.Render {
-		display.ShowContentRendered(resp.GetContent())
+		display.ShowContentRendered(content)
 	} else {
-		display.ShowContent(resp.GetContent())
+		display.ShowContent(content)
 	}
 
 	if app.cfg.Usage {
-		fmt.Println()
 		display.ShowUsage(resp.GetUsageMap())
 	}
+
+	return content
+}
+
+// runChoices asks for --n alternative answers and displays them numbered, separated by
+// a rule. The answers are returned joined the same way for --output and citations.
+func (app *App) runChoices(client *api.AzureClient, systemPrompt, userMessage string) string {
+	sp := display.NewSpinner(fmt.Sprintf("Waiting for %d answers...", app.cfg.Choices))
+	sp.Start()
+
+	done := app.timings.track("generate")
+	resp, err := client.QueryChoices(context.Background(), systemPrompt, userMessage, app.cfg.Choices)
+	done()
+	sp.Stop()
+
+	if err != nil {
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+
+	contents := resp.GetContents()
+	for i := range contents {
+		contents[i] = app.applyLengthBudget(contents[i])
+	}
+	display.ShowChoices(contents, app.cfg.Render)
+
+	if app.cfg.Usage {
+		display.ShowUsage(resp.GetUsageMap())
+	}
+
+	return strings.Join(contents, "\n\n"+display.ChoiceSeparator+"\n\n")
 }
 
-func (app *App) runStream(client *api.AzureClient, systemPrompt, userMessage string) {
-	var finalResp *api.ChatResponse
+// countOnly prints the prompt's token count as Azure counts it (--count-only). If the
+// deployment rejects the counting request, the local estimate is printed instead with
+// a warning. With --json the count is returned as the usage of an empty answer.
+func (app *App) countOnly(client *api.AzureClient, query, systemPrompt, userMessage string) *answerOutput {
+	messages := []api.Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userMessage},
+	}
+
+	sp := display.NewSpinner("Counting tokens...")
+	sp.Start()
+	tokens, err := client.CountTokens(context.Background(), messages)
+	sp.Stop()
+	if err != nil {
+		tokens = api.EstimateTokens(messages)
+		display.ShowTokenEstimate(err)
+	}
+
+	if app.cfg.JSON {
+		return &answerOutput{
+			Query: query,
+			Model: app.cfg.Model,
+			Usage: answerUsage{InputTokens: tokens, TotalTokens: tokens},
+		}
+	}
+	fmt.Println(tokens)
+	return nil
+}
+
+// showSearchAnswer displays the answer an answer provider (e.g. Perplexity) returned with
+// its search results, with the usual citations. In --json mode it is returned instead.
+func (app *App) showSearchAnswer(query string) *answerOutput {
+	content := app.applyLengthBudget(app.searchResults.Answer)
+
+	if app.cfg.JSON {
+		out := &answerOutput{
+			Query:   query,
+			Model:   api.PerplexityModel,
+			Content: content,
+		}
+		app.addSearchSources(out)
+		return out
+	}
+
+	if app.cfg.Render {
+		display.ShowContentRendered(content)
+	} else {
+		display.ShowContent(content)
+	}
+	if app.cfg.Citations {
+		app.showCitations(content)
+	}
+	app.writeAnswer(content)
+	return nil
+}
+
+// runStream sends a streaming query, displays the answer as it arrives and returns it
+func (app *App) runStream(client *api.AzureClient, systemPrompt, userMessage string) string {
 	var fullContent strings.Builder
 	firstChunk := true
 
+	// Buffer instead of printing chunks when the output must be rendered, trimmed, or bare
+	buffered := app.cfg.Render || app.hasLengthBudget() || app.cfg.Bare
+
 	sp := display.NewSpinner("Waiting for response...")
 	sp.Start()
 
-	err := client.QueryStream(systemPrompt, userMessage,
+	done := app.timings.track("generate")
+	start := time.Now()
+	messages := []api.Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userMessage},
+	}
+	// The assembled response is returned even without usage, so a blank answer can be retried
+	finalResp, err := client.QueryStreamWithToolsContext(context.Background(), messages, nil,
 		func(content string) {
 			if firstChunk {
 				firstChunk = false
-				if app.cfg.Render {
+				app.timings.firstTokenAfter(start)
+				if buffered {
 					sp.UpdateMessage("Receiving response...")
 				} else {
 					sp.Stop()
 				}
 			}
 
-			if app.cfg.Render {
-				fullContent.WriteString(content)
-			} else {
+			fullContent.WriteString(content)
+			if !buffered {
 				fmt.Print(content)
 			}
 		},
-		func(resp *api.ChatResponse) {
-			finalResp = resp
-		},
 	)
+	done()
 
 	sp.Stop()
 
+	var interrupted *api.StreamInterruptedError
+	if errors.As(err, &interrupted) && interrupted.Partial != "" {
+		// Keep what arrived before the connection dropped, then fail
+		if buffered {
+			display.ShowContent(app.applyLengthBudget(fullContent.String()))
+		} else {
+			fmt.Println()
+		}
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
 	if err != nil {
 		display.ShowError(err.Error())
 		os.Exit(1)
 	}
 
-	if app.cfg.Render {
-		display.ShowContentRendered(fullContent.String())
+	// A retried answer arrives whole, so it is shown like a buffered one
+	if app.shouldRetryAnswer(finalResp) {
+		if !buffered {
+			fmt.Println()
+		}
+		display.ShowAnswerRetry()
+		finalResp = app.retryAnswer(client, messages, finalResp)
+		fullContent.Reset()
+		fullContent.WriteString(finalResp.GetContent())
+		buffered = true
+	}
+
+	if buffered {
+		content := app.applyLengthBudget(fullContent.String())
+		if app.cfg.Render {
+			display.ShowContentRendered(content)
+		} else {
+			display.ShowContent(content)
+		}
 	} else {
 		fmt.Println()
 	}
 
-	if finalResp != nil && app.cfg.Usage {
-		fmt.Println()
+	// Azure only reports usage for a stream when asked, so there may be none to show
+	if app.cfg.Usage && finalResp.Usage.TotalTokens > 0 {
 		display.ShowUsage(finalResp.GetUsageMap())
 	}
+
+	if buffered {
+		return app.applyLengthBudget(fullContent.String())
+	}
+	return fullContent.String()
 }
@@ -0,0 +1,48 @@
+//go:build !safemode
+
+package cmd
+
+import (
+	"context"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/executor"
+)
+
+// safeModeBuild is true in builds with the safemode tag, which always run in safe mode
+const safeModeBuild = false
+
+// shellExecutor runs the commands the AI asks for and holds their permissions.
+// Only this file and its tests import internal/executor, so a safemode build
+// leaves the shell runner out.
+type shellExecutor = executor.Executor
+
+// executionResult is the outcome of a command run for the AI
+type executionResult = executor.ExecutionResult
+
+// dryRunReason is the CheckPermission reason for commands that dry-run mode lets through
+const dryRunReason = executor.DryRunReason
+
+// newShellExecutor creates the executor for an interactive session
+func newShellExecutor() *shellExecutor {
+	return executor.NewExecutor()
+}
+
+// runShellCommand runs a command the AI asked for, once permissions allow it
+func runShellCommand(ctx context.Context, exec *shellExecutor, command string) (*executionResult, error) {
+	return exec.Execute(ctx, command)
+}
+
+// explainCommand describes what a common command does, or returns ""
+func explainCommand(command string) string {
+	return executor.ExplainCommand(command)
+}
+
+// dangerousCategories lists what /allow-dangerous unblocks
+func dangerousCategories() []string {
+	return executor.DangerousCategories()
+}
+
+// appendPatternFile adds an entry to an allowlist or denylist file
+func appendPatternFile(path, pattern string) error {
+	return executor.AppendPatternFile(path, pattern)
+}
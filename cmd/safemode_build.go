@@ -0,0 +1,87 @@
+//go:build safemode
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// safeModeBuild is true in builds with the safemode tag, which always run in safe mode
+const safeModeBuild = true
+
+var errSafeModeBuild = errors.New("command execution is not available in this build")
+
+// shellExecutor stands in for internal/executor in a safemode build, so no code
+// path that starts a shell for the AI is compiled in. Nothing is ever run.
+type shellExecutor struct {
+	permissions *permissionManager
+}
+
+// permissionManager blocks every command
+type permissionManager struct{}
+
+// executionResult is the outcome of a command run for the AI
+type executionResult struct {
+	Command  string
+	Output   string
+	Error    error
+	ExitCode int
+}
+
+// dryRunReason is never returned in a safemode build; commands are always blocked
+const dryRunReason = "Dry run: would run"
+
+func newShellExecutor() *shellExecutor {
+	return &shellExecutor{permissions: &permissionManager{}}
+}
+
+func (e *shellExecutor) GetPermissionManager() *permissionManager { return e.permissions }
+func (e *shellExecutor) EnableCache(bool)                         {}
+func (e *shellExecutor) ClearCache()                              {}
+func (e *shellExecutor) IsCached(string) bool                     { return false }
+func (e *shellExecutor) SetTimeout(time.Duration)                 {}
+func (e *shellExecutor) Timeout() time.Duration                   { return 0 }
+
+func (pm *permissionManager) CheckPermission(string) (bool, bool, string) {
+	return false, false, errSafeModeBuild.Error()
+}
+func (pm *permissionManager) AddToAllowlist(string)          {}
+func (pm *permissionManager) AddDenyPattern(string)          {}
+func (pm *permissionManager) EnableDangerous()               {}
+func (pm *permissionManager) DisableDangerous()              {}
+func (pm *permissionManager) SetDryRun(bool)                 {}
+func (pm *permissionManager) DryRun() bool                   { return false }
+func (pm *permissionManager) LoadAllowlistFile(string) error { return nil }
+func (pm *permissionManager) LoadDenylistFile(string) error  { return nil }
+func (pm *permissionManager) GetSettings() map[string]interface{} {
+	return map[string]interface{}{
+		"auto_allow_reads":  false,
+		"dangerous_enabled": false,
+		"allowlist_count":   0,
+		"prefix_count":      0,
+		"denylist_count":    0,
+		"dry_run":           false,
+	}
+}
+
+// IsSuccess reports whether the command succeeded; nothing does in a safemode build
+func (r *executionResult) IsSuccess() bool { return false }
+
+// FormatResult formats the result for the AI
+func (r *executionResult) FormatResult() string {
+	return fmt.Sprintf("Command not run: %v", r.Error)
+}
+
+// runShellCommand never runs anything in a safemode build
+func runShellCommand(ctx context.Context, exec *shellExecutor, command string) (*executionResult, error) {
+	return &executionResult{Command: command, Error: errSafeModeBuild, ExitCode: -1}, errSafeModeBuild
+}
+
+func explainCommand(string) string { return "" }
+
+func dangerousCategories() []string { return nil }
+
+func appendPatternFile(string, string) error { return errSafeModeBuild }
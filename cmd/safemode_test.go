@@ -0,0 +1,126 @@
+//go:build !safemode
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+)
+
+// Tests that need the real command executor, which safemode builds leave out
+
+func TestDryRunToolCalls(t *testing.T) {
+	app := &App{cfg: &config.Config{}}
+	exec := newShellExecutor()
+	exec.GetPermissionManager().SetDryRun(true)
+	dir := t.TempDir()
+
+	touched := filepath.Join(dir, "touched")
+	command := api.ToolCall{}
+	command.Function.Name = api.ExecuteCommandTool.Function.Name
+	command.Function.Arguments = fmt.Sprintf(`{"command":"touch %s","reasoning":"test"}`, touched)
+	if got := app.handleToolCall(context.Background(), exec, command); got != DryRunToolMessage {
+		t.Errorf("command = %q, want %q", got, DryRunToolMessage)
+	}
+	if _, err := os.Stat(touched); !os.IsNotExist(err) {
+		t.Errorf("dry run ran the command: %v", err)
+	}
+
+	written := filepath.Join(dir, "a.txt")
+	write := api.ToolCall{ID: "1"}
+	write.Function.Name = api.WriteFileTool.Function.Name
+	write.Function.Arguments = fmt.Sprintf(`{"path":%q,"content":"hello\n","reasoning":"test"}`, written)
+	if got := app.handleToolCall(context.Background(), exec, write); got != DryRunToolMessage {
+		t.Errorf("write = %q, want %q", got, DryRunToolMessage)
+	}
+	if _, err := os.Stat(written); !os.IsNotExist(err) {
+		t.Errorf("dry run wrote the file: %v", err)
+	}
+
+	// Blocked commands are still reported as blocked
+	command.Function.Arguments = `{"command":"sudo reboot","reasoning":"test"}`
+	if got := app.handleToolCall(context.Background(), exec, command); !strings.HasPrefix(got, "Command blocked") {
+		t.Errorf("dangerous command = %q, want blocked", got)
+	}
+}
+
+func TestToolTurnSegments(t *testing.T) {
+	// The first response explains itself and calls a tool; the second answers
+	responses := []string{
+		`data: {"choices":[{"delta":{"role":"assistant","content":"Let me check the file."}}]}
+
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"write_file","arguments":"{\"path\":\"out.txt\",\"content\":\"x\"}"}}]}}]}
+
+data: {"choices":[{"delta":{},"finish_reason":"tool_calls"}]}
+
+data: [DONE]
+
+`,
+		`data: {"choices":[{"delta":{"content":"All done."}}]}
+
+data: {"choices":[{"delta":{},"finish_reason":"stop"}]}
+
+data: [DONE]
+
+`,
+	}
+
+	for _, render := range []bool{false, true} {
+		t.Run(fmt.Sprintf("render=%v", render), func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = w.Write([]byte(responses[min(calls, len(responses)-1)]))
+				calls++
+			}))
+			defer server.Close()
+
+			cfg := &config.Config{AzureEndpoint: server.URL, AzureAPIKey: "test-key", Model: "test", Stream: true, Render: render}
+			app := &App{cfg: cfg}
+			exec := newShellExecutor()
+			exec.GetPermissionManager().SetDryRun(true) // Nothing is written
+			messages := []api.Message{{Role: "system", Content: "sys"}, {Role: "user", Content: "fix it"}}
+
+			var answer string
+			out := captureStdout(t, func() {
+				var err error
+				answer, _, err = app.sendInteractiveMessageWithTools(api.NewAzureClient(cfg), exec, &messages, nil)
+				if err != nil {
+					t.Errorf("sendInteractiveMessageWithTools() error = %v", err)
+				}
+			})
+			if answer != "All done." {
+				t.Errorf("answer = %q, want %q", answer, "All done.")
+			}
+			first, final := strings.Index(out, "Let me check the file."), strings.Index(out, "All done.")
+			if first < 0 || final < first {
+				t.Errorf("stdout = %q, want the text before the tool call, then the answer", out)
+			}
+		})
+	}
+}
+
+func TestDenyCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "denylist")
+	s := &InteractiveSession{
+		app:  &App{cfg: &config.Config{DenylistFile: path}},
+		exec: newShellExecutor(),
+	}
+
+	s.handleCommand("/deny git push*")
+	if allowed, needsConfirm, _ := s.exec.GetPermissionManager().CheckPermission("git push origin"); allowed || needsConfirm {
+		t.Error("git push should be blocked after /deny")
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "git push*\n" {
+		t.Errorf("denylist file = %q, %v, want the pattern saved", data, err)
+	}
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+	"github.com/quocvuong92/azure-ai-cli/internal/display"
+)
+
+// sessionFileExt is the extension of saved conversation files
+const sessionFileExt = ".json"
+
+// sessionNamePattern restricts session names to safe file names
+var sessionNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+var errInvalidSessionName = errors.New("invalid session name (use letters, digits, '.', '_' and '-')")
+
+// savedSession is a saved conversation listed by /sessions
+type savedSession struct {
+	Name    string
+	ModTime time.Time
+}
+
+// sessionPath returns the file for a named session in dir
+func sessionPath(dir, name string) (string, error) {
+	name = strings.TrimSuffix(name, sessionFileExt)
+	if !sessionNamePattern.MatchString(name) {
+		return "", errInvalidSessionName
+	}
+	return filepath.Join(dir, name+sessionFileExt), nil
+}
+
+// writeSession saves messages as JSON. Pasted images are not stored: they are dropped
+// so each message's content is written as plain text that readSession can load.
+func writeSession(dir, name string, messages []api.Message) error {
+	path, err := sessionPath(dir, name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create sessions directory: %w", err)
+	}
+	saved := make([]api.Message, len(messages))
+	for i, msg := range messages {
+		msg.Images = nil
+		saved[i] = msg
+	}
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
+
+// readSession loads a saved conversation. When it has no system message, system is put first.
+func readSession(dir, name string, system api.Message) ([]api.Message, error) {
+	path, err := sessionPath(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("no saved session named %q", strings.TrimSuffix(name, sessionFileExt))
+		}
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+	var messages []api.Message
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, fmt.Errorf("failed to parse session %s: %w", path, err)
+	}
+	if len(messages) == 0 || messages[0].Role != "system" {
+		messages = append([]api.Message{system}, messages...)
+	}
+	return messages, nil
+}
+
+// listSessions returns the saved sessions in dir, most recent first
+func listSessions(dir string) ([]savedSession, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var sessions []savedSession
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), sessionFileExt) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, savedSession{Name: strings.TrimSuffix(e.Name(), sessionFileExt), ModTime: info.ModTime()})
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ModTime.After(sessions[j].ModTime) })
+	return sessions, nil
+}
+
+// saveSession handles "/save <name>"
+func (s *InteractiveSession) saveSession(name string) {
+	dir, err := config.GetSessionsDir()
+	if err == nil {
+		err = writeSession(dir, name, s.messages)
+	}
+	if err != nil {
+		display.ShowError(err.Error())
+		return
+	}
+	fmt.Printf("Session saved as %q (%d messages).\n", strings.TrimSuffix(name, sessionFileExt), len(s.messages))
+}
+
+// loadSession handles "/load <name>", replacing the conversation
+func (s *InteractiveSession) loadSession(name string) {
+	dir, err := config.GetSessionsDir()
+	if err != nil {
+		display.ShowError(err.Error())
+		return
+	}
+	messages, err := readSession(dir, name, s.messages[0])
+	if err != nil {
+		display.ShowError(err.Error())
+		return
+	}
+	s.messages = messages
+	fmt.Printf("Session %q loaded (%d messages).\n", strings.TrimSuffix(name, sessionFileExt), len(messages))
+}
+
+// showSessions handles "/sessions"
+func (s *InteractiveSession) showSessions() {
+	dir, err := config.GetSessionsDir()
+	if err != nil {
+		display.ShowError(err.Error())
+		return
+	}
+	sessions, err := listSessions(dir)
+	if err != nil {
+		display.ShowError(fmt.Sprintf("Failed to list sessions: %v", err))
+		return
+	}
+	if len(sessions) == 0 {
+		fmt.Println("No saved sessions. Use /save <name> to save this one.")
+		return
+	}
+	for _, saved := range sessions {
+		fmt.Printf("  %-24s %s\n", saved.Name, saved.ModTime.Format("2006-01-02 15:04"))
+	}
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+)
+
+func TestSessionSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	system := api.Message{Role: "system", Content: "current prompt"}
+
+	saved := []api.Message{
+		{Role: "system", Content: "saved prompt"},
+		{Role: "user", Content: "why does the build fail?"},
+		{Role: "assistant", ToolCalls: []api.ToolCall{{ID: "call_1", Type: "function"}}},
+		{Role: "tool", Content: "exit status 1", ToolCallID: "call_1"},
+		{Role: "assistant", Content: "A missing import."},
+	}
+	if err := writeSession(dir, "debug-1", saved); err != nil {
+		t.Fatalf("writeSession() error = %v", err)
+	}
+	got, err := readSession(dir, "debug-1", system)
+	if err != nil {
+		t.Fatalf("readSession() error = %v", err)
+	}
+	if len(got) != len(saved) || got[0].Content != "saved prompt" || got[3].ToolCallID != "call_1" || len(got[2].ToolCalls) != 1 {
+		t.Errorf("readSession() = %+v, want the saved messages", got)
+	}
+
+	// A file without a system message gets the current one
+	if err := writeSession(dir, "bare", saved[1:2]); err != nil {
+		t.Fatalf("writeSession() error = %v", err)
+	}
+	got, err = readSession(dir, "bare.json", system)
+	if err != nil {
+		t.Fatalf("readSession() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Content != system.Content {
+		t.Errorf("readSession() without system = %+v, want current system prompt first", got)
+	}
+
+	sessions, err := listSessions(dir)
+	if err != nil || len(sessions) != 2 {
+		t.Errorf("listSessions() = %+v, %v, want 2 sessions", sessions, err)
+	}
+
+	if _, err := readSession(dir, "missing", system); err == nil {
+		t.Error("readSession() of a missing session succeeded")
+	}
+	for _, name := range []string{"../escape", "a/b", ".hidden", ""} {
+		if err := writeSession(dir, name, saved); err == nil {
+			t.Errorf("writeSession(%q) succeeded, want invalid name error", name)
+		}
+	}
+}
+
+func TestSessionWithImage(t *testing.T) {
+	dir := t.TempDir()
+	messages := []api.Message{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: "what is in this picture?", Images: []string{"data:image/png;base64,iVBORw0KGgo="}},
+		{Role: "assistant", Content: "A cat."},
+	}
+	if err := writeSession(dir, "photo", messages); err != nil {
+		t.Fatalf("writeSession() error = %v", err)
+	}
+	got, err := readSession(dir, "photo", messages[0])
+	if err != nil {
+		t.Fatalf("readSession() error = %v", err)
+	}
+	if len(got) != 3 || got[1].Content != "what is in this picture?" || len(got[1].Images) != 0 {
+		t.Errorf("readSession() = %+v, want the text kept and the image dropped", got)
+	}
+	if len(messages[1].Images) != 1 {
+		t.Error("writeSession() changed the caller's messages")
+	}
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+	"github.com/quocvuong92/azure-ai-cli/internal/display"
+)
+
+// sourceResult is one search result in --only-sources JSON output
+type sourceResult struct {
+	Title   string  `json:"title"`
+	URL     string  `json:"url"`
+	Snippet string  `json:"snippet"`
+	Score   float64 `json:"score,omitempty"`
+}
+
+// sourcesOutput is the --only-sources --json document
+type sourcesOutput struct {
+	Query    string         `json:"query"`
+	Provider string         `json:"provider"`
+	Results  []sourceResult `json:"results"`
+}
+
+// runOnlySources searches each query and prints the results without calling Azure.
+// With several queries, --json emits an array of documents instead of one.
+// Exits non-zero on search errors, or on any query with zero results under --fail-empty.
+func (app *App) runOnlySources(queries []string) {
+	var outputs []sourcesOutput
+	empty := false
+
+	for i, query := range queries {
+		app.searchResults = nil
+		app.searchQuery = ""
+		app.timings.reset()
+
+		if _, err := app.performWebSearch(query); err != nil {
+			display.ShowError(err.Error())
+			os.Exit(1)
+		}
+
+		var results []display.Citation
+		if app.searchResults != nil {
+			for _, r := range app.searchResults.Results {
+				results = append(results, display.Citation{Title: r.Title, URL: r.URL, Snippet: r.Content, Score: r.Score})
+			}
+		}
+		if len(results) == 0 {
+			empty = true
+		}
+
+		if app.cfg.JSON {
+			out := sourcesOutput{Query: app.searchQuery, Provider: app.cfg.WebSearchProvider, Results: []sourceResult{}}
+			for _, r := range results {
+				out.Results = append(out.Results, sourceResult{Title: r.Title, URL: r.URL, Snippet: r.Snippet, Score: r.Score})
+			}
+			outputs = append(outputs, out)
+		} else {
+			if len(queries) > 1 {
+				display.ShowQueryHeader(i+1, len(queries), query)
+			}
+			if len(results) > 0 {
+				display.ShowSearchResults(results)
+			} else {
+				fmt.Fprintln(os.Stderr, "No results found.")
+			}
+		}
+
+		app.showTimings()
+	}
+
+	if app.cfg.JSON {
+		var v interface{} = outputs
+		if len(outputs) == 1 {
+			v = outputs[0]
+		}
+		if err := display.ShowJSON(v); err != nil {
+			display.ShowError(err.Error())
+			os.Exit(1)
+		}
+	}
+
+	if empty && app.cfg.FailEmpty {
+		os.Exit(1)
+	}
+}
+
+// shouldPickSources reports whether --pick-sources asks the user to choose results.
+// Scripts (no terminal on stdin or stdout), --json, --bare and --only-sources keep
+// every result, as do answer providers whose answer was written from all of them.
+func (app *App) shouldPickSources(provider string, results *api.TavilyResponse) bool {
+	if !app.cfg.PickSources || app.cfg.OnlySources || app.cfg.JSON || app.cfg.Bare {
+		return false
+	}
+	if len(results.Results) < 2 || (config.IsAnswerProvider(provider) && results.Answer != "") {
+		return false
+	}
+	return isTerminal(os.Stdin) && isTerminal(os.Stdout)
+}
+
+// pickSources lists search results and returns the ones the user selects, in order.
+// Keeping only the picked results also renumbers them, so the context given to
+// the model and the citations shown after the answer stay in step.
+func pickSources(results []api.TavilyResult, query string) []api.TavilyResult {
+	citations := make([]display.Citation, len(results))
+	for i, r := range results {
+		citations[i] = display.Citation{Title: r.Title, URL: r.URL}
+	}
+	fmt.Println()
+	display.ShowCitations(citations, query)
+
+	for {
+		picked, err := parseSourceSelection(display.AskSourceSelection(), len(results))
+		if err != nil {
+			display.ShowError(err.Error())
+			continue
+		}
+		if picked == nil {
+			return results
+		}
+		kept := make([]api.TavilyResult, len(picked))
+		for i, n := range picked {
+			kept[i] = results[n-1]
+		}
+		return kept
+	}
+}
+
+// parseSourceSelection parses a selection like "1,3-4" of result numbers 1..n into
+// sorted, unique numbers. Empty input means all results and returns nil.
+func parseSourceSelection(input string, n int) ([]int, error) {
+	input = strings.TrimSpace(input)
+	if input == "" || strings.EqualFold(input, "all") {
+		return nil, nil
+	}
+
+	selected := make(map[int]bool)
+	for _, field := range strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' }) {
+		lo, hi, isRange := strings.Cut(field, "-")
+		if !isRange {
+			hi = lo
+		}
+		from, err1 := strconv.Atoi(strings.TrimSpace(lo))
+		to, err2 := strconv.Atoi(strings.TrimSpace(hi))
+		if err1 != nil || err2 != nil || from < 1 || to > n || from > to {
+			return nil, fmt.Errorf("invalid selection %q: use numbers from 1 to %d, e.g. 1,3-4", field, n)
+		}
+		for i := from; i <= to; i++ {
+			selected[i] = true
+		}
+	}
+
+	picked := make([]int, 0, len(selected))
+	for i := 1; i <= n; i++ {
+		if selected[i] {
+			picked = append(picked, i)
+		}
+	}
+	return picked, nil
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+)
+
+func TestParseSourceSelection(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []int
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"all", nil, false},
+		{"2", []int{2}, false},
+		{"3, 1", []int{1, 3}, false},
+		{"1,3-4", []int{1, 3, 4}, false},
+		{"2-3 3", []int{2, 3}, false},
+		{"0", nil, true},
+		{"6", nil, true},
+		{"4-2", nil, true},
+		{"two", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseSourceSelection(tt.input, 5)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSourceSelection(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSourceSelection(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunOnlySources(t *testing.T) {
+	tests := []struct {
+		name    string
+		queries []string
+		want    []sourcesOutput
+	}{
+		{"one query is one document", []string{"go"}, []sourcesOutput{
+			{Query: "go", Provider: "tavily", Results: []sourceResult{{Title: "go", URL: "https://example.com/go"}}},
+		}},
+		{"several queries are an array", []string{"go", "rust"}, []sourcesOutput{
+			{Query: "go", Provider: "tavily", Results: []sourceResult{{Title: "go", URL: "https://example.com/go"}}},
+			{Query: "rust", Provider: "tavily", Results: []sourceResult{{Title: "rust", URL: "https://example.com/rust"}}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			search := &stubSearch{}
+			// No Azure client: --only-sources must not ask the model
+			app := &App{
+				cfg:           &config.Config{WebSearchProvider: "tavily", OnlySources: true, JSON: true},
+				searchClients: map[string]api.SearchClient{"tavily": search},
+			}
+			out := captureStdout(t, func() { app.runOnlySources(tt.queries) })
+
+			var got []sourcesOutput
+			if len(tt.queries) == 1 {
+				var doc sourcesOutput
+				if err := json.Unmarshal([]byte(out), &doc); err != nil {
+					t.Fatalf("stdout %q is not one JSON document: %v", out, err)
+				}
+				got = []sourcesOutput{doc}
+			} else if err := json.Unmarshal([]byte(out), &got); err != nil {
+				t.Fatalf("stdout %q is not a JSON array: %v", out, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("output = %+v, want %+v", got, tt.want)
+			}
+			if search.searches != len(tt.queries) {
+				t.Errorf("searches = %d, want %d", search.searches, len(tt.queries))
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// readPipedInput reads all of f when it is a pipe or a redirected file, e.g.
+// "cat notes.md | azure-ai ..." or "azure-ai ... < notes.md". A terminal, or
+// another device such as /dev/null, yields nothing so the CLI never waits on it.
+func readPipedInput(f *os.File) (string, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return "", nil
+	}
+	mode := info.Mode()
+	if mode&os.ModeNamedPipe == 0 && !mode.IsRegular() {
+		return "", nil
+	}
+	data, err := io.ReadAll(bufio.NewReaderSize(f, 64*1024))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// mergePipedInput appends piped input to each query, or makes it the query when none was given
+func mergePipedInput(queries []string, input string) []string {
+	if strings.TrimSpace(input) == "" {
+		return queries
+	}
+	if len(queries) == 0 {
+		return []string{input}
+	}
+	merged := make([]string, len(queries))
+	for i, q := range queries {
+		merged[i] = q + "\n\n" + input
+	}
+	return merged
+}
+
+// isTerminal reports whether f is a terminal rather than a pipe, file or other device
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadPipedInput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.md")
+	large := strings.Repeat("line of text\n", 20000)
+	if err := os.WriteFile(path, []byte(large), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	got, err := readPipedInput(f)
+	if err != nil {
+		t.Fatalf("readPipedInput() error = %v", err)
+	}
+	if want := strings.TrimSuffix(large, "\n"); got != want {
+		t.Errorf("readPipedInput() returned %d bytes, want %d without the trailing newline", len(got), len(want))
+	}
+
+	// Devices are never read, so a closed-over stdin cannot block
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = devNull.Close() }()
+	if got, err := readPipedInput(devNull); got != "" || err != nil {
+		t.Errorf("readPipedInput(%s) = %q, %v, want empty", os.DevNull, got, err)
+	}
+}
+
+func TestMergePipedInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		queries []string
+		input   string
+		want    []string
+	}{
+		{"no input", []string{"q"}, "", []string{"q"}},
+		{"whitespace input", []string{"q"}, " \n", []string{"q"}},
+		{"input only", nil, "data", []string{"data"}},
+		{"appended", []string{"summarize this"}, "data", []string{"summarize this\n\ndata"}},
+		{"each query", []string{"a", "b"}, "data", []string{"a\n\ndata", "b\n\ndata"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergePipedInput(tt.queries, tt.input)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+				t.Errorf("mergePipedInput(%q, %q) = %q, want %q", tt.queries, tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync/atomic"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/display"
+)
+
+// errTurnAborted is returned when the user aborts a tool-using turn with Ctrl+C
+var errTurnAborted = errors.New("turn aborted")
+
+// Steering choices offered when a turn is paused
+const (
+	steerContinue = "continue"
+	steerMessage  = "steer"
+	steerAbort    = "abort"
+)
+
+// turnInterrupts turns Ctrl+C during a tool-using turn into a pause request: the
+// turn stops at the next step and asks the user what to do. A second Ctrl+C
+// cancels the turn's context, stopping the running request or command.
+type turnInterrupts struct {
+	requested atomic.Bool
+	cancel    context.CancelFunc
+	signals   chan os.Signal
+	done      chan struct{}
+}
+
+// watchInterrupts starts handling Ctrl+C for one turn; call stop when the turn ends
+func watchInterrupts(parent context.Context) (context.Context, *turnInterrupts) {
+	ctx, cancel := context.WithCancel(parent)
+	ti := &turnInterrupts{
+		cancel:  cancel,
+		signals: make(chan os.Signal, 1),
+		done:    make(chan struct{}),
+	}
+	signal.Notify(ti.signals, os.Interrupt)
+	go func() {
+		for {
+			select {
+			case <-ti.signals:
+				ti.interrupt()
+			case <-ti.done:
+				return
+			}
+		}
+	}()
+	return ctx, ti
+}
+
+// interrupt records a Ctrl+C: the first asks for a pause, the next cancels the turn
+func (ti *turnInterrupts) interrupt() {
+	if ti.requested.Swap(true) {
+		ti.cancel()
+		return
+	}
+	display.ShowPausePending()
+}
+
+// dropPending tells the user a pause requested too late to take effect, e.g. while
+// the final answer streamed, was ignored. Call it before stop.
+func (ti *turnInterrupts) dropPending(ctx context.Context) {
+	if ctx.Err() == nil && ti.requested.Swap(false) {
+		display.ShowPauseIgnored()
+	}
+}
+
+// stop restores the default Ctrl+C handling
+func (ti *turnInterrupts) stop() {
+	signal.Stop(ti.signals)
+	close(ti.done)
+	ti.cancel()
+}
+
+// pause asks the user how to go on when a pause was requested, returning the choice and,
+// for steerMessage, the new instructions. A cancelled turn aborts without asking.
+func (ti *turnInterrupts) pause(ctx context.Context) (string, string) {
+	if ctx.Err() != nil {
+		return steerAbort, ""
+	}
+	if !ti.requested.Load() {
+		return steerContinue, ""
+	}
+	choice, message := display.AskSteering()
+	ti.requested.Store(false)
+	if choice == steerMessage && message == "" {
+		return steerContinue, ""
+	}
+	return choice, message
+}
+
+// skippedToolResults answers tool calls that were not run because the user paused the
+// turn; the API requires a tool message for every call
+func skippedToolResults(calls []api.ToolCall) []api.Message {
+	results := make([]api.Message, len(calls))
+	for i, tc := range calls {
+		results[i] = api.Message{Role: "tool", Content: SkippedToolCallMessage, ToolCallID: tc.ID}
+	}
+	return results
+}
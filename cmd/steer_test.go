@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+)
+
+func TestTurnInterrupts(t *testing.T) {
+	ctx, ti := watchInterrupts(context.Background())
+	defer ti.stop()
+
+	if choice, _ := ti.pause(ctx); choice != steerContinue {
+		t.Errorf("pause() without Ctrl+C = %q, want %q", choice, steerContinue)
+	}
+
+	ti.interrupt()
+	if ctx.Err() != nil {
+		t.Fatal("first Ctrl+C should only request a pause")
+	}
+	ti.interrupt()
+	if ctx.Err() == nil {
+		t.Fatal("second Ctrl+C should cancel the turn")
+	}
+	if choice, _ := ti.pause(ctx); choice != steerAbort {
+		t.Errorf("pause() after cancel = %q, want %q", choice, steerAbort)
+	}
+}
+
+func TestDropPendingPause(t *testing.T) {
+	ctx, ti := watchInterrupts(context.Background())
+	defer ti.stop()
+
+	ti.interrupt() // Ctrl+C while the final answer streams
+	ti.dropPending(ctx)
+	if ti.requested.Load() {
+		t.Error("dropPending() left the pause request set")
+	}
+	if ctx.Err() != nil {
+		t.Error("dropPending() cancelled the turn")
+	}
+}
+
+func TestSkippedToolResults(t *testing.T) {
+	calls := []api.ToolCall{{ID: "call_1"}, {ID: "call_2"}}
+	results := skippedToolResults(calls)
+	if len(results) != len(calls) {
+		t.Fatalf("got %d results, want %d", len(results), len(calls))
+	}
+	for i, r := range results {
+		if r.Role != "tool" || r.ToolCallID != calls[i].ID || r.Content != SkippedToolCallMessage {
+			t.Errorf("result %d = %+v", i, r)
+		}
+	}
+}
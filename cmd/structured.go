@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+)
+
+// maxSchemaNameLength is the longest json_schema name Azure accepts
+const maxSchemaNameLength = 64
+
+// invalidSchemaNameChars matches characters not allowed in a json_schema name
+var invalidSchemaNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// loadResponseFormat builds the structured output format for --json-mode and --json-schema,
+// or returns nil when neither is set. The schema file holds either a bare JSON schema or
+// the full {"name", "schema", "strict"} object.
+func loadResponseFormat(jsonMode bool, schemaFile string) (*api.ResponseFormat, error) {
+	if schemaFile == "" {
+		if !jsonMode {
+			return nil, nil
+		}
+		return &api.ResponseFormat{Type: api.ResponseFormatJSONObject}, nil
+	}
+
+	data, err := os.ReadFile(schemaFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON schema: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("JSON schema %s: %w", schemaFile, err)
+	}
+
+	schema := &api.JSONSchemaFormat{Schema: json.RawMessage(data)}
+	if _, wrapped := fields["schema"]; wrapped {
+		if err := json.Unmarshal(data, schema); err != nil {
+			return nil, fmt.Errorf("JSON schema %s: %w", schemaFile, err)
+		}
+	}
+	if schema.Name == "" {
+		schema.Name = schemaName(schemaFile)
+	}
+	return &api.ResponseFormat{Type: api.ResponseFormatJSONSchema, JSONSchema: schema}, nil
+}
+
+// schemaName derives a valid json_schema name from the schema file name
+func schemaName(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	name = invalidSchemaNameChars.ReplaceAllString(name, "_")
+	if len(name) > maxSchemaNameLength {
+		name = name[:maxSchemaNameLength]
+	}
+	if name == "" {
+		return "response"
+	}
+	return name
+}
+
+// withJSONHint appends JSONModeHint to a system prompt that never mentions JSON,
+// which Azure requires for json_object responses
+func withJSONHint(systemPrompt string) string {
+	if strings.Contains(strings.ToLower(systemPrompt), "json") {
+		return systemPrompt
+	}
+	return systemPrompt + "\n\n" + JSONModeHint
+}
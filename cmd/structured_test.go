@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+)
+
+func TestLoadResponseFormat(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	bare := write("release info.json", `{"type":"object","properties":{"version":{"type":"string"}}}`)
+	wrapped := write("wrapped.json", `{"name":"release","strict":true,"schema":{"type":"object"}}`)
+	invalid := write("invalid.json", `{"type":`)
+
+	if format, err := loadResponseFormat(false, ""); err != nil || format != nil {
+		t.Errorf("no flags = %+v, %v, want nil", format, err)
+	}
+	if format, err := loadResponseFormat(true, ""); err != nil || format.Type != api.ResponseFormatJSONObject {
+		t.Errorf("--json-mode = %+v, %v, want json_object", format, err)
+	}
+
+	format, err := loadResponseFormat(false, bare)
+	if err != nil {
+		t.Fatalf("bare schema error = %v", err)
+	}
+	if format.Type != api.ResponseFormatJSONSchema || format.JSONSchema.Name != "release_info" || string(format.JSONSchema.Schema) != `{"type":"object","properties":{"version":{"type":"string"}}}` {
+		t.Errorf("bare schema = %+v", format.JSONSchema)
+	}
+
+	format, err = loadResponseFormat(true, wrapped)
+	if err != nil {
+		t.Fatalf("wrapped schema error = %v", err)
+	}
+	if s := format.JSONSchema; s.Name != "release" || !s.Strict || string(s.Schema) != `{"type":"object"}` {
+		t.Errorf("wrapped schema = %+v", s)
+	}
+
+	if _, err := loadResponseFormat(false, invalid); err == nil {
+		t.Error("invalid schema should fail")
+	}
+	if _, err := loadResponseFormat(false, filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("missing schema file should fail")
+	}
+}
+
+func TestWithJSONHint(t *testing.T) {
+	if got := withJSONHint("Answer as json."); got != "Answer as json." {
+		t.Errorf("prompt mentioning JSON changed to %q", got)
+	}
+	if got := withJSONHint("Be precise."); got != "Be precise.\n\n"+JSONModeHint {
+		t.Errorf("withJSONHint() = %q, want hint appended", got)
+	}
+}
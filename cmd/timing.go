@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/display"
+)
+
+// phaseTimings accumulates how long each phase of a request took, in first-seen order
+type phaseTimings struct {
+	phases     []display.PhaseTiming
+	firstToken time.Duration // Time to the first streamed token, zero until one arrives
+}
+
+// track starts timing a phase and returns a function that records it when called
+func (t *phaseTimings) track(name string) func() {
+	start := time.Now()
+	return func() {
+		t.add(name, time.Since(start))
+	}
+}
+
+// add records a duration, summing repeated phases (e.g. several generate calls in a tool loop)
+func (t *phaseTimings) add(name string, d time.Duration) {
+	for i := range t.phases {
+		if t.phases[i].Name == name {
+			t.phases[i].Duration += d
+			return
+		}
+	}
+	t.phases = append(t.phases, display.PhaseTiming{Name: name, Duration: d})
+}
+
+// firstTokenAfter records the time to first token of a request sent at start.
+// Only the first token of a query or turn counts; later calls are ignored.
+func (t *phaseTimings) firstTokenAfter(start time.Time) {
+	if t.firstToken == 0 {
+		t.firstToken = time.Since(start)
+	}
+}
+
+// reset clears recorded phases before a new request or interactive turn
+func (t *phaseTimings) reset() {
+	t.phases = nil
+	t.firstToken = 0
+}
+
+// showTimings prints the time to first token when --timing or --usage is set,
+// and the phase breakdown to stderr when --timing is set
+func (app *App) showTimings() {
+	if app.timings.firstToken > 0 && (app.cfg.Timing || app.cfg.Usage) {
+		display.ShowFirstToken(app.timings.firstToken)
+	}
+	if !app.cfg.Timing || len(app.timings.phases) == 0 {
+		return
+	}
+	display.ShowTimings(app.timings.phases)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+	"github.com/quocvuong92/azure-ai-cli/internal/display"
+)
+
+func TestFirstTokenAfter(t *testing.T) {
+	var timings phaseTimings
+	timings.firstTokenAfter(time.Now().Add(-time.Second))
+	first := timings.firstToken
+	if first < time.Second {
+		t.Fatalf("firstToken = %v, want at least 1s", first)
+	}
+
+	// Later tokens and requests in the same turn don't move it
+	timings.firstTokenAfter(time.Now())
+	if timings.firstToken != first {
+		t.Errorf("firstToken changed to %v, want %v", timings.firstToken, first)
+	}
+
+	timings.reset()
+	if timings.firstToken != 0 {
+		t.Errorf("firstToken after reset = %v, want 0", timings.firstToken)
+	}
+}
+
+func TestStreamWithToolsFirstToken(t *testing.T) {
+	const delay = 50 * time.Millisecond
+
+	tests := []struct {
+		name      string
+		chunk     string
+		wantFirst bool
+	}{
+		{"content", `{"choices":[{"delta":{"content":"hi"},"finish_reason":"stop"}]}`, true},
+		{"tool call only", `{"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function",` +
+			`"function":{"name":"execute_command","arguments":"{}"}}]},"finish_reason":"tool_calls"}]}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(delay)
+				_, _ = w.Write([]byte("data: " + tt.chunk + "\n\ndata: [DONE]\n\n"))
+			}))
+			defer server.Close()
+
+			cfg := &config.Config{AzureEndpoint: server.URL, AzureAPIKey: "test-key", Model: "gpt-4o", Stream: true}
+			app := &App{cfg: cfg}
+			sp := display.NewSpinner("Thinking...")
+			captureStdout(t, func() {
+				_, _, err := app.streamWithTools(context.Background(), api.NewAzureClient(cfg),
+					[]api.Message{{Role: "user", Content: "hi"}}, api.GetDefaultTools(), sp)
+				if err != nil {
+					t.Errorf("streamWithTools() error = %v", err)
+				}
+			})
+			sp.Stop()
+
+			if got := app.timings.firstToken; (got > 0) != tt.wantFirst || tt.wantFirst && got < delay {
+				t.Errorf("firstToken = %v, want it recorded = %v (at least %v)", got, tt.wantFirst, delay)
+			}
+		})
+	}
+}
@@ -0,0 +1,346 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/display"
+)
+
+// registerTools sets up the tools offered to the AI from the configuration.
+// An unreadable --tools-file is fatal.
+func (app *App) registerTools() {
+	// Safe mode keeps chat and web search but offers the AI no way to run commands
+	api.EnableCommandTools(!app.cfg.SafeMode)
+
+	if app.cfg.ToolsFile != "" {
+		tools, err := api.LoadCustomTools(app.cfg.ToolsFile)
+		if err != nil {
+			display.ShowError(err.Error())
+			os.Exit(1)
+		}
+		api.RegisterCustomTools(tools)
+	}
+
+	// Offer the web_search tool when the search provider has keys
+	api.EnableWebSearchTool(app.cfg.HasSearchKeys(app.cfg.WebSearchProvider) && app.cfg.MaxSearches > 0)
+}
+
+// toolListings describes the registered tools for --list-tools and /tools,
+// with why a disabled tool is off
+func (app *App) toolListings() []display.ToolListing {
+	var listings []display.ToolListing
+	for _, t := range api.RegisteredTools() {
+		listing := display.ToolListing{
+			Name:        t.Tool.Function.Name,
+			Description: t.Tool.Function.Description,
+			Parameters:  t.Tool.Function.ParameterSummary(),
+			Custom:      t.Custom,
+			Enabled:     t.Enabled,
+		}
+		if !t.Enabled {
+			switch {
+			case t.Tool.Function.Name != api.WebSearchTool.Function.Name:
+				listing.Disabled = "safe mode"
+			case app.cfg.MaxSearches <= 0:
+				listing.Disabled = "--max-searches 0"
+			default:
+				listing.Disabled = "no search API keys"
+			}
+		}
+		listings = append(listings, listing)
+	}
+	return listings
+}
+
+// handleToolCall dispatches a single tool call and returns the result to send back to the model
+func (app *App) handleToolCall(ctx context.Context, exec *shellExecutor, toolCall api.ToolCall) string {
+	name := toolCall.Function.Name
+
+	// Safe mode offers no command tools, but a model may still ask for one
+	if app.cfg.SafeMode && name != api.WebSearchTool.Function.Name {
+		return SafeModeToolMessage
+	}
+
+	if name == api.ExecuteCommandTool.Function.Name {
+		var args struct {
+			Command   string `json:"command"`
+			Reasoning string `json:"reasoning"`
+		}
+		if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args); err != nil {
+			display.ShowError(fmt.Sprintf("Failed to parse tool arguments: %v", err))
+			return fmt.Sprintf("Invalid tool arguments: %v", err)
+		}
+		return app.runToolCommand(ctx, exec, args.Command, args.Reasoning)
+	}
+
+	if name == api.WriteFileTool.Function.Name {
+		if exec == nil {
+			return app.runWriteFile(toolCall, false)
+		}
+		defer exec.ClearCache() // Cached reads may show the file before the write
+		return app.runWriteFile(toolCall, exec.GetPermissionManager().DryRun())
+	}
+
+	if name == api.WebSearchTool.Function.Name {
+		var args struct {
+			Query string `json:"query"`
+		}
+		if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args); err != nil {
+			display.ShowError(fmt.Sprintf("Failed to parse tool arguments: %v", err))
+			return fmt.Sprintf("Invalid tool arguments: %v", err)
+		}
+		return app.runWebSearchTool(args.Query)
+	}
+
+	if tool, ok := api.FindCustomTool(name); ok {
+		command, err := tool.BuildCommand(toolCall.Function.Arguments)
+		if err != nil {
+			display.ShowError(fmt.Sprintf("Failed to build command for tool %s: %v", name, err))
+			return fmt.Sprintf("Invalid tool arguments: %v", err)
+		}
+		return app.runToolCommand(ctx, exec, command, fmt.Sprintf("%s (tool: %s)", tool.Description, name))
+	}
+
+	return fmt.Sprintf("Unknown tool: %s", name)
+}
+
+// runToolCommand checks permissions, asks for confirmation if needed, and runs a shell command
+func (app *App) runToolCommand(ctx context.Context, exec *shellExecutor, command, reasoning string) string {
+	allowed, needsConfirm, reason := exec.GetPermissionManager().CheckPermission(command)
+
+	if !allowed && !needsConfirm {
+		display.ShowCommandBlocked(command, reason)
+		return fmt.Sprintf("Command blocked: %s", reason)
+	}
+	if reason == dryRunReason {
+		display.ShowCommandDryRun(command, reasoning)
+		return DryRunToolMessage
+	}
+
+	// Ask for confirmation if needed
+	if needsConfirm {
+		allow, always := display.AskCommandConfirmation(command, explainCommand(command), reasoning)
+		if !allow {
+			return "Command execution denied by user"
+		}
+		if always {
+			exec.GetPermissionManager().AddToAllowlist(command)
+		}
+	}
+
+	// Execute the command (repeated read-only commands reuse this turn's result)
+	if exec.IsCached(command) {
+		display.ShowCommandCached(command)
+	} else {
+		display.ShowCommandExecuting(command)
+	}
+	done := app.timings.track("tools")
+	result, err := runShellCommand(ctx, exec, command)
+	done()
+
+	if err != nil || !result.IsSuccess() {
+		display.ShowCommandError(command, result.Error)
+		return app.summarizeToolOutput(ctx, command, result.FormatResult())
+	}
+
+	display.ShowCommandOutput(result.Output)
+	if result.Output == "" {
+		return "Command executed successfully (no output)"
+	}
+	return app.summarizeToolOutput(ctx, command, result.Output)
+}
+
+// summarizeToolOutput replaces command output larger than --summarize-tool-output bytes
+// with a summary written by --summary-model (default: the main model) before it goes back
+// to the model. The user has already seen the full output. At most MaxSummaryInputBytes
+// are sent to be summarized. Errors send the output unchanged.
+func (app *App) summarizeToolOutput(ctx context.Context, command, output string) string {
+	limit := app.cfg.SummarizeToolOutput
+	if limit <= 0 || len(output) <= limit {
+		return output
+	}
+
+	messages := []api.Message{
+		{Role: "system", Content: ToolOutputSummaryPrompt},
+		{Role: "user", Content: fmt.Sprintf("Command: %s\n\nOutput:\n%s", command, truncateMiddle(output, MaxSummaryInputBytes))},
+	}
+	sp := display.NewSpinner("Summarizing command output...")
+	sp.Start()
+	var resp *api.ChatResponse
+	var err error
+	if app.cfg.SummaryModel != "" {
+		resp, err = app.getAzureClient().QueryModelContext(ctx, app.cfg.SummaryModel, messages)
+	} else {
+		resp, err = app.getAzureClient().QueryWithHistoryContext(ctx, messages)
+	}
+	sp.Stop()
+	if err != nil {
+		display.ShowError(fmt.Sprintf("Command output summarization failed: %v", err))
+		return output
+	}
+	summary := strings.TrimSpace(resp.GetContent())
+	if summary == "" {
+		return output
+	}
+
+	display.ShowToolOutputSummarized(len(output), len(summary))
+	return fmt.Sprintf(ToolOutputSummaryTemplate, len(output), summary)
+}
+
+// truncateMiddle cuts s to about max bytes by dropping its middle, keeping whole lines
+// at both ends and noting how much was left out
+func truncateMiddle(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	head := s[:max/2]
+	if i := strings.LastIndexByte(head, '\n'); i > 0 {
+		head = head[:i+1]
+	}
+	start := len(s) - max/2
+	if i := strings.IndexByte(s[start:], '\n'); s[start-1] != '\n' && i >= 0 && start+i+1 < len(s) {
+		start += i + 1
+	}
+	tail := s[start:]
+	return fmt.Sprintf("%s[... %d bytes omitted ...]\n%s", head, len(s)-len(head)-len(tail), tail)
+}
+
+// errPlanRejected is returned when the user declines a --plan plan
+var errPlanRejected = errors.New("plan not approved; nothing was run")
+
+// approvePlan asks the model for a plan without tools, shows it and asks the user to approve it.
+// On approval the plan and the approval are appended to history and their indices returned.
+func (app *App) approvePlan(ctx context.Context, client *api.AzureClient, messages *[]api.Message, sp *display.Spinner) ([]int, error) {
+	if sp == nil {
+		sp = display.NewSpinner("Planning...")
+		sp.Start()
+	} else {
+		sp.UpdateMessage("Planning...")
+	}
+
+	planMessages := append(append([]api.Message(nil), withPromptMacros(*messages)...), api.Message{Role: "system", Content: PlanPrompt})
+	done := app.timings.track("plan")
+	resp, err := client.QueryWithHistoryAndToolsContext(ctx, planMessages, nil)
+	done()
+	sp.Stop()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := resp.GetContent()
+	if app.cfg.Render {
+		display.ShowContentRendered(plan)
+	} else {
+		display.ShowContent(plan)
+	}
+	if !display.AskPlanApproval() {
+		return nil, errPlanRejected
+	}
+	app.planApproved = true
+
+	start := len(*messages)
+	*messages = append(*messages,
+		api.Message{Role: "assistant", Content: plan},
+		api.Message{Role: "user", Content: PlanApprovedMessage},
+	)
+	return []int{start, start + 1}, nil
+}
+
+// runWebSearchTool performs a web search requested by the model, bounded by --max-searches per turn.
+// Results found earlier in the turn, including the user's web search, are kept: new results are
+// numbered after them and added to the sources, so citations of either still resolve.
+func (app *App) runWebSearchTool(query string) string {
+	if app.turnSearches >= app.cfg.MaxSearches {
+		return fmt.Sprintf("Search limit reached (%d searches this turn). Do not search again; answer using the information you already have.", app.cfg.MaxSearches)
+	}
+	earlier, earlierQuery := app.searchResults, app.searchQuery
+	if !app.webTurn && app.turnSearches == 0 {
+		earlier = nil // Left over from an earlier turn
+	}
+	app.turnSearches++
+
+	display.ShowWebSearching(query)
+	searchContext, err := app.performWebSearch(query)
+	if err != nil {
+		display.ShowError(err.Error())
+		return fmt.Sprintf("Web search failed: %v", err)
+	}
+	if earlier != nil {
+		found := app.searchResults
+		searchContext = found.FormatResultsAsContextFrom(len(earlier.Results) + 1)
+		merged := *earlier
+		merged.Results = append(append([]api.TavilyResult(nil), earlier.Results...), found.Results...)
+		app.searchResults, app.searchQuery = &merged, earlierQuery
+	}
+	if searchContext == "" {
+		return "No results found."
+	}
+	return searchContext
+}
+
+// streamWithTools streams a tool-enabled request, printing content live unless it must be buffered.
+// With --show-tool-calls the spinner shows each tool call as its arguments arrive.
+// Returns the assembled response and whether its content was already printed.
+func (app *App) streamWithTools(ctx context.Context, client *api.AzureClient, messages []api.Message, tools []api.Tool, sp *display.Spinner) (*api.ChatResponse, bool, error) {
+	buffered := app.cfg.Render || app.hasLengthBudget() || app.cfg.Bare
+	printed := false
+
+	if app.cfg.ShowToolCalls {
+		client.SetToolCallProgressCallback(func(call api.ToolCall) {
+			sp.UpdateMessage(fmt.Sprintf("Calling %s: %s", call.Function.Name, toolCallPreview(call.Function.Arguments)))
+		})
+		defer client.SetToolCallProgressCallback(nil)
+	}
+
+	start := time.Now()
+	resp, err := client.QueryStreamWithToolsContext(ctx, messages, tools, func(content string) {
+		app.timings.firstTokenAfter(start)
+		if buffered {
+			sp.UpdateMessage("Receiving...")
+			return
+		}
+		if !printed {
+			printed = true
+			sp.Stop()
+		}
+		fmt.Print(content)
+	})
+	if printed {
+		fmt.Println()
+	}
+	return resp, printed, err
+}
+
+// showToolTurnContent shows the text a response sent along with its tool calls, before
+// they run. Streaming prints it live; buffered (--render) and non-streamed text is shown
+// here, rendered on its own with --render, so every segment between tool calls appears
+// in order while command output stays plain. --bare shows only the final answer.
+func (app *App) showToolTurnContent(content string, printed bool) {
+	if printed || app.cfg.Bare || strings.TrimSpace(content) == "" {
+		return
+	}
+	if app.cfg.Render {
+		display.ShowContentRendered(content)
+	} else {
+		display.ShowContent(content)
+	}
+}
+
+// maxToolCallPreview is the longest argument preview shown while a tool call streams in
+const maxToolCallPreview = 60
+
+// toolCallPreview condenses partial tool arguments to a single short line
+func toolCallPreview(arguments string) string {
+	preview := strings.Join(strings.Fields(arguments), " ")
+	if runes := []rune(preview); len(runes) > maxToolCallPreview {
+		preview = "…" + string(runes[len(runes)-maxToolCallPreview:])
+	}
+	return preview + "…"
+}
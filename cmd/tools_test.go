@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+)
+
+func TestSummarizeToolOutput(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var req api.ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if len(req.Messages) != 2 || !strings.Contains(req.Messages[1].Content, "go test ./...") {
+			t.Errorf("summary request missing the command: %+v", req.Messages)
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"FAIL: TestFoo (foo_test.go:12)"}}]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{AzureEndpoint: server.URL, AzureAPIKey: "test-key", Model: "test", SummarizeToolOutput: 100}
+	app := &App{cfg: cfg, azureClient: api.NewAzureClient(cfg)}
+	ctx := context.Background()
+
+	small := "ok  \tpkg\t0.01s"
+	if got := app.summarizeToolOutput(ctx, "go test ./...", small); got != small {
+		t.Errorf("small output = %q, want it unchanged", got)
+	}
+	if requests != 0 {
+		t.Fatalf("small output made %d requests, want 0", requests)
+	}
+
+	large := strings.Repeat("=== RUN TestFoo\n", 20)
+	got := app.summarizeToolOutput(ctx, "go test ./...", large)
+	if requests != 1 {
+		t.Fatalf("large output made %d requests, want 1", requests)
+	}
+	if !strings.Contains(got, "FAIL: TestFoo (foo_test.go:12)") || strings.Contains(got, "=== RUN") {
+		t.Errorf("large output = %q, want the summary instead", got)
+	}
+
+	cfg.SummarizeToolOutput = 0
+	if got := app.summarizeToolOutput(ctx, "go test ./...", large); got != large {
+		t.Error("summarization should be off when the threshold is 0")
+	}
+}
+
+func TestSummarizeToolOutputRequest(t *testing.T) {
+	var req api.ChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"summary"}}]}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		summaryModel string
+		outputBytes  int
+		wantModel    string
+	}{
+		{"main model by default", "", 1000, "main"},
+		{"summary model", "mini", 1000, "mini"},
+		{"oversized output is cut", "", 10 * MaxSummaryInputBytes, "main"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{AzureEndpoint: server.URL, AzureAPIKey: "test-key", Model: "main",
+				SummarizeToolOutput: 100, SummaryModel: tt.summaryModel}
+			app := &App{cfg: cfg, azureClient: api.NewAzureClient(cfg)}
+			output := strings.Repeat("line of output\n", tt.outputBytes/15)
+			if got := app.summarizeToolOutput(context.Background(), "make", output); !strings.Contains(got, "summary") {
+				t.Fatalf("summarizeToolOutput() = %q", got)
+			}
+			if req.Model != tt.wantModel {
+				t.Errorf("model = %q, want %q", req.Model, tt.wantModel)
+			}
+			if sent := len(req.Messages[1].Content); sent > MaxSummaryInputBytes+100 {
+				t.Errorf("sent %d bytes of output, want at most ~%d", sent, MaxSummaryInputBytes)
+			}
+		})
+	}
+}
+
+func TestTruncateMiddle(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		max  int
+		want string
+	}{
+		{"fits", "a\nb\n", 10, "a\nb\n"},
+		{"keeps whole lines at both ends", "head1\nhead2\nmiddle\nmiddle\ntail1\ntail2\n", 24,
+			"head1\nhead2\n[... 14 bytes omitted ...]\ntail1\ntail2\n"},
+		{"cuts inside lines", "aaaa\nbbbbbbbbbb\ncccc\n", 12, "aaaa\n[... 11 bytes omitted ...]\ncccc\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateMiddle(tt.in, tt.max); got != tt.want {
+				t.Errorf("truncateMiddle(%q, %d) = %q, want %q", tt.in, tt.max, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSafeModeToolCall(t *testing.T) {
+	app := &App{cfg: &config.Config{SafeMode: true}}
+	call := api.ToolCall{}
+	call.Function.Name = api.ExecuteCommandTool.Function.Name
+	call.Function.Arguments = `{"command":"touch should-not-exist","reasoning":"test"}`
+
+	// A nil executor would panic if the command were run
+	if got := app.handleToolCall(context.Background(), nil, call); got != SafeModeToolMessage {
+		t.Errorf("handleToolCall() = %q, want %q", got, SafeModeToolMessage)
+	}
+}
+
+// captureStdout returns what fn prints on stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	return captureFile(t, &os.Stdout, fn)
+}
+
+// captureStderr returns what fn writes on stderr
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	return captureFile(t, &os.Stderr, fn)
+}
+
+// captureFile returns what fn writes to *f, which is replaced by a pipe meanwhile
+func captureFile(t *testing.T, f **os.File, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := *f
+	*f = w
+	defer func() { *f = saved }()
+
+	out := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		out <- string(data)
+	}()
+	fn()
+	_ = w.Close()
+	return <-out
+}
+
+func TestToolListings(t *testing.T) {
+	t.Cleanup(func() { api.EnableCommandTools(true) })
+	app := &App{cfg: &config.Config{SafeMode: true, MaxSearches: 0}}
+	app.registerTools()
+
+	want := map[string]string{
+		api.ExecuteCommandTool.Function.Name: "safe mode",
+		api.WriteFileTool.Function.Name:      "safe mode",
+		api.WebSearchTool.Function.Name:      "--max-searches 0",
+	}
+	listings := app.toolListings()
+	if len(listings) != len(want) {
+		t.Fatalf("toolListings() = %+v, want the %d built-in tools", listings, len(want))
+	}
+	for _, l := range listings {
+		if l.Enabled || l.Disabled != want[l.Name] {
+			t.Errorf("%s: enabled = %v, disabled = %q, want disabled by %q", l.Name, l.Enabled, l.Disabled, want[l.Name])
+		}
+		if l.Parameters == "" {
+			t.Errorf("%s has no parameter summary", l.Name)
+		}
+	}
+}
+
+// stubSearch returns one result per search, titled after the query
+type stubSearch struct{ searches int }
+
+func (s *stubSearch) Search(_ context.Context, query string) (*api.SearchResponse, error) {
+	s.searches++
+	return &api.SearchResponse{Results: []api.SearchResult{{Title: query, URL: "https://example.com/" + query}}}, nil
+}
+
+func (s *stubSearch) SetKeyRotationCallback(func(fromIndex, toIndex, totalKeys int)) {}
+
+func TestRunWebSearchTool(t *testing.T) {
+	userSearch := &api.TavilyResponse{Results: []api.TavilyResult{{Title: "user1"}, {Title: "user2"}}}
+
+	tests := []struct {
+		name         string
+		webTurn      bool
+		maxSearches  int
+		queries      []string
+		wantSearches int
+		wantTitles   []string
+		wantQuery    string
+		wantLast     string // substring of the last tool result
+	}{
+		{"web turn keeps the user's sources", true, 3, []string{"tool"}, 1,
+			[]string{"user1", "user2", "tool"}, "user query", "[3] tool"},
+		{"later tool searches add up", false, 3, []string{"a", "b"}, 2,
+			[]string{"a", "b"}, "a", "[2] b"},
+		{"stops at --max-searches", false, 2, []string{"a", "b", "c"}, 2,
+			[]string{"a", "b"}, "a", "Search limit reached (2 searches this turn)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			search := &stubSearch{}
+			app := &App{
+				cfg:           &config.Config{WebSearchProvider: "tavily", MaxSearches: tt.maxSearches},
+				searchClients: map[string]api.SearchClient{"tavily": search},
+				searchResults: userSearch,
+				searchQuery:   "user query",
+				webTurn:       tt.webTurn,
+			}
+			var last string
+			for _, q := range tt.queries {
+				last = app.runWebSearchTool(q)
+			}
+
+			if search.searches != tt.wantSearches {
+				t.Errorf("searches = %d, want %d", search.searches, tt.wantSearches)
+			}
+			var titles []string
+			for _, r := range app.searchResults.Results {
+				titles = append(titles, r.Title)
+			}
+			if strings.Join(titles, ",") != strings.Join(tt.wantTitles, ",") || app.searchQuery != tt.wantQuery {
+				t.Errorf("sources = %v for %q, want %v for %q", titles, app.searchQuery, tt.wantTitles, tt.wantQuery)
+			}
+			if !strings.Contains(last, tt.wantLast) {
+				t.Errorf("last tool result = %q, want it to contain %q", last, tt.wantLast)
+			}
+		})
+	}
+	if len(userSearch.Results) != 2 {
+		t.Error("runWebSearchTool modified the user's search results")
+	}
+}
+
+func TestApprovePlan(t *testing.T) {
+	var planRequest api.ChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&planRequest)
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"1. Run ls"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		answer       string
+		wantErr      error
+		wantMessages []string
+	}{
+		{"approved", "y", nil, []string{"list files", "1. Run ls", PlanApprovedMessage}},
+		{"rejected", "n", errPlanRejected, []string{"list files"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, w, err := os.Pipe()
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _ = w.WriteString(tt.answer)
+			_ = w.Close()
+			stdin := os.Stdin
+			os.Stdin = r
+			defer func() { os.Stdin = stdin }()
+
+			cfg := &config.Config{AzureEndpoint: server.URL, AzureAPIKey: "test-key", Model: "gpt-4o", Plan: true}
+			app := &App{cfg: cfg}
+			messages := []api.Message{{Role: "user", Content: "list files"}}
+			var added []int
+			captureStdout(t, func() {
+				added, err = app.approvePlan(context.Background(), api.NewAzureClient(cfg), &messages, nil)
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("approvePlan() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if len(planRequest.Tools) != 0 {
+				t.Errorf("plan request offered %d tools, want none", len(planRequest.Tools))
+			}
+			if last := planRequest.Messages[len(planRequest.Messages)-1]; last.Role != "system" || last.Content != PlanPrompt {
+				t.Errorf("plan request ends with %+v, want the plan prompt", last)
+			}
+			var contents []string
+			for _, m := range messages {
+				contents = append(contents, m.Content)
+			}
+			if !reflect.DeepEqual(contents, tt.wantMessages) {
+				t.Errorf("history = %q, want %q", contents, tt.wantMessages)
+			}
+			if len(added) != len(tt.wantMessages)-1 || app.planApproved != (tt.wantErr == nil) {
+				t.Errorf("added = %v, planApproved = %v", added, app.planApproved)
+			}
+		})
+	}
+}
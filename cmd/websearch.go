@@ -2,17 +2,18 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
-	"os"
+	"regexp"
 	"strings"
 
 	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
 	"github.com/quocvuong92/azure-ai-cli/internal/display"
-	"github.com/quocvuong92/azure-ai-cli/internal/executor"
 )
 
-func (app *App) optimizeSearchQuery(query string, messages []api.Message, client *api.AzureClient) (string, error) {
+func (app *App) optimizeSearchQuery(query string, messages []api.Message, client *api.AzureClient, sp *display.Spinner) (string, error) {
 	// Build messages for query optimization
 	// Include conversation history so LLM understands context
 	optimizeMessages := []api.Message{
@@ -31,14 +32,10 @@ func (app *App) optimizeSearchQuery(query string, messages []api.Message, client
 	for i := startIdx; i < len(messages); i++ {
 		msg := messages[i]
 		// Truncate long assistant responses to save tokens
-		if msg.Role == "assistant" && len(msg.Content) > MaxMessageLengthForOptimization {
-			optimizeMessages = append(optimizeMessages, api.Message{
-				Role:    msg.Role,
-				Content: msg.Content[:MaxMessageLengthForOptimization] + "...",
-			})
-		} else {
-			optimizeMessages = append(optimizeMessages, msg)
+		if msg.Role == "assistant" {
+			msg.Content = truncateWithEllipsis(msg.Content, MaxMessageLengthForOptimization)
 		}
+		optimizeMessages = append(optimizeMessages, msg)
 	}
 
 	// Add the current query as the final user message
@@ -47,12 +44,11 @@ func (app *App) optimizeSearchQuery(query string, messages []api.Message, client
 		Content: fmt.Sprintf("Generate a search query for: %s", query),
 	})
 
-	sp := display.NewSpinner("Optimizing query...")
-	sp.Start()
+	sp.UpdateMessage("Optimizing query...")
 
+	done := app.timings.track("optimize")
 	resp, err := client.QueryWithHistory(optimizeMessages)
-	sp.Stop()
-
+	done()
 	if err != nil {
 		return "", err
 	}
@@ -61,132 +57,429 @@ func (app *App) optimizeSearchQuery(query string, messages []api.Message, client
 	// Remove quotes if the LLM wrapped the query in them
 	optimizedQuery = strings.Trim(optimizedQuery, "\"'`")
 
+	if reason := invalidQueryReason(optimizedQuery); reason != "" {
+		log.Printf("Optimized query rejected (%s): %q, using original query", reason, optimizedQuery)
+		return query, nil
+	}
+
 	return optimizedQuery, nil
 }
 
-func (app *App) handleWebSearch(query string, messages *[]api.Message, client *api.AzureClient, exec *executor.Executor) {
-	// Optimize search query using LLM if there's conversation context
+// shouldOptimizeQuery reports whether a search is rewritten by the model for
+// the given --optimize mode; hasHistory is false for the first message of a session
+func shouldOptimizeQuery(mode string, hasHistory bool) bool {
+	switch mode {
+	case config.OptimizeAlways:
+		return true
+	case config.OptimizeNever:
+		return false
+	case config.OptimizeFirst:
+		return !hasHistory
+	default:
+		return hasHistory
+	}
+}
+
+// invalidQueryReason returns why optimizer output is not usable as a search query,
+// or "" if it looks like a query
+func invalidQueryReason(q string) string {
+	if q == "" {
+		return "empty"
+	}
+	if strings.Contains(q, "\n") {
+		return "multiple lines"
+	}
+	if len(strings.Fields(q)) > MaxOptimizedQueryWords {
+		return "too long"
+	}
+	lower := strings.ToLower(q)
+	for _, prefix := range junkQueryPrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return "not a query"
+		}
+	}
+	return ""
+}
+
+// needsWebSearch asks the model whether a follow-up needs fresh search results (--smart-web).
+// The first message, explicit provider overrides, and classification failures always search.
+func (app *App) needsWebSearch(query string, messages []api.Message, client *api.AzureClient) bool {
+	if len(messages) <= 1 || strings.HasPrefix(query, "@") {
+		return true
+	}
+
+	classifyMessages := []api.Message{{Role: "system", Content: SearchNeededPrompt}}
+	startIdx := 1 // Skip system message
+	if len(messages) > MaxHistoryMessagesForOptimization+1 {
+		startIdx = len(messages) - MaxHistoryMessagesForOptimization
+	}
+	for _, msg := range messages[startIdx:] {
+		if msg.Role != "user" && msg.Role != "assistant" {
+			continue
+		}
+		classifyMessages = append(classifyMessages, api.Message{Role: msg.Role, Content: truncateWithEllipsis(msg.Content, MaxMessageLengthForOptimization)})
+	}
+	classifyMessages = append(classifyMessages, api.Message{
+		Role:    "user",
+		Content: fmt.Sprintf("Does this follow-up need a new web search? %s", query),
+	})
+
+	sp := display.NewSpinner("Checking if a search is needed...")
+	sp.Start()
+	done := app.timings.track("classify")
+	resp, err := client.QueryWithHistory(classifyMessages)
+	done()
+	sp.Stop()
+	if err != nil {
+		log.Printf("Search classification failed: %v, searching anyway", err)
+		return true
+	}
+
+	answer := strings.ToUpper(strings.TrimSpace(resp.GetContent()))
+	if strings.HasPrefix(answer, "NO") {
+		display.ShowSearchSkipped()
+		return false
+	}
+	return true
+}
+
+// searchIntent is the model's reply to SearchIntentPrompt
+type searchIntent struct {
+	NeedSearch  *bool  `json:"need_search"`
+	SearchQuery string `json:"search_query"`
+}
+
+// parseSearchIntent reads the --search-intent decision from the model's reply, allowing
+// code fences or text around the JSON object. A missing need_search means search.
+func parseSearchIntent(reply string) (bool, string, error) {
+	start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return false, "", fmt.Errorf("no JSON object in %q", reply)
+	}
+	var intent searchIntent
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &intent); err != nil {
+		return false, "", err
+	}
+	need := intent.NeedSearch == nil || *intent.NeedSearch
+	return need, strings.TrimSpace(intent.SearchQuery), nil
+}
+
+// searchIntent asks the model whether query needs a web search and what to search for
+// (--search-intent), in place of the separate --smart-web and --optimize calls. An empty
+// search query means the query is searched as typed; provider overrides and failures
+// always search.
+func (app *App) searchIntent(query string, messages []api.Message, client *api.AzureClient) (bool, string) {
+	if strings.HasPrefix(query, "@") {
+		return true, ""
+	}
+
+	intentMessages := []api.Message{{Role: "system", Content: SearchIntentPrompt}}
+	startIdx := 1 // Skip system message
+	if len(messages) > MaxHistoryMessagesForOptimization+1 {
+		startIdx = len(messages) - MaxHistoryMessagesForOptimization
+	}
+	for i := startIdx; i < len(messages); i++ {
+		msg := messages[i]
+		if msg.Role != "user" && msg.Role != "assistant" {
+			continue
+		}
+		intentMessages = append(intentMessages, api.Message{Role: msg.Role, Content: truncateWithEllipsis(msg.Content, MaxMessageLengthForOptimization)})
+	}
+	intentMessages = append(intentMessages, api.Message{Role: "user", Content: query})
+
+	sp := display.NewSpinner("Deciding whether to search...")
+	sp.Start()
+	done := app.timings.track("intent")
+	resp, err := client.QueryWithHistory(intentMessages)
+	done()
+	sp.Stop()
+	if err != nil {
+		log.Printf("Search intent failed: %v, searching with the original query", err)
+		return true, ""
+	}
+
+	need, searchQuery, err := parseSearchIntent(resp.GetContent())
+	if err != nil {
+		log.Printf("Search intent unreadable (%v), searching with the original query", err)
+		return true, ""
+	}
+	if !need {
+		display.ShowSearchSkipped()
+		return false, ""
+	}
+	if reason := invalidQueryReason(searchQuery); reason != "" {
+		log.Printf("Search intent query rejected (%s): %q, using original query", reason, searchQuery)
+		return true, ""
+	}
+	return true, searchQuery
+}
+
+// parseProviderOverride extracts a leading "@provider" token from a query.
+// Returns an empty provider when the query has no override.
+func parseProviderOverride(query string) (string, string, error) {
+	if !strings.HasPrefix(query, "@") {
+		return "", query, nil
+	}
+
+	parts := strings.SplitN(query, " ", 2)
+	provider := strings.ToLower(strings.TrimPrefix(parts[0], "@"))
+	if !config.IsValidSearchProvider(provider) {
+		return "", "", fmt.Errorf("invalid provider: %s (available: %s)", provider, strings.Join(config.SearchProviders, ", "))
+	}
+	if len(parts) < 2 || strings.TrimSpace(parts[1]) == "" {
+		return "", "", fmt.Errorf("missing query after @%s", provider)
+	}
+	return provider, strings.TrimSpace(parts[1]), nil
+}
+
+// handleWebSearch searches the web for query and answers with the results. searchQuery,
+// when set (--search-intent), is searched instead of optimizing the query.
+func (app *App) handleWebSearch(query, searchQuery string, messages *[]api.Message, client *api.AzureClient, exec *shellExecutor) {
+	// Allow a one-off provider override, e.g. "@brave latest go release"
+	provider, query, err := parseProviderOverride(query)
+	if err != nil {
+		display.ShowError(err.Error())
+		return
+	}
+	if provider == "" {
+		provider = app.cfg.WebSearchProvider
+	} else if !app.cfg.HasSearchKeys(provider) {
+		display.ShowError(fmt.Sprintf("no API keys configured for provider: %s", provider))
+		return
+	}
+
+	// One spinner is carried through optimize, search and answer stages to avoid flicker
+	fmt.Println()
+	sp := display.NewSpinner("Searching web...")
+	sp.Start()
+	defer sp.Stop()
+
+	// Optimize search query using LLM, by default only when there's conversation context
 	optimizedQuery := query
-	if len(*messages) > 1 { // More than just system message
-		var err error
-		optimizedQuery, err = app.optimizeSearchQuery(query, *messages, client)
+	if searchQuery != "" {
+		optimizedQuery = searchQuery
+	} else if !app.cfg.SearchIntent && shouldOptimizeQuery(app.cfg.Optimize, len(*messages) > 1) { // More than just system message
+		optimizedQuery, err = app.optimizeSearchQuery(query, *messages, client, sp)
 		if err != nil {
 			// Fall back to original query if optimization fails
 			log.Printf("Query optimization failed: %v, using original query", err)
 			optimizedQuery = query
-		} else if optimizedQuery != query {
-			// Show the optimized query so user knows what was searched
-			fmt.Fprintf(os.Stderr, "Searching for: %s\n", optimizedQuery)
 		}
 	}
 
 	// Perform web search with optimized query
-	searchContext, err := app.performWebSearch(optimizedQuery)
+	searchContext, err := app.performWebSearchWithProvider(optimizedQuery, provider, sp)
+	if err != nil {
+		sp.Stop()
+		display.ShowError(err.Error())
+		return
+	}
+
+	app.webTurn = true
+	response, err := app.answerWithWebContext(query, searchContext, messages, client, exec, sp)
+	app.webTurn = false
 	if err != nil {
 		display.ShowError(err.Error())
 		return
 	}
 
-	// Add web search results as a system context message, then add user query
-	// This preserves conversation flow while providing web context
+	// Show citations if enabled
+	if app.cfg.Citations {
+		app.showCitations(response)
+	}
+	fmt.Println()
+}
+
+// answerWithWebContext answers query with the search results injected as a system message
+// for this turn only. On success history keeps the query, any tool exchange and the answer,
+// but not the web context; on error the turn is removed entirely.
+func (app *App) answerWithWebContext(query, searchContext string, messages *[]api.Message, client *api.AzureClient, exec *shellExecutor, sp *display.Spinner) (string, error) {
 	webContextMsg := api.Message{
 		Role:    "system",
 		Content: fmt.Sprintf(WebContextMessageTemplate, searchContext),
 	}
+	*messages = append(*messages, webContextMsg, api.Message{Role: "user", Content: query})
 
-	// Add web context to messages temporarily
-	*messages = append(*messages, webContextMsg)
-	*messages = append(*messages, api.Message{Role: "user", Content: query})
-
-	// Send request with tools support
-	fmt.Println()
-	response, err := app.sendInteractiveMessageWithTools(client, exec, messages)
+	// Tool calls append a variable number of messages after the query, so the
+	// context message is found by identity rather than by offset from the end
+	response, added, err := app.sendInteractiveMessageWithTools(client, exec, messages, sp)
 	if err != nil {
-		display.ShowError(err.Error())
-		// Remove the messages we added on error
-		*messages = (*messages)[:len(*messages)-2]
-		return
+		*messages = removeMessages(*messages, added)
+		*messages = removeWebContext(*messages, webContextMsg)
+		*messages = (*messages)[:len(*messages)-1] // the query
+		return "", err
 	}
-
-	// Remove web context from history, keep only user query and response
-	// Remove web context message (second to last before we added response)
-	*messages = append((*messages)[:len(*messages)-3], (*messages)[len(*messages)-2:]...)
-
+	*messages = removeWebContext(*messages, webContextMsg)
 	if response != "" {
-		// Response is already added by sendInteractiveMessageWithTools
-	} else {
-		// If no response, add it manually
 		*messages = append(*messages, api.Message{Role: "assistant", Content: response})
 	}
+	return response, nil
+}
 
-	// Show citations if enabled
-	if app.cfg.Citations && app.searchResults != nil && len(app.searchResults.Results) > 0 {
-		fmt.Println()
-		citations := make([]display.Citation, len(app.searchResults.Results))
-		for i, r := range app.searchResults.Results {
-			citations[i] = display.Citation{Title: r.Title, URL: r.URL}
+// removeWebContext removes the most recent system message identical to ctx
+func removeWebContext(messages []api.Message, ctx api.Message) []api.Message {
+	for i := len(messages) - 1; i > 0; i-- {
+		if messages[i].Role == ctx.Role && messages[i].Content == ctx.Content {
+			return append(messages[:i], messages[i+1:]...)
 		}
-		display.ShowCitations(citations)
 	}
-	fmt.Println()
+	return messages
+}
+
+// removeMessages returns messages without the entries at the given indices
+func removeMessages(messages []api.Message, indices []int) []api.Message {
+	if len(indices) == 0 {
+		return messages
+	}
+	drop := make(map[int]bool, len(indices))
+	for _, i := range indices {
+		drop[i] = true
+	}
+	kept := messages[:0]
+	for i, msg := range messages {
+		if !drop[i] {
+			kept = append(kept, msg)
+		}
+	}
+	return kept
 }
 
 func (app *App) performWebSearch(query string) (string, error) {
-	sp := display.NewSpinner("Searching web...")
-	sp.Start()
+	return app.performWebSearchWithProvider(query, app.cfg.WebSearchProvider, nil)
+}
 
-	ctx := context.Background()
-	var results *api.TavilyResponse
+// performWebSearchWithProvider searches using the given provider without changing the configured default.
+// If sp is nil a spinner is created for the search; otherwise sp is updated and left running.
+func (app *App) performWebSearchWithProvider(query, provider string, sp *display.Spinner) (string, error) {
+	if sp == nil {
+		sp = display.NewSpinner("Searching web...")
+		sp.Start()
+		defer sp.Stop()
+	} else if query != "" {
+		sp.UpdateMessage(fmt.Sprintf("Searching web for: %s", query))
+	}
 
-	switch app.cfg.WebSearchProvider {
-	case "linkup":
-		linkupClient := api.NewLinkupClient(app.cfg)
-		linkupClient.SetKeyRotationCallback(func(from, to, total int) {
-			display.ShowKeyRotation("Linkup", from, to, total)
-		})
+	defer app.timings.track("search")()
 
-		searchResp, searchErr := linkupClient.Search(ctx, query)
-		if searchErr != nil {
-			sp.Stop()
-			return "", searchErr
-		}
-		results = searchResp.ToTavilyResponse()
-
-	case "brave":
-		braveClient := api.NewBraveClient(app.cfg)
-		braveClient.SetKeyRotationCallback(func(from, to, total int) {
-			display.ShowKeyRotation("Brave", from, to, total)
-		})
-
-		searchResp, searchErr := braveClient.Search(ctx, query)
-		if searchErr != nil {
-			sp.Stop()
-			return "", searchErr
-		}
-		results = searchResp.ToTavilyResponse()
-
-	default: // tavily
-		tavilyClient := api.NewTavilyClient(app.cfg)
-		tavilyClient.SetKeyRotationCallback(func(from, to, total int) {
-			display.ShowKeyRotation("Tavily", from, to, total)
-		})
-
-		searchResp, searchErr := tavilyClient.Search(ctx, query)
-		if searchErr != nil {
-			sp.Stop()
-			return "", searchErr
+	client, err := app.searchClient(provider)
+	if err != nil {
+		return "", err
+	}
+	searchResp, err := client.Search(context.Background(), query)
+	if err != nil {
+		return "", err
+	}
+	results := searchResp.ToTavilyResponse()
+
+	if provider == config.SearchProviderAll {
+		// Only providers whose search succeeded used up a request
+		for _, p := range searchResp.Providers {
+			recordSearchQuota(app.cfg, p)
 		}
-		results = searchResp.ToTavilyResponse()
+	} else {
+		recordSearchQuota(app.cfg, provider)
 	}
 
-	sp.Stop()
+	if app.shouldPickSources(provider, results) {
+		sp.Stop()
+		results.Results = pickSources(results.Results, query)
+	}
 
-	// Store results for citations
+	// Store results and the query used for citations
 	app.searchResults = results
+	app.searchQuery = query
 
 	return results.FormatResultsAsContext(), nil
 }
 
+// searchClient returns the session's client for a provider, creating it on first use.
+// Reusing clients (and their shared transport) keeps connections alive across queries.
+func (app *App) searchClient(provider string) (api.SearchClient, error) {
+	if client, ok := app.searchClients[provider]; ok {
+		return client, nil
+	}
+	if provider == config.SearchProviderAll {
+		return app.multiSearchClient()
+	}
+	client, err := api.NewSearchClient(provider, app.cfg)
+	if err != nil {
+		return nil, err
+	}
+	name := providerDisplayName(provider)
+	client.SetKeyRotationCallback(func(from, to, total int) {
+		display.ShowKeyRotation(name, from, to, total)
+	})
+	if app.searchClients == nil {
+		app.searchClients = make(map[string]api.SearchClient)
+	}
+	app.searchClients[provider] = client
+	return client, nil
+}
+
+// multiSearchClient combines the clients of every provider --provider all searches.
+// It is rebuilt on each call, so keys added during a session are picked up; the
+// per-provider clients inside it are still reused.
+func (app *App) multiSearchClient() (api.SearchClient, error) {
+	providers := app.cfg.MultiSearchProviders()
+	if len(providers) == 0 {
+		return nil, config.ErrWebSearchKeyNotFound
+	}
+	clients := make([]api.SearchClient, len(providers))
+	for i, p := range providers {
+		client, err := app.searchClient(p)
+		if err != nil {
+			return nil, err
+		}
+		clients[i] = client
+	}
+	return api.NewMultiSearchClient(providers, clients), nil
+}
+
+// providerDisplayName capitalizes a provider name for messages, e.g. "brave" -> "Brave"
+func providerDisplayName(provider string) string {
+	if provider == "" {
+		return provider
+	}
+	return strings.ToUpper(provider[:1]) + provider[1:]
+}
+
+// citationMarkerPattern matches citation markers like [1] or [2, 3] in an answer
+var citationMarkerPattern = regexp.MustCompile(`\[\d+(?:\s*,\s*\d+)*\]`)
+
+// showCitations displays the sources from the last web search. Unless --always-cite is set,
+// sources are skipped when the answer contains no [n] markers (i.e. it didn't use them).
+func (app *App) showCitations(answer string) {
+	citations := app.citations(answer)
+	if len(citations) == 0 {
+		return
+	}
+
+	fmt.Println()
+	searchQuery := ""
+	if app.cfg.ShowSearchQuery {
+		searchQuery = app.searchQuery
+	}
+	display.ShowCitations(citations, searchQuery)
+}
+
+// citations returns the sources from the last web search to list under an answer,
+// or nil when there are none or, unless --always-cite is set, the answer cites none
+func (app *App) citations(answer string) []display.Citation {
+	if app.searchResults == nil || len(app.searchResults.Results) == 0 {
+		return nil
+	}
+	if !app.cfg.AlwaysCite && !citationMarkerPattern.MatchString(answer) {
+		log.Printf("Answer has no citation markers, skipping sources")
+		return nil
+	}
+
+	citations := make([]display.Citation, len(app.searchResults.Results))
+	for i, r := range app.searchResults.Results {
+		citations[i] = display.Citation{Title: r.Title, URL: r.URL}
+	}
+	return citations
+}
+
 func buildWebSearchPrompt(searchContext string) string {
 	return fmt.Sprintf(WebSearchPromptTemplate, searchContext)
 }
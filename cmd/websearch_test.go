@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+	"github.com/quocvuong92/azure-ai-cli/internal/quota"
+)
+
+func TestInvalidQueryReason(t *testing.T) {
+	tests := []struct {
+		query string
+		valid bool
+	}{
+		{"Kubernetes 1.33 release date", true},
+		{"React hooks performance optimization", true},
+		{"", false},
+		{"I cannot help with that request.", false},
+		{"Sorry, I need more context", false},
+		{"Here is a search query: go generics", false},
+		{"go generics\ngo 1.18 type parameters", false},
+		{"This is a long explanation of what the user probably wants to find, written as a full paragraph instead", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			reason := invalidQueryReason(tt.query)
+			if (reason == "") != tt.valid {
+				t.Errorf("invalidQueryReason(%q) = %q, want valid=%v", tt.query, reason, tt.valid)
+			}
+		})
+	}
+}
+
+func TestShouldOptimizeQuery(t *testing.T) {
+	tests := []struct {
+		mode      string
+		first     bool
+		followups bool
+	}{
+		{config.OptimizeFollowups, false, true},
+		{config.OptimizeFirst, true, false},
+		{config.OptimizeAlways, true, true},
+		{config.OptimizeNever, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			if got := shouldOptimizeQuery(tt.mode, false); got != tt.first {
+				t.Errorf("shouldOptimizeQuery(%q, first message) = %v, want %v", tt.mode, got, tt.first)
+			}
+			if got := shouldOptimizeQuery(tt.mode, true); got != tt.followups {
+				t.Errorf("shouldOptimizeQuery(%q, follow-up) = %v, want %v", tt.mode, got, tt.followups)
+			}
+		})
+	}
+}
+
+func TestAnswerWithWebContextHistoryShape(t *testing.T) {
+	toolTurn := `data: {"choices":[{"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"lookup","arguments":"{}"}},{"index":1,"id":"call_2","type":"function","function":{"name":"lookup","arguments":"{}"}}]},"finish_reason":"tool_calls"}]}
+
+data: [DONE]
+
+`
+	answerTurn := `data: {"choices":[{"delta":{"content":"Go 1.24 [1]"},"finish_reason":"stop"}]}
+
+data: [DONE]
+
+`
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var req struct {
+			Messages []api.Message `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		// Every request of the turn must still see the web context
+		seen := false
+		for _, m := range req.Messages {
+			if m.Role == "system" && strings.Contains(m.Content, "RESULTS") {
+				seen = true
+			}
+		}
+		if !seen {
+			t.Errorf("request %d missing web context", requests)
+		}
+		if requests == 1 {
+			_, _ = w.Write([]byte(toolTurn))
+			return
+		}
+		_, _ = w.Write([]byte(answerTurn))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{AzureEndpoint: server.URL, AzureAPIKey: "test-key", Model: "test", Stream: true}
+	app := &App{cfg: cfg}
+	messages := []api.Message{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: "earlier"},
+		{Role: "assistant", Content: "earlier answer"},
+	}
+
+	response, err := app.answerWithWebContext("latest go?", "RESULTS", &messages, api.NewAzureClient(cfg), newShellExecutor(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response != "Go 1.24 [1]" {
+		t.Errorf("response = %q, want %q", response, "Go 1.24 [1]")
+	}
+
+	want := []struct{ role, content, toolCallID string }{
+		{"system", "sys", ""},
+		{"user", "earlier", ""},
+		{"assistant", "earlier answer", ""},
+		{"user", "latest go?", ""},
+		{"assistant", "", ""},
+		{"tool", "Unknown tool: lookup", "call_1"},
+		{"tool", "Unknown tool: lookup", "call_2"},
+		{"assistant", "Go 1.24 [1]", ""},
+	}
+	if len(messages) != len(want) {
+		t.Fatalf("history has %d messages, want %d: %+v", len(messages), len(want), messages)
+	}
+	for i, w := range want {
+		m := messages[i]
+		if m.Role != w.role || m.Content != w.content || m.ToolCallID != w.toolCallID {
+			t.Errorf("message %d = {%s %q %s}, want {%s %q %s}", i, m.Role, m.Content, m.ToolCallID, w.role, w.content, w.toolCallID)
+		}
+	}
+	if len(messages[4].ToolCalls) != 2 {
+		t.Errorf("assistant tool-call message has %d calls, want 2", len(messages[4].ToolCalls))
+	}
+}
+
+func TestParseSearchIntent(t *testing.T) {
+	tests := []struct {
+		name      string
+		reply     string
+		wantNeed  bool
+		wantQuery string
+		wantErr   bool
+	}{
+		{"search", `{"need_search": true, "search_query": "Go 1.24 release date"}`, true, "Go 1.24 release date", false},
+		{"no search", `{"need_search": false, "search_query": ""}`, false, "", false},
+		{"code fence", "```json\n{\"need_search\": true, \"search_query\": \" k8s 1.33 \"}\n```", true, "k8s 1.33", false},
+		{"missing need_search", `{"search_query": "rust async"}`, true, "rust async", false},
+		{"not JSON", "YES", false, "", true},
+		{"broken JSON", `{"need_search": tru}`, false, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			need, query, err := parseSearchIntent(tt.reply)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSearchIntent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if need != tt.wantNeed || query != tt.wantQuery {
+				t.Errorf("parseSearchIntent() = %v, %q, want %v, %q", need, query, tt.wantNeed, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func TestOneShotSearch(t *testing.T) {
+	tests := []struct {
+		name      string
+		web       bool
+		countOnly bool
+		provider  string
+		want      bool
+	}{
+		{"no -w", false, false, "tavily", false},
+		{"searches as typed", true, false, "tavily", true},
+		{"count-only still searches", true, true, "tavily", true},
+		{"count-only skips answer provider", true, true, "perplexity", false},
+		{"answer provider answers", true, false, "perplexity", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// --search-intent is set but must not call the model (no client configured)
+			app := &App{cfg: &config.Config{WebSearch: tt.web, SearchIntent: true, CountOnly: tt.countOnly, WebSearchProvider: tt.provider}}
+			if got := app.oneShotSearch(); got != tt.want {
+				t.Errorf("oneShotSearch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCitations(t *testing.T) {
+	results := &api.TavilyResponse{Results: []api.TavilyResult{
+		{Title: "Go 1.24 Release Notes", URL: "https://go.dev/doc/go1.24"},
+		{Title: "Go blog", URL: "https://go.dev/blog"},
+	}}
+
+	tests := []struct {
+		name       string
+		results    *api.TavilyResponse
+		alwaysCite bool
+		answer     string
+		want       int
+	}{
+		{"cited", results, false, "Go 1.24 shipped in February [1].", 2},
+		{"cited as a list", results, false, "See [1, 2].", 2},
+		{"not cited", results, false, "Go 1.24 shipped in February.", 0},
+		{"not cited with --always-cite", results, true, "Go 1.24 shipped in February.", 2},
+		{"brackets that are not markers", results, false, "Use a[i] or [x].", 0},
+		{"no search", nil, true, "[1]", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &App{cfg: &config.Config{AlwaysCite: tt.alwaysCite}, searchResults: tt.results}
+			got := app.citations(tt.answer)
+			if len(got) != tt.want {
+				t.Fatalf("citations(%q) = %v, want %d sources", tt.answer, got, tt.want)
+			}
+			if tt.want > 0 && (got[0].Title != "Go 1.24 Release Notes" || got[0].URL != "https://go.dev/doc/go1.24") {
+				t.Errorf("citations[0] = %+v", got[0])
+			}
+		})
+	}
+}
+
+// failingSearch fails every search
+type failingSearch struct{}
+
+func (failingSearch) Search(context.Context, string) (*api.SearchResponse, error) {
+	return nil, errors.New("status code 401")
+}
+
+func (failingSearch) SetKeyRotationCallback(func(fromIndex, toIndex, totalKeys int)) {}
+
+func TestMultiSearchQuota(t *testing.T) {
+	state := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", state)
+	t.Setenv(config.EnvQuotaPeriod, "")
+	t.Setenv(config.EnvQuotaResetDay, "")
+	t.Setenv(config.EnvTavilyAPIKeys, "tvly-key")
+	t.Setenv(config.EnvBraveAPIKeys, "brave-key")
+
+	cfg := &config.Config{WebSearchProvider: config.SearchProviderAll}
+	cfg.LoadSearchKeys()
+	multi := api.NewMultiSearchClient([]string{"tavily", "brave"}, []api.SearchClient{&stubSearch{}, failingSearch{}})
+	app := &App{cfg: cfg, searchClients: map[string]api.SearchClient{config.SearchProviderAll: multi}}
+
+	if _, err := app.performWebSearch("go"); err != nil {
+		t.Fatalf("performWebSearch() error = %v", err)
+	}
+
+	usage, err := quota.Load(filepath.Join(state, config.AppDirName, config.QuotaFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	period, _ := quota.ParsePeriod("", 0)
+	tests := []struct {
+		provider, key string
+		want          int
+	}{
+		{"tavily", "tvly-key", 1},
+		{"brave", "brave-key", 0},
+	}
+	for _, tt := range tests {
+		if got := usage.Count(tt.provider, tt.key, period, time.Now()); got != tt.want {
+			t.Errorf("%s searches recorded = %d, want %d", tt.provider, got, tt.want)
+		}
+	}
+}
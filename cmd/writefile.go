@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/display"
+)
+
+// fileWrite is a write_file tool call with the diff it would apply
+type fileWrite struct {
+	Path      string
+	Content   string
+	Reasoning string
+	Diff      string // Unified diff from the current contents; "" when unchanged
+	Exists    bool
+}
+
+// parseFileWrite decodes write_file arguments and diffs them against the file on disk
+func parseFileWrite(arguments string) (*fileWrite, error) {
+	var args struct {
+		Path      string `json:"path"`
+		Content   string `json:"content"`
+		Reasoning string `json:"reasoning"`
+	}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return nil, err
+	}
+	if args.Path == "" {
+		return nil, errors.New("path is required")
+	}
+
+	w := &fileWrite{Path: args.Path, Content: args.Content, Reasoning: args.Reasoning}
+	old, err := os.ReadFile(args.Path)
+	switch {
+	case err == nil:
+		w.Exists = true
+		w.Diff = unifiedDiff("a/"+args.Path, "b/"+args.Path, string(old), args.Content)
+	case errors.Is(err, fs.ErrNotExist):
+		w.Diff = unifiedDiff("/dev/null", "b/"+args.Path, "", args.Content)
+		if w.Diff == "" { // An empty new file still needs creating
+			w.Diff = fmt.Sprintf("--- /dev/null\n+++ b/%s\n", args.Path)
+		}
+	default:
+		return nil, err
+	}
+	return w, nil
+}
+
+// writeFileAtomic replaces path with data through a temporary file in the same
+// directory, so readers never see a partly written file. An existing file keeps
+// its permissions; new files get 0644 and any missing parent directories.
+func writeFileAtomic(path string, data []byte) error {
+	mode := fs.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }() // No-op after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// runWriteFile shows the diff a write_file call would apply, asks for approval unless
+// the batch review already decided, and writes the file atomically. With dryRun the
+// diff is only shown.
+func (app *App) runWriteFile(toolCall api.ToolCall, dryRun bool) string {
+	w, err := parseFileWrite(toolCall.Function.Arguments)
+	if err != nil {
+		display.ShowError(fmt.Sprintf("Failed to prepare file write: %v", err))
+		return fmt.Sprintf("Invalid tool arguments: %v", err)
+	}
+	if w.Exists && w.Diff == "" {
+		return fmt.Sprintf("File %s already has this content; nothing was written", w.Path)
+	}
+
+	if dryRun {
+		display.ShowDiff(w.Diff)
+		display.ShowFileDryRun(w.Path)
+		return DryRunToolMessage
+	}
+
+	allow, reviewed := app.writeApprovals[toolCall.ID]
+	if !reviewed {
+		display.ShowDiff(w.Diff)
+		added, removed := diffStat(w.Diff)
+		allow = display.AskFileWriteConfirmation(w.Path, w.Reasoning, added, removed, !w.Exists)
+	}
+	if !allow {
+		return fmt.Sprintf("Writing %s denied by user", w.Path)
+	}
+
+	if err := writeFileAtomic(w.Path, []byte(w.Content)); err != nil {
+		display.ShowError(fmt.Sprintf("Failed to write %s: %v", w.Path, err))
+		return fmt.Sprintf("Failed to write %s: %v", w.Path, err)
+	}
+	display.ShowFileWritten(w.Path)
+	return fmt.Sprintf("Wrote %d bytes to %s", len(w.Content), w.Path)
+}
+
+// reviewFileWrites lets the user approve several write_file calls from one response
+// together: all diffs are shown with a summary, then all, none, or each one on its own
+// is approved. Decisions are kept in app.writeApprovals for runWriteFile. In dry-run
+// mode there is nothing to approve.
+func (app *App) reviewFileWrites(toolCalls []api.ToolCall, dryRun bool) {
+	app.writeApprovals = nil
+	if dryRun {
+		return
+	}
+
+	var ids []string
+	var writes []*fileWrite
+	for _, tc := range toolCalls {
+		if tc.Function.Name != api.WriteFileTool.Function.Name {
+			continue
+		}
+		w, err := parseFileWrite(tc.Function.Arguments)
+		if err != nil || (w.Exists && w.Diff == "") {
+			continue // Reported when the call itself runs
+		}
+		ids = append(ids, tc.ID)
+		writes = append(writes, w)
+	}
+	if len(writes) < 2 {
+		return
+	}
+
+	changes := make([]display.FileChange, len(writes))
+	for i, w := range writes {
+		display.ShowDiff(w.Diff)
+		added, removed := diffStat(w.Diff)
+		changes[i] = display.FileChange{Path: w.Path, Added: added, Removed: removed, New: !w.Exists}
+	}
+
+	var allow bool
+	switch display.AskFileWritesApproval(changes) {
+	case "all":
+		allow = true
+	case "none":
+		allow = false
+	default:
+		return // Each write asks on its own
+	}
+	app.writeApprovals = make(map[string]bool, len(ids))
+	for _, id := range ids {
+		app.writeApprovals[id] = allow
+	}
+}
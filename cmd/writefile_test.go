@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/api"
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.sh")
+	if err := os.WriteFile(path, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileAtomic(path, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	info, _ := os.Stat(path)
+	if string(data) != "new" || info.Mode().Perm() != 0o755 {
+		t.Errorf("file = %q mode %v, want %q mode 0755", data, info.Mode().Perm(), "new")
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want the temp file cleaned up", len(entries))
+	}
+}
+
+func TestRunWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	call := func(id, path, content string) api.ToolCall {
+		tc := api.ToolCall{ID: id}
+		tc.Function.Name = api.WriteFileTool.Function.Name
+		tc.Function.Arguments = fmt.Sprintf(`{"path":%q,"content":%q,"reasoning":"test"}`, path, content)
+		return tc
+	}
+	written := filepath.Join(dir, "sub", "a.txt")
+	denied := filepath.Join(dir, "b.txt")
+
+	// Decisions from a batch review are used without prompting
+	app := &App{cfg: &config.Config{}, writeApprovals: map[string]bool{"1": true, "2": false}}
+	if got := app.handleToolCall(context.Background(), nil, call("1", written, "hello\n")); !strings.HasPrefix(got, "Wrote 6 bytes") {
+		t.Errorf("approved write = %q", got)
+	}
+	if data, err := os.ReadFile(written); err != nil || string(data) != "hello\n" {
+		t.Errorf("written file = %q, %v", data, err)
+	}
+
+	if got := app.handleToolCall(context.Background(), nil, call("2", denied, "x")); !strings.Contains(got, "denied") {
+		t.Errorf("denied write = %q", got)
+	}
+	if _, err := os.Stat(denied); !os.IsNotExist(err) {
+		t.Errorf("denied file exists: %v", err)
+	}
+
+	if got := app.handleToolCall(context.Background(), nil, call("3", written, "hello\n")); !strings.Contains(got, "already has this content") {
+		t.Errorf("unchanged write = %q", got)
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -21,6 +23,8 @@ type Message struct {
 	Content    string     `json:"content,omitempty"`
 	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
 	ToolCallID string     `json:"tool_call_id,omitempty"`
+	// Images holds image data URLs sent alongside Content as multimodal parts
+	Images []string `json:"-"`
 }
 
 // Tool represents a function/tool that the AI can call
@@ -38,6 +42,8 @@ type Function struct {
 
 // ToolCall represents a function call from the AI
 type ToolCall struct {
+	// Index identifies which call a streaming fragment belongs to; it is only set on stream deltas
+	Index    *int   `json:"index,omitempty"`
 	ID       string `json:"id"`
 	Type     string `json:"type"`
 	Function struct {
@@ -48,10 +54,36 @@ type ToolCall struct {
 
 // ChatRequest represents the Chat Completions API request
 type ChatRequest struct {
-	Model    string    `json:"model"`
-	Messages []Message `json:"messages"`
-	Tools    []Tool    `json:"tools,omitempty"`
-	Stream   bool      `json:"stream,omitempty"`
+	Model       string    `json:"model"`
+	Messages    []Message `json:"messages"`
+	Tools       []Tool    `json:"tools,omitempty"`
+	Stream      bool      `json:"stream,omitempty"`
+	Temperature float64   `json:"temperature,omitempty"` // 0 = model default
+	MaxTokens   int       `json:"max_tokens,omitempty"`  // 0 = model default
+	User        string    `json:"user,omitempty"`        // End-user ID for abuse monitoring
+	N           int       `json:"n,omitempty"`           // Alternative answers; 0 = one
+
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+}
+
+// Response format types
+const (
+	ResponseFormatJSONObject = "json_object"
+	ResponseFormatJSONSchema = "json_schema"
+)
+
+// ResponseFormat constrains the answer to JSON: any object ("json_object", which
+// needs the word JSON somewhere in the messages) or one matching JSONSchema ("json_schema")
+type ResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *JSONSchemaFormat `json:"json_schema,omitempty"`
+}
+
+// JSONSchemaFormat is a named JSON schema for "json_schema" structured output
+type JSONSchemaFormat struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+	Strict bool            `json:"strict,omitempty"`
 }
 
 // Usage represents token usage statistics
@@ -104,32 +136,235 @@ type AzureErrorResponse struct {
 	} `json:"error"`
 }
 
+// ErrEmptyResponse is returned when the API responds successfully but with no choices
+var ErrEmptyResponse = errors.New("empty response from API (no choices returned)")
+
+// ErrResponseTooLarge is the cause of a StreamInterruptedError when a streamed
+// response passes the configured MaxResponseBytes
+var ErrResponseTooLarge = errors.New("response exceeded the size limit (see --max-response-bytes)")
+
+// StreamInterruptedError is returned when a stream breaks before completing.
+// Partial holds the content received so far so callers can keep it.
+type StreamInterruptedError struct {
+	Partial      string
+	HadToolCalls bool
+	Err          error
+}
+
+func (e *StreamInterruptedError) Error() string {
+	return fmt.Sprintf("stream interrupted after %d chars: %v", len([]rune(e.Partial)), e.Err)
+}
+
+func (e *StreamInterruptedError) Unwrap() error {
+	return e.Err
+}
+
+// Empty reports whether nothing was received before the stream broke
+func (e *StreamInterruptedError) Empty() bool {
+	return e.Partial == "" && !e.HadToolCalls
+}
+
 // APIError represents an error with status code
 type APIError struct {
 	StatusCode int
 	Message    string
+	RetryAfter time.Duration // Parsed Retry-After header, 0 if absent
 }
 
 func (e *APIError) Error() string {
 	return e.Message
 }
 
+// ToolCallProgressCallback is called while a tool call streams in, with the call assembled so far
+type ToolCallProgressCallback func(call ToolCall)
+
+// UsageCallback is called with the token usage of each successful response that reports it
+type UsageCallback func(usage Usage)
+
+// ContextOverflowCallback is called when a request's estimated prompt size exceeds the
+// --max-context limit; dropped is how many old messages were left out (0 unless trimming)
+type ContextOverflowCallback func(estimated, limit, dropped int)
+
+// ModelFallbackCallback is called when a request falls back to another model.
+// err is the failure that triggered the fallback; it is nil once toModel has answered.
+type ModelFallbackCallback func(fromModel, toModel string, err error)
+
 // AzureClient is the Azure OpenAI API client
 type AzureClient struct {
-	httpClient *http.Client
-	config     *config.Config
+	httpClient      *http.Client
+	config          *config.Config
+	capabilities    *capabilityCache
+	onModelFallback ModelFallbackCallback
+	onToolCall      ToolCallProgressCallback
+	onKeyRotation   KeyRotationCallback
+	onUsage         UsageCallback
+	onOverflow      ContextOverflowCallback
+	streamDebug     io.Writer       // Receives raw SSE lines when set (--debug-stream)
+	responseFormat  *ResponseFormat // Sent with every request when set (--json-mode)
 }
 
 // NewAzureClient creates a new Azure OpenAI client
 func NewAzureClient(cfg *config.Config) *AzureClient {
 	return &AzureClient{
-		httpClient: &http.Client{
-			Timeout: 120 * time.Second,
-		},
-		config: cfg,
+		httpClient:   newHTTPClient(120 * time.Second),
+		config:       cfg,
+		capabilities: newCapabilityCache(),
+	}
+}
+
+// SetModelFallbackCallback sets a callback function for model fallback events
+func (c *AzureClient) SetModelFallbackCallback(callback ModelFallbackCallback) {
+	c.onModelFallback = callback
+}
+
+// SetToolCallProgressCallback sets a callback for tool call fragments arriving on a stream
+func (c *AzureClient) SetToolCallProgressCallback(callback ToolCallProgressCallback) {
+	c.onToolCall = callback
+}
+
+// SetStreamDebugWriter makes streaming requests copy each raw SSE line to w
+// as received, before parsing; nil turns it off
+func (c *AzureClient) SetStreamDebugWriter(w io.Writer) {
+	c.streamDebug = w
+}
+
+// SetKeyRotationCallback sets a callback function for API key rotation events
+func (c *AzureClient) SetKeyRotationCallback(callback func(fromIndex, toIndex, totalKeys int)) {
+	c.onKeyRotation = callback
+}
+
+// SetUsageCallback sets a callback receiving the token usage of every successful request
+func (c *AzureClient) SetUsageCallback(callback UsageCallback) {
+	c.onUsage = callback
+}
+
+// SetContextOverflowCallback sets a callback for requests estimated to exceed --max-context
+func (c *AzureClient) SetContextOverflowCallback(callback ContextOverflowCallback) {
+	c.onOverflow = callback
+}
+
+// SetResponseFormat makes every request ask for structured output in the given
+// format; nil restores free-form answers
+func (c *AzureClient) SetResponseFormat(format *ResponseFormat) {
+	c.responseFormat = format
+}
+
+// fitContext checks messages against --max-context before a request. Over the limit it
+// reports the overflow and, with --trim-context, sends only the newest messages that fit;
+// the caller's history is left as is.
+func (c *AzureClient) fitContext(messages []Message) []Message {
+	limit := c.config.MaxContextTokens
+	if limit <= 0 {
+		return messages
+	}
+	estimated := EstimateTokens(messages)
+	if estimated <= limit {
+		return messages
+	}
+	dropped := 0
+	if c.config.TrimContext {
+		messages, dropped = TrimToTokens(messages, limit)
+	}
+	log.Printf("Prompt estimated at %d tokens exceeds the %d token limit, dropped %d message(s)", estimated, limit, dropped)
+	if c.onOverflow != nil {
+		c.onOverflow(estimated, limit, dropped)
+	}
+	return messages
+}
+
+// reportUsage passes a response's usage to the usage callback, if both are present
+func (c *AzureClient) reportUsage(usage Usage) {
+	if c.onUsage != nil && usage.TotalTokens > 0 {
+		c.onUsage(usage)
 	}
 }
 
+// withKeyRotation runs attempt, switching to the next API key and retrying with
+// backoff while the error indicates the current key is rejected or rate-limited
+func (c *AzureClient) withKeyRotation(ctx context.Context, attempt func() error) error {
+	var statuses []int
+	for try := 0; ; try++ {
+		err := attempt()
+		apiErr, ok := err.(*APIError)
+		if !ok || !ShouldRotateKey(apiErr.StatusCode) {
+			return err
+		}
+		statuses = append(statuses, apiErr.StatusCode)
+
+		if rotateErr := c.rotateKey(); rotateErr != nil {
+			if len(statuses) == 1 {
+				// Single key (or already on the last one): keep the plain API error
+				return err
+			}
+			return &KeysExhaustedError{
+				Provider:   "Azure",
+				StatusCode: apiErr.StatusCode,
+				Statuses:   statuses,
+				Err:        err,
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("request cancelled: %w", ctx.Err())
+		case <-time.After(CalculateBackoff(try)):
+		}
+	}
+}
+
+// rotateKey attempts to switch to the next available API key
+func (c *AzureClient) rotateKey() error {
+	oldIndex := 0
+	if c.config.AzureKeys != nil {
+		oldIndex = c.config.AzureKeys.GetCurrentIndex()
+	}
+	if _, err := c.config.RotateAzureKey(); err != nil {
+		return err
+	}
+
+	if c.onKeyRotation != nil {
+		c.onKeyRotation(oldIndex+1, c.config.AzureKeys.GetCurrentIndex()+1, c.config.GetAzureKeyCount())
+	}
+
+	return nil
+}
+
+// withModelFallback runs attempt with the primary model, then with each fallback
+// model in order while the error indicates the model is unavailable
+func (c *AzureClient) withModelFallback(attempt func(model string) error) error {
+	models := []string{c.config.Model}
+	for _, m := range c.config.FallbackModels {
+		if m != c.config.Model {
+			models = append(models, m)
+		}
+	}
+
+	var err error
+	for i, model := range models {
+		err = attempt(model)
+		if err == nil {
+			if i > 0 && c.onModelFallback != nil {
+				c.onModelFallback(models[0], model, nil)
+			}
+			return nil
+		}
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || !ShouldFallbackModel(apiErr.StatusCode) || i == len(models)-1 {
+			return err
+		}
+		if c.onModelFallback != nil {
+			c.onModelFallback(model, models[i+1], err)
+		}
+	}
+	return err
+}
+
+// GetCapabilities returns the capabilities observed so far for the given model
+func (c *AzureClient) GetCapabilities(model string) ModelCapabilities {
+	return c.capabilities.get(model)
+}
+
 // Query sends a query to Azure OpenAI (non-streaming)
 func (c *AzureClient) Query(systemPrompt, userMessage string) (*ChatResponse, error) {
 	return c.QueryWithContext(context.Background(), systemPrompt, userMessage)
@@ -156,25 +391,138 @@ func (c *AzureClient) QueryWithHistoryContext(ctx context.Context, messages []Me
 
 // QueryWithHistoryAndToolsContext sends a query with full message history, tools, and context support (non-streaming)
 func (c *AzureClient) QueryWithHistoryAndToolsContext(ctx context.Context, messages []Message, tools []Tool) (*ChatResponse, error) {
-	reqBody := ChatRequest{
-		Model:    c.config.Model,
-		Messages: messages,
-		Tools:    tools,
-		Stream:   false,
+	return c.query(ctx, c.newRequest(messages, tools, false))
+}
+
+// QueryModelContext sends messages to model instead of the configured one (non-streaming),
+// e.g. a cheaper deployment for summaries. Fallback models are not tried.
+func (c *AzureClient) QueryModelContext(ctx context.Context, model string, messages []Message) (*ChatResponse, error) {
+	reqBody := c.newRequest(messages, nil, false)
+	reqBody.Model = model
+	var resp *ChatResponse
+	err := c.withKeyRotation(ctx, func() error {
+		var err error
+		resp, err = c.doQuery(ctx, reqBody)
+		return err
+	})
+	c.capabilities.recordRequest(model, reqBody, err)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// QueryChoices sends a query asking for n alternative answers, returned as separate
+// choices (non-streaming)
+func (c *AzureClient) QueryChoices(ctx context.Context, systemPrompt, userMessage string, n int) (*ChatResponse, error) {
+	reqBody := c.newRequest([]Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userMessage},
+	}, nil, false)
+	if n > 1 {
+		reqBody.N = n
 	}
+	return c.query(ctx, reqBody)
+}
 
-	jsonData, err := json.Marshal(reqBody)
+// ErrNoUsage is returned by CountTokens when the response reports no prompt tokens
+var ErrNoUsage = errors.New("response has no token usage")
+
+// CountTokens returns the prompt tokens Azure counts for messages. Chat Completions
+// has no count-only call and rejects max_tokens 0, so this generates a single token.
+func (c *AzureClient) CountTokens(ctx context.Context, messages []Message) (int, error) {
+	reqBody := c.newRequest(messages, nil, false)
+	reqBody.MaxTokens = 1
+	resp, err := c.query(ctx, reqBody)
+	if err != nil {
+		return 0, err
+	}
+	if resp.Usage.PromptTokens == 0 {
+		return 0, ErrNoUsage
+	}
+	return resp.Usage.PromptTokens, nil
+}
+
+// newRequest builds a chat request with the configured model and generation parameters
+func (c *AzureClient) newRequest(messages []Message, tools []Tool, stream bool) ChatRequest {
+	return ChatRequest{
+		Model:       c.config.Model,
+		Messages:    c.fitContext(messages),
+		Tools:       tools,
+		Stream:      stream,
+		Temperature: c.config.Temperature,
+		MaxTokens:   c.config.MaxTokens,
+		User:        c.config.User,
+
+		ResponseFormat: c.responseFormat,
+	}
+}
+
+// query sends a non-streaming request with model fallback and key rotation
+func (c *AzureClient) query(ctx context.Context, reqBody ChatRequest) (*ChatResponse, error) {
+	var resp *ChatResponse
+	err := c.withModelFallback(func(model string) error {
+		reqBody.Model = model
+		err := c.withKeyRotation(ctx, func() error {
+			var err error
+			resp, err = c.doQuery(ctx, reqBody)
+			if errors.Is(err, ErrEmptyResponse) {
+				// Azure occasionally returns no choices under load; retry once
+				log.Printf("Empty response from model %s, retrying once", model)
+				resp, err = c.doQuery(ctx, reqBody)
+			}
+			return err
+		})
+		c.capabilities.recordRequest(model, reqBody, err)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// marshalRequest encodes a chat request with the --extra-body fields merged in.
+// Extra fields replace any the client sets itself, e.g. max_tokens.
+func (c *AzureClient) marshalRequest(reqBody ChatRequest) ([]byte, error) {
+	data, err := json.Marshal(reqBody)
+	if err != nil || len(c.config.ExtraBodyFields) == 0 {
+		return data, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range c.config.ExtraBodyFields {
+		fields[key] = value
+	}
+	return json.Marshal(fields)
+}
+
+// setAuthHeader authenticates a request with the current API key: in the api-key
+// header classic resource keys use, or as a bearer token (the default)
+func (c *AzureClient) setAuthHeader(req *http.Request) {
+	if c.config.AuthMode == config.AuthAPIKey {
+		req.Header.Set("api-key", c.config.AzureAPIKey)
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+c.config.AzureAPIKey)
+}
+
+// doQuery performs a single non-streaming request
+func (c *AzureClient) doQuery(ctx context.Context, reqBody ChatRequest) (*ChatResponse, error) {
+	jsonData, err := c.marshalRequest(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.GetAzureAPIURL(), bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.GetAzureAPIURL(reqBody.Model), bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.config.AzureAPIKey)
+	c.setAuthHeader(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -189,7 +537,7 @@ func (c *AzureClient) QueryWithHistoryAndToolsContext(ctx context.Context, messa
 
 	if resp.StatusCode != http.StatusOK {
 		var errResp AzureErrorResponse
-		errMsg := fmt.Sprintf("status code %d", resp.StatusCode)
+		errMsg := statusMessage(resp.StatusCode, body)
 		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Message != "" {
 			errMsg = errResp.Error.Message
 		}
@@ -203,6 +551,10 @@ func (c *AzureClient) QueryWithHistoryAndToolsContext(ctx context.Context, messa
 	if err := json.Unmarshal(body, &chatResp); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
+	if len(chatResp.Choices) == 0 {
+		return nil, ErrEmptyResponse
+	}
+	c.reportUsage(chatResp.Usage)
 
 	return &chatResp, nil
 }
@@ -233,53 +585,93 @@ func (c *AzureClient) QueryStreamWithHistoryContext(ctx context.Context, message
 
 // QueryStreamWithHistoryAndToolsContext sends a streaming query with full message history, tools, and context support
 func (c *AzureClient) QueryStreamWithHistoryAndToolsContext(ctx context.Context, messages []Message, tools []Tool, onChunk func(content string), onDone func(resp *ChatResponse)) error {
-	reqBody := ChatRequest{
-		Model:    c.config.Model,
-		Messages: messages,
-		Tools:    tools,
-		Stream:   true,
+	resp, err := c.QueryStreamWithToolsContext(ctx, messages, tools, onChunk)
+	if err != nil {
+		return err
 	}
+	// onDone also carries assembled tool calls, which may arrive without usage
+	if onDone != nil && (resp.Usage.TotalTokens > 0 || len(resp.Choices) > 0 && len(resp.Choices[0].GetToolCalls()) > 0) {
+		onDone(resp)
+	}
+	return nil
+}
 
-	jsonData, err := json.Marshal(reqBody)
+// QueryStreamWithToolsContext streams a query and returns the assembled response.
+// Content is passed to onChunk as it arrives; tool calls are accumulated from the
+// stream and returned in the response message, as a non-streaming query would.
+func (c *AzureClient) QueryStreamWithToolsContext(ctx context.Context, messages []Message, tools []Tool, onChunk func(content string)) (*ChatResponse, error) {
+	reqBody := c.newRequest(messages, tools, true)
+
+	var result *ChatResponse
+	err := c.withModelFallback(func(model string) error {
+		reqBody.Model = model
+		err := c.withKeyRotation(ctx, func() error {
+			resp, err := c.doQueryStream(ctx, reqBody, onChunk)
+			var interrupted *StreamInterruptedError
+			if errors.Is(err, ErrEmptyResponse) || (errors.As(err, &interrupted) && interrupted.Empty() && !errors.Is(err, ErrResponseTooLarge)) {
+				// Nothing was streamed yet, so a single retry is safe
+				log.Printf("Empty or dropped stream from model %s (%v), retrying once", model, err)
+				resp, err = c.doQueryStream(ctx, reqBody, onChunk)
+			}
+			result = resp
+			return err
+		})
+		c.capabilities.recordRequest(model, reqBody, err)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// doQueryStream performs a single streaming request and returns the assembled response
+func (c *AzureClient) doQueryStream(ctx context.Context, reqBody ChatRequest, onChunk func(content string)) (*ChatResponse, error) {
+	jsonData, err := c.marshalRequest(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.GetAzureAPIURL(), bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.GetAzureStreamAPIURL(reqBody.Model), bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "text/event-stream")
-	req.Header.Set("Authorization", "Bearer "+c.config.AzureAPIKey)
+	c.setAuthHeader(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		var errResp AzureErrorResponse
-		errMsg := fmt.Sprintf("status code %d", resp.StatusCode)
+		errMsg := statusMessage(resp.StatusCode, body)
 		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Message != "" {
 			errMsg = errResp.Error.Message
 		}
-		return &APIError{
+		return nil, &APIError{
 			StatusCode: resp.StatusCode,
 			Message:    fmt.Sprintf("Azure API error: %s", errMsg),
 		}
 	}
 
-	var finalResp *ChatResponse
+	result := &ChatResponse{}
+	var content strings.Builder
+	var toolCalls toolCallAccumulator
+	finishReason := ""
+	sawChoices := false
+	received := 0 // Content and tool call argument bytes, checked against MaxResponseBytes
 	reader := bufio.NewReader(resp.Body)
 
 	for {
 		// Check for context cancellation
 		if err := ctx.Err(); err != nil {
-			return fmt.Errorf("request cancelled: %w", err)
+			return nil, fmt.Errorf("request cancelled: %w", err)
 		}
 
 		line, err := reader.ReadString('\n')
@@ -287,13 +679,20 @@ func (c *AzureClient) QueryStreamWithHistoryAndToolsContext(ctx context.Context,
 			if err == io.EOF {
 				break
 			}
-			return fmt.Errorf("failed to read stream: %w", err)
+			return nil, &StreamInterruptedError{
+				Partial:      content.String(),
+				HadToolCalls: len(toolCalls.order) > 0,
+				Err:          err,
+			}
 		}
 
 		line = strings.TrimSpace(line)
 		if line == "" {
 			continue
 		}
+		if c.streamDebug != nil {
+			fmt.Fprintf(c.streamDebug, "[stream] %s\n", line)
+		}
 
 		if !strings.HasPrefix(line, "data: ") {
 			continue
@@ -301,6 +700,8 @@ func (c *AzureClient) QueryStreamWithHistoryAndToolsContext(ctx context.Context,
 
 		data := strings.TrimPrefix(line, "data: ")
 		if data == "[DONE]" {
+			// Drain anything after [DONE] so the connection can be reused
+			_, _ = io.Copy(io.Discard, reader)
 			break
 		}
 
@@ -311,22 +712,61 @@ func (c *AzureClient) QueryStreamWithHistoryAndToolsContext(ctx context.Context,
 			continue
 		}
 
-		// Send content chunk
-		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
-			onChunk(chunk.Choices[0].Delta.Content)
+		if chunk.ID != "" {
+			result.ID = chunk.ID
+		}
+
+		// Send content chunk and accumulate tool call fragments
+		if len(chunk.Choices) > 0 {
+			sawChoices = true
+			delta := chunk.Choices[0].Delta
+			received += len(delta.Content)
+			for _, fragment := range delta.ToolCalls {
+				received += len(fragment.Function.Arguments)
+			}
+			if limit := c.config.MaxResponseBytes; limit > 0 && received > limit {
+				return nil, &StreamInterruptedError{
+					Partial:      content.String(),
+					HadToolCalls: len(toolCalls.order) > 0 || len(delta.ToolCalls) > 0,
+					Err:          fmt.Errorf("%w: %d bytes", ErrResponseTooLarge, limit),
+				}
+			}
+			if delta.Content != "" {
+				content.WriteString(delta.Content)
+				onChunk(delta.Content)
+			}
+			for _, fragment := range delta.ToolCalls {
+				call := toolCalls.add(fragment)
+				if c.onToolCall != nil {
+					c.onToolCall(call)
+				}
+			}
+			if chunk.Choices[0].FinishReason != "" {
+				finishReason = chunk.Choices[0].FinishReason
+			}
 		}
 
 		// Capture usage from final chunk
 		if chunk.Usage.TotalTokens > 0 {
-			finalResp = &chunk
+			result.Usage = chunk.Usage
 		}
 	}
 
-	if onDone != nil && finalResp != nil {
-		onDone(finalResp)
+	if !sawChoices {
+		return nil, ErrEmptyResponse
 	}
 
-	return nil
+	result.Choices = []Choice{{
+		Message: Message{
+			Role:      "assistant",
+			Content:   content.String(),
+			ToolCalls: toolCalls.calls(),
+		},
+		FinishReason: finishReason,
+	}}
+	c.reportUsage(result.Usage)
+
+	return result, nil
 }
 
 // GetContent extracts the content from the response
@@ -340,6 +780,21 @@ func (r *ChatResponse) GetContent() string {
 	return ""
 }
 
+// GetContents returns the content of every choice in index order, e.g. the
+// alternative answers of a QueryChoices request
+func (r *ChatResponse) GetContents() []string {
+	choices := append([]Choice(nil), r.Choices...)
+	sort.SliceStable(choices, func(i, j int) bool { return choices[i].Index < choices[j].Index })
+	contents := make([]string, len(choices))
+	for i, ch := range choices {
+		contents[i] = ch.Message.Content
+		if contents[i] == "" {
+			contents[i] = ch.Delta.Content
+		}
+	}
+	return contents
+}
+
 // GetUsageMap returns usage as a map for display
 func (r *ChatResponse) GetUsageMap() map[string]int {
 	return map[string]int{
@@ -0,0 +1,642 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+)
+
+// newTestClient returns a client pointed at a test server running handler
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*AzureClient, *config.Config) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := &config.Config{
+		AzureEndpoint: server.URL,
+		AzureAPIKey:   "test-key",
+		Model:         "primary",
+	}
+	return NewAzureClient(cfg), cfg
+}
+
+// requestModel decodes the model name from a chat completion request body
+func requestModel(t *testing.T, r *http.Request) string {
+	t.Helper()
+	body, _ := io.ReadAll(r.Body)
+	var req ChatRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		t.Fatalf("invalid request body: %v", err)
+	}
+	return req.Model
+}
+
+func TestQueryModelFallback(t *testing.T) {
+	var tried []string
+	client, cfg := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		model := requestModel(t, r)
+		tried = append(tried, model)
+		switch model {
+		case "primary":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"message":"deployment not found"}}`))
+		case "busy":
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+		}
+	})
+	cfg.FallbackModels = []string{"busy", "backup"}
+
+	var answeredBy string
+	client.SetModelFallbackCallback(func(from, to string, err error) {
+		if err == nil {
+			answeredBy = to
+		}
+	})
+
+	resp, err := client.QueryWithHistory([]Message{{Role: "user", Content: "hi"}})
+	if err != nil {
+		t.Fatalf("QueryWithHistory() error = %v", err)
+	}
+	if got := resp.GetContent(); got != "ok" {
+		t.Errorf("content = %q, want %q", got, "ok")
+	}
+	if want := []string{"primary", "busy", "backup"}; len(tried) != len(want) || tried[2] != "backup" {
+		t.Errorf("tried models = %v, want %v", tried, want)
+	}
+	if answeredBy != "backup" {
+		t.Errorf("answered by = %q, want %q", answeredBy, "backup")
+	}
+}
+
+func TestQueryRotatesAPIKeys(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      string
+		wantErr   bool
+		wantCalls int
+	}{
+		{"second key works", "bad,good", false, 2},
+		{"all keys rejected", "bad,worse", true, 2},
+		{"single key unchanged", "bad", true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			client, cfg := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if r.Header.Get("Authorization") != "Bearer good" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+			})
+			t.Setenv(config.EnvAzureAPIKeys, tt.keys)
+			cfg.AzureKeys = config.NewKeyRotator(config.EnvAzureAPIKeys)
+			cfg.AzureAPIKey = cfg.AzureKeys.GetCurrentKey()
+
+			rotations := 0
+			client.SetKeyRotationCallback(func(from, to, total int) { rotations++ })
+
+			_, err := client.QueryWithHistory([]Message{{Role: "user", Content: "hi"}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("QueryWithHistory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls || rotations != tt.wantCalls-1 {
+				t.Errorf("calls = %d, rotations = %d; want %d, %d", calls, rotations, tt.wantCalls, tt.wantCalls-1)
+			}
+			var apiErr *APIError
+			if tt.wantErr && !errors.As(err, &apiErr) {
+				t.Errorf("error = %v, want it to wrap *APIError", err)
+			}
+		})
+	}
+}
+
+func TestQueryNoFallbackOnOtherErrors(t *testing.T) {
+	calls := 0
+	client, cfg := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	cfg.FallbackModels = []string{"backup"}
+
+	if _, err := client.QueryWithHistory([]Message{{Role: "user", Content: "hi"}}); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestQueryEmptyResponse(t *testing.T) {
+	calls := 0
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			_, _ = w.Write([]byte(`{"choices":[]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"recovered"}}]}`))
+	})
+
+	resp, err := client.QueryWithHistory([]Message{{Role: "user", Content: "hi"}})
+	if err != nil {
+		t.Fatalf("QueryWithHistory() error = %v", err)
+	}
+	if resp.GetContent() != "recovered" || calls != 2 {
+		t.Errorf("content = %q after %d calls, want %q after 2", resp.GetContent(), calls, "recovered")
+	}
+
+	empty, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"choices":[]}`))
+	})
+	if _, err := empty.QueryWithHistory([]Message{{Role: "user", Content: "hi"}}); !errors.Is(err, ErrEmptyResponse) {
+		t.Errorf("error = %v, want ErrEmptyResponse", err)
+	}
+}
+
+func TestQueryGenerationParameters(t *testing.T) {
+	tests := []struct {
+		name        string
+		temperature float64
+		maxTokens   int
+		user        string
+		want        map[string]bool // request fields expected present
+	}{
+		{"unset omitted", 0, 0, "", map[string]bool{"temperature": false, "max_tokens": false, "user": false}},
+		{"all sent", 0.2, 256, "alice", map[string]bool{"temperature": true, "max_tokens": true, "user": true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields map[string]json.RawMessage
+			client, cfg := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				_ = json.Unmarshal(body, &fields)
+				_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+			})
+			cfg.Temperature = tt.temperature
+			cfg.MaxTokens = tt.maxTokens
+			cfg.User = tt.user
+
+			if _, err := client.QueryWithHistory([]Message{{Role: "user", Content: "hi"}}); err != nil {
+				t.Fatalf("QueryWithHistory() error = %v", err)
+			}
+			for field, want := range tt.want {
+				if _, got := fields[field]; got != want {
+					t.Errorf("request has %s = %v, want %v", field, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryChoices(t *testing.T) {
+	var req ChatRequest
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_, _ = w.Write([]byte(`{"choices":[
+			{"index":1,"message":{"role":"assistant","content":"second"}},
+			{"index":0,"message":{"role":"assistant","content":"first"}},
+			{"index":2,"message":{"role":"assistant","content":"third"}}
+		]}`))
+	})
+
+	resp, err := client.QueryChoices(context.Background(), "system", "hi", 3)
+	if err != nil {
+		t.Fatalf("QueryChoices() error = %v", err)
+	}
+	if req.N != 3 || req.Stream {
+		t.Errorf("request n = %d, stream = %v, want 3 and false", req.N, req.Stream)
+	}
+	got := strings.Join(resp.GetContents(), ",")
+	if got != "first,second,third" {
+		t.Errorf("GetContents() = %s, want first,second,third in index order", got)
+	}
+
+	// A single answer leaves n out of the request
+	req = ChatRequest{}
+	if _, err := client.QueryChoices(context.Background(), "system", "hi", 1); err != nil {
+		t.Fatal(err)
+	}
+	if req.N != 0 {
+		t.Errorf("request n = %d for one answer, want omitted", req.N)
+	}
+}
+
+func TestCountTokens(t *testing.T) {
+	var req ChatRequest
+	body := `{"choices":[{"message":{"role":"assistant","content":"I"}}],"usage":{"prompt_tokens":42,"completion_tokens":1,"total_tokens":43}}`
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_, _ = w.Write([]byte(body))
+	})
+	messages := []Message{{Role: "user", Content: "how many tokens?"}}
+
+	tokens, err := client.CountTokens(context.Background(), messages)
+	if err != nil || tokens != 42 {
+		t.Errorf("CountTokens() = %d, %v, want 42", tokens, err)
+	}
+	if req.MaxTokens != 1 {
+		t.Errorf("request max_tokens = %d, want 1", req.MaxTokens)
+	}
+
+	body = `{"choices":[{"message":{"role":"assistant","content":"I"}}]}`
+	if _, err := client.CountTokens(context.Background(), messages); !errors.Is(err, ErrNoUsage) {
+		t.Errorf("CountTokens() without usage error = %v, want ErrNoUsage", err)
+	}
+}
+
+func TestQueryStreamEmptyResponse(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("data: {\"choices\":[]}\n\ndata: [DONE]\n\n"))
+	})
+
+	err := client.QueryStreamWithHistory([]Message{{Role: "user", Content: "hi"}}, func(string) {}, nil)
+	if !errors.Is(err, ErrEmptyResponse) {
+		t.Errorf("error = %v, want ErrEmptyResponse", err)
+	}
+}
+
+func TestQueryStreamDebugWriter(t *testing.T) {
+	stream := "data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\ndata: {broken\n\ndata: [DONE]\n\n"
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(stream))
+	})
+	var debug bytes.Buffer
+	client.SetStreamDebugWriter(&debug)
+
+	if err := client.QueryStreamWithHistory([]Message{{Role: "user", Content: "hi"}}, func(string) {}, nil); err != nil {
+		t.Fatalf("QueryStreamWithHistory() error = %v", err)
+	}
+	want := "[stream] data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n[stream] data: {broken\n[stream] data: [DONE]\n"
+	if debug.String() != want {
+		t.Errorf("debug output = %q, want %q", debug.String(), want)
+	}
+}
+
+func TestQueryStreamMaxResponseBytes(t *testing.T) {
+	chunk := "data: {\"choices\":[{\"delta\":{\"content\":\"0123456789\"}}]}\n\n"
+	client, cfg := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(chunk + chunk + chunk + "data: [DONE]\n\n"))
+	})
+	cfg.MaxResponseBytes = 25
+
+	var received strings.Builder
+	_, err := client.QueryStreamWithToolsContext(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, func(s string) {
+		received.WriteString(s)
+	})
+
+	var interrupted *StreamInterruptedError
+	if !errors.As(err, &interrupted) || !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("error = %v, want StreamInterruptedError wrapping ErrResponseTooLarge", err)
+	}
+	if interrupted.Partial != "01234567890123456789" || received.String() != interrupted.Partial {
+		t.Errorf("partial = %q, received = %q; want the first two chunks", interrupted.Partial, received.String())
+	}
+}
+
+func TestQueryStreamAccumulatesToolCalls(t *testing.T) {
+	stream := `data: {"choices":[{"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"execute_command","arguments":""}}]}}]}
+
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"command\":"}}]}}]}
+
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"git status\"}"}}]}}]}
+
+data: {"choices":[{"delta":{},"finish_reason":"tool_calls"}]}
+
+data: [DONE]
+
+`
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(stream))
+	})
+
+	var progress []string
+	client.SetToolCallProgressCallback(func(call ToolCall) {
+		progress = append(progress, call.Function.Arguments)
+	})
+
+	resp, err := client.QueryStreamWithToolsContext(context.Background(), []Message{{Role: "user", Content: "hi"}}, GetDefaultTools(), func(string) {})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	calls := resp.Choices[0].GetToolCalls()
+	if len(calls) != 1 {
+		t.Fatalf("got %d tool calls, want 1", len(calls))
+	}
+	if calls[0].ID != "call_1" || calls[0].Function.Name != "execute_command" {
+		t.Errorf("call = %+v, want call_1 execute_command", calls[0])
+	}
+	if want := `{"command":"git status"}`; calls[0].Function.Arguments != want {
+		t.Errorf("arguments = %q, want %q", calls[0].Function.Arguments, want)
+	}
+	if calls[0].Index != nil {
+		t.Errorf("assembled call should not carry a stream index")
+	}
+	if len(progress) != 3 || progress[2] != `{"command":"git status"}` {
+		t.Errorf("progress = %q, want 3 updates ending in full arguments", progress)
+	}
+	if resp.Choices[0].FinishReason != "tool_calls" {
+		t.Errorf("finish reason = %q, want tool_calls", resp.Choices[0].FinishReason)
+	}
+}
+
+func TestQueryStreamToolCallsViaOnDone(t *testing.T) {
+	stream := `data: {"choices":[{"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"web_search","arguments":"{\"query\":"}}]}}]}
+
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"go\"}"}}]},"finish_reason":"tool_calls"}]}
+
+data: [DONE]
+
+`
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(stream))
+	})
+
+	var calls []ToolCall
+	err := client.QueryStreamWithHistoryAndToolsContext(context.Background(), []Message{{Role: "user", Content: "hi"}}, GetDefaultTools(),
+		func(string) {},
+		func(resp *ChatResponse) { calls = resp.Choices[0].GetToolCalls() },
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(calls) != 1 || calls[0].Function.Arguments != `{"query":"go"}` {
+		t.Errorf("onDone tool calls = %+v, want one web_search call with full arguments", calls)
+	}
+}
+
+// dropStream writes an SSE response containing body and then drops the connection mid-stream
+func dropStream(t *testing.T, w http.ResponseWriter, body string) {
+	t.Helper()
+	conn, buf, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		t.Fatalf("hijack failed: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Type: text/event-stream\r\nTransfer-Encoding: chunked\r\n\r\n")
+	if body != "" {
+		_, _ = fmt.Fprintf(buf, "%x\r\n%s\r\n", len(body), body)
+	}
+	_ = buf.Flush()
+}
+
+func TestQueryStreamInterrupted(t *testing.T) {
+	attempts := 0
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		dropStream(t, w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hello wor\"}}]}\n\n")
+	})
+
+	var streamed string
+	_, err := client.QueryStreamWithToolsContext(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, func(s string) {
+		streamed += s
+	})
+
+	var interrupted *StreamInterruptedError
+	if !errors.As(err, &interrupted) {
+		t.Fatalf("error = %v, want StreamInterruptedError", err)
+	}
+	if interrupted.Partial != "Hello wor" || streamed != "Hello wor" {
+		t.Errorf("partial = %q, streamed = %q, want %q", interrupted.Partial, streamed, "Hello wor")
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1 (no retry once content was streamed)", attempts)
+	}
+}
+
+func TestQueryStreamRetriesDropBeforeContent(t *testing.T) {
+	attempts := 0
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			dropStream(t, w, "")
+			return
+		}
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"ok\"}}]}\n\ndata: [DONE]\n\n"))
+	})
+
+	resp, err := client.QueryStreamWithToolsContext(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, func(string) {})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := resp.GetContent(); got != "ok" {
+		t.Errorf("content = %q, want ok", got)
+	}
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+}
+
+func TestUsageCallback(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req ChatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Stream {
+			_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\ndata: {\"choices\":[],\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":1,\"total_tokens\":4}}\n\ndata: [DONE]\n\n"))
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"hi"}}],"usage":{"prompt_tokens":10,"completion_tokens":2,"total_tokens":12}}`))
+	})
+
+	var got []Usage
+	client.SetUsageCallback(func(u Usage) { got = append(got, u) })
+
+	if _, err := client.Query("sys", "hi"); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if _, err := client.QueryStreamWithToolsContext(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, func(string) {}); err != nil {
+		t.Fatalf("QueryStreamWithToolsContext() error = %v", err)
+	}
+
+	if len(got) != 2 || got[0].TotalTokens != 12 || got[1].TotalTokens != 4 {
+		t.Errorf("usage callback got %+v, want totals 12 then 4", got)
+	}
+}
+
+func TestResponseFormat(t *testing.T) {
+	var formats []*ResponseFormat
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req ChatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		formats = append(formats, req.ResponseFormat)
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"{}"}}]}`))
+	})
+
+	if _, err := client.Query("sys", "hi"); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	client.SetResponseFormat(&ResponseFormat{Type: ResponseFormatJSONObject})
+	if _, err := client.Query("sys", "hi"); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+
+	if len(formats) != 2 {
+		t.Fatalf("got %d requests, want 2", len(formats))
+	}
+	if formats[0] != nil {
+		t.Errorf("default request response_format = %+v, want none", formats[0])
+	}
+	if formats[1] == nil || formats[1].Type != ResponseFormatJSONObject {
+		t.Errorf("response_format = %+v, want json_object", formats[1])
+	}
+}
+
+func TestNonJSONErrorBody(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("<html>\n  <body>Request blocked by WAF</body>\n</html>"))
+	})
+	_, err := client.QueryWithHistory([]Message{{Role: "user", Content: "hi"}})
+	if err == nil || !strings.Contains(err.Error(), "status code 403: <html> <body>Request blocked by WAF</body> </html>") {
+		t.Errorf("error = %v, want the status and body snippet", err)
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"empty", "", "status code 502"},
+		{"json without message", `{"error":{}}`, "status code 502"},
+		{"plain text", "upstream connect error\n", "status code 502: upstream connect error"},
+		{"truncated", strings.Repeat("x", maxErrorSnippet+10), "status code 502: " + strings.Repeat("x", maxErrorSnippet) + "..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statusMessage(http.StatusBadGateway, []byte(tt.body)); got != tt.want {
+				t.Errorf("statusMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtraBody(t *testing.T) {
+	var body map[string]json.RawMessage
+	client, cfg := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+	})
+	cfg.MaxTokens = 100
+	cfg.ExtraBodyFields = map[string]json.RawMessage{
+		"user":       json.RawMessage(`"alice"`),
+		"max_tokens": json.RawMessage(`50`),
+	}
+
+	if _, err := client.Query("sys", "hi"); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if string(body["user"]) != `"alice"` {
+		t.Errorf("user = %s, want the extra field", body["user"])
+	}
+	if string(body["max_tokens"]) != "50" {
+		t.Errorf("max_tokens = %s, want the extra field to win", body["max_tokens"])
+	}
+	if string(body["model"]) != `"primary"` || len(body["messages"]) == 0 {
+		t.Errorf("request lost its own fields: %v", body)
+	}
+}
+
+func TestQueryDeploymentURL(t *testing.T) {
+	var paths, keys []string
+	client, cfg := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path+"?"+r.URL.RawQuery)
+		keys = append(keys, r.Header.Get("api-key")+"|"+r.Header.Get("Authorization"))
+		if strings.Contains(r.URL.Path, "/primary/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+	})
+	cfg.APIVersion = "2024-10-21"
+	cfg.AuthMode = config.AuthAPIKey
+	cfg.FallbackModels = []string{"backup"}
+
+	if _, err := client.QueryWithHistory([]Message{{Role: "user", Content: "hi"}}); err != nil {
+		t.Fatalf("QueryWithHistory() error = %v", err)
+	}
+	want := []string{
+		"/openai/deployments/primary/chat/completions?api-version=2024-10-21",
+		"/openai/deployments/backup/chat/completions?api-version=2024-10-21",
+	}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+	if keys[0] != "test-key|" {
+		t.Errorf("auth headers = %q, want the key in api-key only", keys[0])
+	}
+
+	cfg.AuthMode = config.AuthBearer
+	if _, err := client.QueryWithHistory([]Message{{Role: "user", Content: "hi"}}); err != nil {
+		t.Fatalf("QueryWithHistory() error = %v", err)
+	}
+	if last := keys[len(keys)-1]; last != "|Bearer test-key" {
+		t.Errorf("auth headers = %q, want a bearer token only", last)
+	}
+}
+
+func TestStreamEndpoint(t *testing.T) {
+	hits := map[string]int{}
+	handler := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			hits[name]++
+			var req ChatRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.Stream {
+				_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"ok\"},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n"))
+				return
+			}
+			_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+		}
+	}
+	main := httptest.NewServer(handler("main"))
+	defer main.Close()
+	stream := httptest.NewServer(handler("stream"))
+	defer stream.Close()
+
+	tests := []struct {
+		name           string
+		streamEndpoint string
+		streaming      bool
+		want           string
+	}{
+		{"non-streaming uses the endpoint", stream.URL, false, "main"},
+		{"streaming uses the stream endpoint", stream.URL, true, "stream"},
+		{"streaming without override", "", true, "main"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clear(hits)
+			cfg := &config.Config{AzureEndpoint: main.URL, AzureStreamEndpoint: tt.streamEndpoint, AzureAPIKey: "test-key", Model: "primary"}
+			client := NewAzureClient(cfg)
+			messages := []Message{{Role: "user", Content: "hi"}}
+			var err error
+			if tt.streaming {
+				err = client.QueryStreamWithHistory(messages, func(string) {}, nil)
+			} else {
+				_, err = client.QueryWithHistory(messages)
+			}
+			if err != nil {
+				t.Fatalf("request error = %v", err)
+			}
+			if hits[tt.want] != 1 || len(hits) != 1 {
+				t.Errorf("requests = %v, want one to %s", hits, tt.want)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/quocvuong92/azure-ai-cli/internal/config"
@@ -31,6 +32,15 @@ type BraveResult struct {
 	Description string `json:"description"`
 }
 
+// BraveErrorResponse represents an error from Brave
+type BraveErrorResponse struct {
+	Type  string `json:"type"`
+	Error struct {
+		Code   string `json:"code"`
+		Detail string `json:"detail"`
+	} `json:"error"`
+}
+
 // BraveClient is the Brave Search API client
 type BraveClient struct {
 	httpClient    *http.Client
@@ -44,10 +54,8 @@ var _ SearchClient = (*BraveClient)(nil)
 // NewBraveClient creates a new Brave Search client
 func NewBraveClient(cfg *config.Config) *BraveClient {
 	return &BraveClient{
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		config: cfg,
+		httpClient: newHTTPClient(30 * time.Second),
+		config:     cfg,
 	}
 }
 
@@ -62,7 +70,10 @@ func (c *BraveClient) Search(ctx context.Context, query string) (*SearchResponse
 	if err != nil {
 		return nil, err
 	}
-	return resp.ToSearchResponse(), nil
+	// Brave has no domain filter parameters, so they are applied to the results here
+	searchResp := resp.ToSearchResponse()
+	searchResp.Results = FilterResultsByDomain(searchResp.Results, c.config.IncludeDomains, c.config.ExcludeDomains)
+	return searchResp, nil
 }
 
 // SearchLegacy performs a web search using Brave Search (legacy method for backward compatibility)
@@ -72,43 +83,9 @@ func (c *BraveClient) SearchLegacy(query string) (*BraveResponse, error) {
 
 // searchWithRetry performs search with automatic key rotation on failure
 func (c *BraveClient) searchWithRetry(ctx context.Context, query string) (*BraveResponse, error) {
-	if c.config.GetBraveKeyCount() <= 1 {
+	return searchWithKeyRotation(ctx, "Brave", c.rotateKey, func() (*BraveResponse, error) {
 		return c.doSearch(ctx, query)
-	}
-
-	var lastErr error
-	for attempt := 0; attempt < MaxRetryAttempts; attempt++ {
-		// Check for context cancellation
-		if err := ctx.Err(); err != nil {
-			return nil, fmt.Errorf("search cancelled: %w", err)
-		}
-
-		resp, err := c.doSearch(ctx, query)
-		if err == nil {
-			return resp, nil
-		}
-		lastErr = err
-
-		apiErr, ok := err.(*APIError)
-		if !ok || !ShouldRotateKey(apiErr.StatusCode) {
-			return nil, err
-		}
-
-		if rotateErr := c.rotateKey(); rotateErr != nil {
-			return nil, fmt.Errorf("%v (no more Brave API keys available)", err)
-		}
-
-		// Apply backoff before retry
-		if attempt < MaxRetryAttempts-1 {
-			select {
-			case <-ctx.Done():
-				return nil, fmt.Errorf("search cancelled: %w", ctx.Err())
-			case <-time.After(CalculateBackoff(attempt)):
-			}
-		}
-	}
-
-	return nil, fmt.Errorf("max retry attempts (%d) exceeded: %v", MaxRetryAttempts, lastErr)
+	})
 }
 
 // doSearch performs a single search attempt
@@ -121,7 +98,7 @@ func (c *BraveClient) doSearch(ctx context.Context, query string) (*BraveRespons
 
 	params := url.Values{}
 	params.Set("q", query)
-	params.Set("count", "5")
+	params.Set("count", strconv.Itoa(c.config.GetSearchMaxResults()))
 	reqURL.RawQuery = params.Encode()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
@@ -146,7 +123,8 @@ func (c *BraveClient) doSearch(ctx context.Context, query string) (*BraveRespons
 	if resp.StatusCode != http.StatusOK {
 		return nil, &APIError{
 			StatusCode: resp.StatusCode,
-			Message:    fmt.Sprintf("Brave API error: status code %d", resp.StatusCode),
+			Message:    fmt.Sprintf("Brave API error: %s", braveErrorMessage(resp.StatusCode, body)),
+			RetryAfter: retryAfter(resp),
 		}
 	}
 
@@ -158,6 +136,25 @@ func (c *BraveClient) doSearch(ctx context.Context, query string) (*BraveRespons
 	return &braveResp, nil
 }
 
+// braveErrorMessage returns the detail and code from a Brave error body, e.g.
+// {"type":"ErrorResponse","error":{"code":"SUBSCRIPTION_TOKEN_INVALID","detail":"..."}},
+// falling back to the status code when the body can't be parsed
+func braveErrorMessage(statusCode int, body []byte) string {
+	var errResp BraveErrorResponse
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		return statusMessage(statusCode, body)
+	}
+	switch {
+	case errResp.Error.Detail != "" && errResp.Error.Code != "":
+		return fmt.Sprintf("%s (%s)", errResp.Error.Detail, errResp.Error.Code)
+	case errResp.Error.Detail != "":
+		return errResp.Error.Detail
+	case errResp.Error.Code != "":
+		return errResp.Error.Code
+	}
+	return statusMessage(statusCode, body)
+}
+
 // rotateKey attempts to switch to the next available API key
 func (c *BraveClient) rotateKey() error {
 	oldIndex := c.config.BraveCurrentKeyIdx
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+// ModelCapabilities records which features a model has been observed to support.
+// A nil field means the capability has not been probed yet.
+type ModelCapabilities struct {
+	Tools     *bool
+	Vision    *bool
+	Streaming *bool
+}
+
+// capabilityCache tracks capabilities per model, filled lazily as requests succeed or fail
+type capabilityCache struct {
+	mu     sync.RWMutex
+	models map[string]*ModelCapabilities
+}
+
+func newCapabilityCache() *capabilityCache {
+	return &capabilityCache{
+		models: make(map[string]*ModelCapabilities),
+	}
+}
+
+// get returns a copy of the cached capabilities for a model
+func (cc *capabilityCache) get(model string) ModelCapabilities {
+	cc.mu.RLock()
+	defer cc.mu.RUnlock()
+	if caps, ok := cc.models[model]; ok {
+		return *caps
+	}
+	return ModelCapabilities{}
+}
+
+// record updates a single capability flag for a model
+func (cc *capabilityCache) record(model string, update func(caps *ModelCapabilities)) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	caps, ok := cc.models[model]
+	if !ok {
+		caps = &ModelCapabilities{}
+		cc.models[model] = caps
+	}
+	update(caps)
+}
+
+// recordRequest updates the cache from the outcome of a chat completion request
+func (cc *capabilityCache) recordRequest(model string, req ChatRequest, err error) {
+	if err == nil {
+		cc.record(model, func(caps *ModelCapabilities) {
+			if len(req.Tools) > 0 {
+				caps.Tools = boolPtr(true)
+			}
+			if req.Stream {
+				caps.Streaming = boolPtr(true)
+			}
+			if hasImages(req.Messages) {
+				caps.Vision = boolPtr(true)
+			}
+		})
+		return
+	}
+
+	// Only a 400 that names the feature is treated as proof it is unsupported
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 400 {
+		return
+	}
+	msg := strings.ToLower(apiErr.Message)
+	cc.record(model, func(caps *ModelCapabilities) {
+		if len(req.Tools) > 0 && strings.Contains(msg, "tool") {
+			caps.Tools = boolPtr(false)
+		}
+		if req.Stream && strings.Contains(msg, "stream") {
+			caps.Streaming = boolPtr(false)
+		}
+	})
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
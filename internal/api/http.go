@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// sharedTransport pools connections for every API client, so Azure and search
+// requests in one session reuse keep-alive (HTTP/2 where offered) connections
+// instead of paying a TLS handshake per request.
+var sharedTransport = newTransport()
+
+func newTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.ForceAttemptHTTP2 = true
+	t.MaxIdleConns = 20
+	t.MaxIdleConnsPerHost = 4
+	t.IdleConnTimeout = 90 * time.Second
+	return t
+}
+
+// newHTTPClient returns a client with its own timeout on the shared transport
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: sharedTransport,
+	}
+}
+
+// maxErrorSnippet caps how much of a non-JSON error body goes into an error message
+const maxErrorSnippet = 200
+
+// statusMessage describes a failed response whose body had no usable JSON error. A body
+// that is not JSON at all (a proxy's HTML error page, a WAF block, plain text) is
+// appended as a short snippet so the real cause is visible.
+func statusMessage(statusCode int, body []byte) string {
+	msg := fmt.Sprintf("status code %d", statusCode)
+	if json.Valid(body) {
+		return msg
+	}
+	snippet := strings.Join(strings.Fields(string(body)), " ")
+	if snippet == "" {
+		return msg
+	}
+	if runes := []rune(snippet); len(runes) > maxErrorSnippet {
+		snippet = string(runes[:maxErrorSnippet]) + "..."
+	}
+	return fmt.Sprintf("%s: %s", msg, snippet)
+}
+
+// retryAfter returns the wait a response's Retry-After header asks for, 0 if none
+func retryAfter(resp *http.Response) time.Duration {
+	return ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+}
+
+// searchWithKeyRotation runs search for a search provider, switching to the next key with
+// backoff while the error indicates the current key is rejected or rate-limited. A short
+// Retry-After on 429 usually clears with a wait, so the same key is retried once first.
+func searchWithKeyRotation[T any](ctx context.Context, provider string, rotateKey func() error, search func() (T, error)) (T, error) {
+	var zero T
+	var lastErr error
+	var statuses []int
+	waited := false // Retry-After already honoured for the current key
+	for attempt := 0; attempt < MaxRetryAttempts; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return zero, fmt.Errorf("search cancelled: %w", err)
+		}
+
+		resp, err := search()
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+
+		apiErr, ok := err.(*APIError)
+		if !ok || !ShouldRotateKey(apiErr.StatusCode) {
+			return zero, err
+		}
+
+		if wait, ok := RetryAfterWait(apiErr); ok && !waited && attempt < MaxRetryAttempts-1 {
+			waited = true
+			select {
+			case <-ctx.Done():
+				return zero, fmt.Errorf("search cancelled: %w", ctx.Err())
+			case <-time.After(wait):
+			}
+			continue
+		}
+		statuses = append(statuses, apiErr.StatusCode)
+
+		if rotateErr := rotateKey(); rotateErr != nil {
+			return zero, &KeysExhaustedError{
+				Provider:   provider,
+				StatusCode: apiErr.StatusCode,
+				Statuses:   statuses,
+				Err:        err,
+			}
+		}
+		waited = false
+
+		if attempt < MaxRetryAttempts-1 {
+			select {
+			case <-ctx.Done():
+				return zero, fmt.Errorf("search cancelled: %w", ctx.Err())
+			case <-time.After(CalculateBackoff(attempt)):
+			}
+		}
+	}
+
+	return zero, fmt.Errorf("max retry attempts (%d) exceeded: %v", MaxRetryAttempts, lastErr)
+}
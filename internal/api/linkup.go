@@ -54,10 +54,8 @@ var _ SearchClient = (*LinkupClient)(nil)
 // NewLinkupClient creates a new Linkup client
 func NewLinkupClient(cfg *config.Config) *LinkupClient {
 	return &LinkupClient{
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		config: cfg,
+		httpClient: newHTTPClient(30 * time.Second),
+		config:     cfg,
 	}
 }
 
@@ -72,7 +70,10 @@ func (c *LinkupClient) Search(ctx context.Context, query string) (*SearchRespons
 	if err != nil {
 		return nil, err
 	}
-	return resp.ToSearchResponse(), nil
+	// Linkup has no domain filters, so they are applied to the results here
+	searchResp := resp.ToSearchResponse()
+	searchResp.Results = FilterResultsByDomain(searchResp.Results, c.config.IncludeDomains, c.config.ExcludeDomains)
+	return searchResp, nil
 }
 
 // SearchLegacy performs a web search using Linkup (legacy method for backward compatibility)
@@ -82,43 +83,9 @@ func (c *LinkupClient) SearchLegacy(query string) (*LinkupResponse, error) {
 
 // searchWithRetry performs search with automatic key rotation on failure
 func (c *LinkupClient) searchWithRetry(ctx context.Context, query string) (*LinkupResponse, error) {
-	if c.config.GetLinkupKeyCount() <= 1 {
+	return searchWithKeyRotation(ctx, "Linkup", c.rotateKey, func() (*LinkupResponse, error) {
 		return c.doSearch(ctx, query)
-	}
-
-	var lastErr error
-	for attempt := 0; attempt < MaxRetryAttempts; attempt++ {
-		// Check for context cancellation
-		if err := ctx.Err(); err != nil {
-			return nil, fmt.Errorf("search cancelled: %w", err)
-		}
-
-		resp, err := c.doSearch(ctx, query)
-		if err == nil {
-			return resp, nil
-		}
-		lastErr = err
-
-		apiErr, ok := err.(*APIError)
-		if !ok || !ShouldRotateKey(apiErr.StatusCode) {
-			return nil, err
-		}
-
-		if rotateErr := c.rotateKey(); rotateErr != nil {
-			return nil, fmt.Errorf("%v (no more Linkup API keys available)", err)
-		}
-
-		// Apply backoff before retry
-		if attempt < MaxRetryAttempts-1 {
-			select {
-			case <-ctx.Done():
-				return nil, fmt.Errorf("search cancelled: %w", ctx.Err())
-			case <-time.After(CalculateBackoff(attempt)):
-			}
-		}
-	}
-
-	return nil, fmt.Errorf("max retry attempts (%d) exceeded: %v", MaxRetryAttempts, lastErr)
+	})
 }
 
 // doSearch performs a single search attempt
@@ -127,7 +94,7 @@ func (c *LinkupClient) doSearch(ctx context.Context, query string) (*LinkupRespo
 		Query:      query,
 		Depth:      "standard",
 		OutputType: "searchResults",
-		MaxResults: 5,
+		MaxResults: c.config.GetSearchMaxResults(),
 	}
 
 	jsonData, err := json.Marshal(reqBody)
@@ -156,7 +123,7 @@ func (c *LinkupClient) doSearch(ctx context.Context, query string) (*LinkupRespo
 
 	if resp.StatusCode != http.StatusOK {
 		var errResp LinkupErrorResponse
-		errMsg := fmt.Sprintf("status code %d", resp.StatusCode)
+		errMsg := statusMessage(resp.StatusCode, body)
 		if err := json.Unmarshal(body, &errResp); err == nil {
 			if errResp.Message != "" {
 				errMsg = errResp.Message
@@ -167,6 +134,7 @@ func (c *LinkupClient) doSearch(ctx context.Context, query string) (*LinkupRespo
 		return nil, &APIError{
 			StatusCode: resp.StatusCode,
 			Message:    fmt.Sprintf("Linkup API error: %s", errMsg),
+			RetryAfter: retryAfter(resp),
 		}
 	}
 
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// MultiSearchClient searches several providers at once and merges their results
+// (--provider all)
+type MultiSearchClient struct {
+	providers []string
+	clients   []SearchClient
+}
+
+// Ensure MultiSearchClient implements SearchClient
+var _ SearchClient = (*MultiSearchClient)(nil)
+
+// NewMultiSearchClient creates a client that queries each provider's client concurrently;
+// providers names the clients for error messages
+func NewMultiSearchClient(providers []string, clients []SearchClient) *MultiSearchClient {
+	return &MultiSearchClient{providers: providers, clients: clients}
+}
+
+// SetKeyRotationCallback sets the key rotation callback on every provider's client
+func (c *MultiSearchClient) SetKeyRotationCallback(callback func(fromIndex, toIndex, totalKeys int)) {
+	for _, client := range c.clients {
+		client.SetKeyRotationCallback(callback)
+	}
+}
+
+// Search queries all providers concurrently and merges the results with mergeSearchResults.
+// Providers that fail are skipped; an error is returned only if every provider failed.
+// The response's Providers lists the providers that answered, e.g. for quota tracking.
+func (c *MultiSearchClient) Search(ctx context.Context, query string) (*SearchResponse, error) {
+	responses := make([]*SearchResponse, len(c.clients))
+	errs := make([]error, len(c.clients))
+
+	// Errors are kept per provider rather than returned to the group, so one failure
+	// neither cancels the other searches nor discards their results
+	var g errgroup.Group
+	for i, client := range c.clients {
+		g.Go(func() error {
+			responses[i], errs[i] = client.Search(ctx, query)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	var ok []*SearchResponse
+	var answered []string
+	var failed []error
+	for i, err := range errs {
+		if err != nil {
+			log.Printf("Search with %s failed: %v", c.providers[i], err)
+			failed = append(failed, fmt.Errorf("%s: %w", c.providers[i], err))
+			continue
+		}
+		ok = append(ok, responses[i])
+		answered = append(answered, c.providers[i])
+	}
+	if len(ok) == 0 {
+		return nil, errors.Join(failed...)
+	}
+	merged := mergeSearchResults(ok)
+	merged.Providers = answered
+	return merged, nil
+}
+
+// mergeSearchResults interleaves the providers' results by rank, drops duplicate URLs
+// (keeping the highest score), and then orders results by score where providers give
+// one. Results without a score keep their interleaved order after the scored ones.
+func mergeSearchResults(responses []*SearchResponse) *SearchResponse {
+	merged := &SearchResponse{}
+	seen := make(map[string]int) // Normalized URL -> index in merged.Results
+	for rank := 0; ; rank++ {
+		more := false
+		for _, resp := range responses {
+			if rank >= len(resp.Results) {
+				continue
+			}
+			more = true
+			r := resp.Results[rank]
+			key := normalizeResultURL(r.URL)
+			if i, dup := seen[key]; dup {
+				merged.Results[i].Score = max(merged.Results[i].Score, r.Score)
+				continue
+			}
+			seen[key] = len(merged.Results)
+			merged.Results = append(merged.Results, r)
+		}
+		if !more {
+			break
+		}
+	}
+	for _, resp := range responses {
+		if merged.Answer == "" {
+			merged.Answer = resp.Answer
+		}
+	}
+
+	sort.SliceStable(merged.Results, func(i, j int) bool {
+		return merged.Results[i].Score > merged.Results[j].Score
+	})
+	return merged
+}
+
+// normalizeResultURL reduces a URL to what identifies the page, so the same result from
+// two providers matches: scheme, "www.", fragment and a trailing slash are ignored
+func normalizeResultURL(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return strings.ToLower(strings.TrimSpace(rawURL))
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	path := strings.TrimSuffix(u.EscapedPath(), "/")
+	key := host + path
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	return key
+}
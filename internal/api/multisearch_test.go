@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeSearchClient returns a fixed response or error
+type fakeSearchClient struct {
+	resp *SearchResponse
+	err  error
+}
+
+func (f *fakeSearchClient) Search(ctx context.Context, query string) (*SearchResponse, error) {
+	return f.resp, f.err
+}
+
+func (f *fakeSearchClient) SetKeyRotationCallback(func(fromIndex, toIndex, totalKeys int)) {}
+
+func TestMultiSearchClient(t *testing.T) {
+	tavily := &fakeSearchClient{resp: &SearchResponse{Results: []SearchResult{
+		{Title: "Go blog", URL: "https://go.dev/blog/go1.24", Score: 0.9},
+		{Title: "Release notes", URL: "https://go.dev/doc/go1.24", Score: 0.7},
+	}}}
+	brave := &fakeSearchClient{resp: &SearchResponse{Results: []SearchResult{
+		{Title: "Go blog (dup)", URL: "http://www.go.dev/blog/go1.24/#intro"},
+		{Title: "News", URL: "https://news.example.com/go"},
+	}}}
+	broken := &fakeSearchClient{err: errors.New("status code 401")}
+
+	client := NewMultiSearchClient([]string{"tavily", "brave", "linkup"}, []SearchClient{tavily, brave, broken})
+	resp, err := client.Search(context.Background(), "go 1.24")
+	if err != nil {
+		t.Fatalf("Search() error = %v, want failed providers skipped", err)
+	}
+
+	var titles []string
+	for _, r := range resp.Results {
+		titles = append(titles, r.Title)
+	}
+	want := "Go blog,Release notes,News"
+	if got := strings.Join(titles, ","); got != want {
+		t.Errorf("merged results = %s, want %s (deduped, scored first)", got, want)
+	}
+	if got := strings.Join(resp.Providers, ","); got != "tavily,brave" {
+		t.Errorf("Providers = %s, want tavily,brave (the failed linkup left out)", got)
+	}
+
+	client = NewMultiSearchClient([]string{"linkup"}, []SearchClient{broken})
+	if _, err := client.Search(context.Background(), "go"); err == nil || !strings.Contains(err.Error(), "linkup: status code 401") {
+		t.Errorf("Search() with every provider failing error = %v", err)
+	}
+}
+
+func TestNormalizeResultURL(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"https://www.Example.com/path/", "http://example.com/path#top", true},
+		{"https://example.com/a?id=1", "https://example.com/a?id=2", false},
+		{"https://example.com/a", "https://example.com/b", false},
+	}
+	for _, tt := range tests {
+		if got := normalizeResultURL(tt.a) == normalizeResultURL(tt.b); got != tt.same {
+			t.Errorf("normalizeResultURL(%q) == normalizeResultURL(%q) is %v, want %v", tt.a, tt.b, got, tt.same)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+)
+
+const (
+	PerplexityAPIURL = "https://api.perplexity.ai/chat/completions"
+	PerplexityModel  = "sonar"
+)
+
+// PerplexityRequest represents the Perplexity chat-completions request
+type PerplexityRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	// SearchDomainFilter lists allowed domains, with excluded ones prefixed by "-"
+	SearchDomainFilter []string `json:"search_domain_filter,omitempty"`
+}
+
+// PerplexityResponse represents the Perplexity chat-completions response
+type PerplexityResponse struct {
+	Choices       []PerplexityChoice       `json:"choices"`
+	Citations     []string                 `json:"citations"`
+	SearchResults []PerplexitySearchResult `json:"search_results"`
+}
+
+// PerplexityChoice represents a single completion choice
+type PerplexityChoice struct {
+	Message Message `json:"message"`
+}
+
+// PerplexitySearchResult represents a source the online model consulted
+type PerplexitySearchResult struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Date    string `json:"date"`
+	Snippet string `json:"snippet"`
+}
+
+// PerplexityErrorResponse represents an error from Perplexity
+type PerplexityErrorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error"`
+}
+
+// PerplexityClient is the Perplexity Sonar API client
+type PerplexityClient struct {
+	httpClient    *http.Client
+	config        *config.Config
+	onKeyRotation KeyRotationCallback
+}
+
+// Ensure PerplexityClient implements SearchClient
+var _ SearchClient = (*PerplexityClient)(nil)
+
+// NewPerplexityClient creates a new Perplexity client
+func NewPerplexityClient(cfg *config.Config) *PerplexityClient {
+	return &PerplexityClient{
+		httpClient: newHTTPClient(60 * time.Second),
+		config:     cfg,
+	}
+}
+
+// SetKeyRotationCallback sets a callback function for key rotation events
+func (c *PerplexityClient) SetKeyRotationCallback(callback func(fromIndex, toIndex, totalKeys int)) {
+	c.onKeyRotation = callback
+}
+
+// Search asks the online model and returns its answer with the cited sources (implements SearchClient interface)
+func (c *PerplexityClient) Search(ctx context.Context, query string) (*SearchResponse, error) {
+	resp, err := c.searchWithRetry(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return resp.ToSearchResponse(), nil
+}
+
+// searchWithRetry performs search with automatic key rotation on failure
+func (c *PerplexityClient) searchWithRetry(ctx context.Context, query string) (*PerplexityResponse, error) {
+	return searchWithKeyRotation(ctx, "Perplexity", c.rotateKey, func() (*PerplexityResponse, error) {
+		return c.doSearch(ctx, query)
+	})
+}
+
+// doSearch performs a single search attempt
+func (c *PerplexityClient) doSearch(ctx context.Context, query string) (*PerplexityResponse, error) {
+	reqBody := PerplexityRequest{
+		Model:    PerplexityModel,
+		Messages: []Message{{Role: "user", Content: query}},
+	}
+	// Filtered natively: dropping sources afterwards would break the answer's [n] markers
+	reqBody.SearchDomainFilter = append(reqBody.SearchDomainFilter, c.config.IncludeDomains...)
+	for _, d := range c.config.ExcludeDomains {
+		reqBody.SearchDomainFilter = append(reqBody.SearchDomainFilter, "-"+d)
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, PerplexityAPIURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.config.PerplexityAPIKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp PerplexityErrorResponse
+		errMsg := statusMessage(resp.StatusCode, body)
+		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Message != "" {
+			errMsg = errResp.Error.Message
+		}
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("Perplexity API error: %s", errMsg),
+			RetryAfter: retryAfter(resp),
+		}
+	}
+
+	var pplxResp PerplexityResponse
+	if err := json.Unmarshal(body, &pplxResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &pplxResp, nil
+}
+
+// rotateKey attempts to switch to the next available API key
+func (c *PerplexityClient) rotateKey() error {
+	oldIndex := c.config.PerplexityCurrentKeyIdx
+	_, err := c.config.RotatePerplexityKey()
+	if err != nil {
+		return err
+	}
+
+	if c.onKeyRotation != nil {
+		c.onKeyRotation(oldIndex+1, c.config.PerplexityCurrentKeyIdx+1, c.config.GetPerplexityKeyCount())
+	}
+
+	return nil
+}
+
+// ToSearchResponse converts PerplexityResponse to unified SearchResponse.
+// The answer's [n] markers refer to citations, so sources keep that order;
+// search_results only enrich them with titles and snippets.
+func (r *PerplexityResponse) ToSearchResponse() *SearchResponse {
+	var answer string
+	if len(r.Choices) > 0 {
+		answer = r.Choices[0].Message.Content
+	}
+
+	details := make(map[string]PerplexitySearchResult, len(r.SearchResults))
+	for _, res := range r.SearchResults {
+		details[res.URL] = res
+	}
+
+	urls := r.Citations
+	if len(urls) == 0 {
+		for _, res := range r.SearchResults {
+			urls = append(urls, res.URL)
+		}
+	}
+
+	results := make([]SearchResult, len(urls))
+	for i, u := range urls {
+		title := u
+		res, ok := details[u]
+		if ok && res.Title != "" {
+			title = res.Title
+		}
+		results[i] = SearchResult{
+			Title:   title,
+			URL:     u,
+			Content: res.Snippet,
+		}
+	}
+
+	return &SearchResponse{
+		Results: results,
+		Answer:  answer,
+	}
+}
@@ -1,6 +1,10 @@
 package api
 
 import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/quocvuong92/azure-ai-cli/internal/config"
@@ -12,6 +16,10 @@ const (
 	InitialBackoff    = 100 * time.Millisecond
 	MaxBackoff        = 2 * time.Second
 	BackoffMultiplier = 2.0
+
+	// MaxRetryAfter is the longest Retry-After wait honoured on a 429; longer
+	// requests rotate to the next key with the usual backoff instead
+	MaxRetryAfter = 10 * time.Second
 )
 
 // ShouldRotateKey checks if the error status code indicates we should try another key
@@ -36,3 +44,78 @@ func CalculateBackoff(attempt int) time.Duration {
 	}
 	return backoff
 }
+
+// ParseRetryAfter parses a Retry-After header given as delay-seconds or an HTTP date.
+// It returns 0 when the header is absent, malformed, or already in the past.
+func ParseRetryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		if secs <= 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// RetryAfterWait returns how long to wait before retrying the same key: only
+// for a 429 whose Retry-After is present and no longer than MaxRetryAfter
+func RetryAfterWait(apiErr *APIError) (time.Duration, bool) {
+	if apiErr.StatusCode != http.StatusTooManyRequests || apiErr.RetryAfter <= 0 || apiErr.RetryAfter > MaxRetryAfter {
+		return 0, false
+	}
+	return apiErr.RetryAfter, true
+}
+
+// ShouldFallbackModel checks if the error status code indicates the model is unavailable
+func ShouldFallbackModel(statusCode int) bool {
+	for _, code := range config.ModelFallbackErrorCodes {
+		if statusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// KeysExhaustedError is returned when every configured API key for a provider has failed.
+// It keeps the status codes seen so rate limiting can be told apart from rejected keys.
+type KeysExhaustedError struct {
+	Provider   string
+	StatusCode int   // Status code of the last failure
+	Statuses   []int // Status codes of every failed attempt
+	Err        error // Last underlying error
+}
+
+func (e *KeysExhaustedError) Error() string {
+	rateLimited, rejected := 0, 0
+	for _, code := range e.Statuses {
+		switch code {
+		case 429:
+			rateLimited++
+		case 401, 403:
+			rejected++
+		}
+	}
+
+	switch {
+	case rateLimited == len(e.Statuses):
+		return fmt.Sprintf("%s: all API keys rate-limited (429), try again later", e.Provider)
+	case rejected == len(e.Statuses):
+		return fmt.Sprintf("%s: all API keys rejected (%d), check your keys", e.Provider, e.StatusCode)
+	case rateLimited > 0 && rejected > 0:
+		return fmt.Sprintf("%s: no usable API keys (%d rejected, %d rate-limited); check your keys or try again later",
+			e.Provider, rejected, rateLimited)
+	default:
+		return fmt.Sprintf("%v (no more %s API keys available)", e.Err, e.Provider)
+	}
+}
+
+func (e *KeysExhaustedError) Unwrap() error {
+	return e.Err
+}
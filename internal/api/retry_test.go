@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeysExhaustedError(t *testing.T) {
+	last := &APIError{StatusCode: 429, Message: "Tavily API error: too many requests"}
+
+	tests := []struct {
+		name     string
+		statuses []int
+		want     string
+	}{
+		{"all rate limited", []int{429, 429}, "all API keys rate-limited (429), try again later"},
+		{"all rejected", []int{401, 403, 401}, "all API keys rejected"},
+		{"mixed", []int{401, 429}, "1 rejected, 1 rate-limited"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := &KeysExhaustedError{Provider: "Tavily", StatusCode: tt.statuses[len(tt.statuses)-1], Statuses: tt.statuses, Err: last}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Error() = %q, want it to contain %q", err.Error(), tt.want)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Error("expected KeysExhaustedError to unwrap to *APIError")
+			}
+		})
+	}
+}
+
+func TestShouldFallbackModel(t *testing.T) {
+	for code, want := range map[int]bool{404: true, 429: true, 400: false, 401: false, 500: false} {
+		if got := ShouldFallbackModel(code); got != want {
+			t.Errorf("ShouldFallbackModel(%d) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header string
+		want   time.Duration
+	}{
+		{"absent", "", 0},
+		{"seconds", "3", 3 * time.Second},
+		{"zero", "0", 0},
+		{"negative", "-5", 0},
+		{"http date", now.Add(7 * time.Second).Format(http.TimeFormat), 7 * time.Second},
+		{"past date", now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"garbage", "soon", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseRetryAfter(tt.header, now); got != tt.want {
+				t.Errorf("ParseRetryAfter(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfterWait(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    *APIError
+		want   time.Duration
+		wantOK bool
+	}{
+		{"429 short wait", &APIError{StatusCode: 429, RetryAfter: 2 * time.Second}, 2 * time.Second, true},
+		{"429 without header", &APIError{StatusCode: 429}, 0, false},
+		{"429 wait too long", &APIError{StatusCode: 429, RetryAfter: MaxRetryAfter + time.Second}, 0, false},
+		{"401 ignored", &APIError{StatusCode: 401, RetryAfter: time.Second}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := RetryAfterWait(tt.err)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("RetryAfterWait() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSearchWithKeyRotation(t *testing.T) {
+	rateLimited := &APIError{StatusCode: 429, RetryAfter: 10 * time.Millisecond}
+	tests := []struct {
+		name          string
+		errs          []error // returned by successive searches; nil succeeds
+		keys          int     // keys left to rotate to
+		wantCalls     int
+		wantRotations int
+		wantErr       string
+	}{
+		{"success", []error{nil}, 1, 1, 0, ""},
+		{"short Retry-After retries the same key", []error{rateLimited, nil}, 1, 2, 0, ""},
+		{"second 429 rotates", []error{rateLimited, rateLimited, nil}, 1, 3, 1, ""},
+		{"rejected key rotates", []error{&APIError{StatusCode: 401}, nil}, 1, 2, 1, ""},
+		{"other errors return at once", []error{&APIError{StatusCode: 400, Message: "bad query"}}, 1, 1, 0, "bad query"},
+		{"keys exhausted", []error{&APIError{StatusCode: 401}}, 0, 1, 0, "Test: all API keys rejected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls, rotations := 0, 0
+			rotate := func() error {
+				if rotations == tt.keys {
+					return errors.New("no more keys")
+				}
+				rotations++
+				return nil
+			}
+			got, err := searchWithKeyRotation(context.Background(), "Test", rotate, func() (string, error) {
+				err := tt.errs[calls]
+				calls++
+				if err != nil {
+					return "", err
+				}
+				return "results", nil
+			})
+			if calls != tt.wantCalls || rotations != tt.wantRotations {
+				t.Errorf("calls = %d, rotations = %d; want %d, %d", calls, rotations, tt.wantCalls, tt.wantRotations)
+			}
+			if tt.wantErr == "" {
+				if err != nil || got != "results" {
+					t.Errorf("searchWithKeyRotation() = %q, %v", got, err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,6 +3,10 @@ package api
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
 )
 
 // SearchResult represents a unified search result across all providers
@@ -15,17 +19,26 @@ type SearchResult struct {
 
 // SearchResponse represents a unified search response across all providers
 type SearchResponse struct {
-	Results []SearchResult
-	Answer  string // Optional answer from some providers
+	Results   []SearchResult
+	Answer    string   // Optional answer from some providers
+	Providers []string // Providers that answered a --provider all search
+}
+
+// formatDirectAnswer labels a provider's synthesized answer as a hint to weigh against the sources
+func formatDirectAnswer(answer string) string {
+	if answer == "" {
+		return ""
+	}
+	return fmt.Sprintf("Direct answer (provider-generated summary, verify against the sources below): %s\n\n", answer)
 }
 
 // FormatResultsAsContext formats search results for use as LLM context
 func (r *SearchResponse) FormatResultsAsContext() string {
-	if len(r.Results) == 0 {
+	if len(r.Results) == 0 && r.Answer == "" {
 		return ""
 	}
 
-	var result string
+	result := formatDirectAnswer(r.Answer)
 	for i, res := range r.Results {
 		result += fmt.Sprintf("[%d] %s\nURL: %s\n%s\n\n", i+1, res.Title, res.URL, res.Content)
 	}
@@ -49,6 +62,52 @@ func (r *SearchResponse) ToTavilyResponse() *TavilyResponse {
 	}
 }
 
+// FilterResultsByDomain keeps results whose host matches one of include (when
+// given) and none of exclude. A domain matches its subdomains too.
+func FilterResultsByDomain(results []SearchResult, include, exclude []string) []SearchResult {
+	if len(include) == 0 && len(exclude) == 0 {
+		return results
+	}
+	var kept []SearchResult
+	for _, r := range results {
+		host := resultHost(r.URL)
+		if len(include) > 0 && !matchesAnyDomain(host, include) {
+			continue
+		}
+		if matchesAnyDomain(host, exclude) {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	return kept
+}
+
+// resultHost returns the lower-cased host of a result URL, or "" if it can't be parsed
+func resultHost(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// matchesAnyDomain reports whether host is one of domains or a subdomain of one
+func matchesAnyDomain(host string, domains []string) bool {
+	if host == "" {
+		return false
+	}
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "."))
+		if d == "" {
+			continue
+		}
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
 // SearchClient defines the interface for web search providers
 type SearchClient interface {
 	// Search performs a web search with the given query
@@ -60,3 +119,20 @@ type SearchClient interface {
 
 // KeyRotationCallback is the function signature for key rotation notifications
 type KeyRotationCallback func(fromIndex, toIndex, totalKeys int)
+
+// NewSearchClient creates the search client for a provider name
+func NewSearchClient(provider string, cfg *config.Config) (SearchClient, error) {
+	switch provider {
+	case "tavily":
+		return NewTavilyClient(cfg), nil
+	case "linkup":
+		return NewLinkupClient(cfg), nil
+	case "brave":
+		return NewBraveClient(cfg), nil
+	case "perplexity":
+		return NewPerplexityClient(cfg), nil
+	case "searxng":
+		return NewSearXNGClient(cfg), nil
+	}
+	return nil, fmt.Errorf("%w: %s", config.ErrInvalidSearchProvider, provider)
+}
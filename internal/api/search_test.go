@@ -0,0 +1,204 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+)
+
+func TestFormatResultsAsContextDirectAnswer(t *testing.T) {
+	results := []TavilyResult{{Title: "Go 1.24", URL: "https://go.dev", Content: "Released in February"}}
+
+	tests := []struct {
+		name       string
+		resp       *TavilyResponse
+		wantPrefix string
+		wantEmpty  bool
+	}{
+		{"answer first", &TavilyResponse{Results: results, Answer: "February 2025"}, "Direct answer", false},
+		{"no answer", &TavilyResponse{Results: results}, "[1] Go 1.24", false},
+		{"answer only", &TavilyResponse{Answer: "February 2025"}, "Direct answer", false},
+		{"nothing", &TavilyResponse{}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.resp.FormatResultsAsContext()
+			if tt.wantEmpty {
+				if got != "" {
+					t.Errorf("FormatResultsAsContext() = %q, want empty", got)
+				}
+				return
+			}
+			if !strings.HasPrefix(got, tt.wantPrefix) {
+				t.Errorf("FormatResultsAsContext() = %q, want prefix %q", got, tt.wantPrefix)
+			}
+			// The sources must follow the answer, not be replaced by it
+			if len(tt.resp.Results) > 0 && !strings.Contains(got, "[1] Go 1.24") {
+				t.Errorf("FormatResultsAsContext() dropped results: %q", got)
+			}
+		})
+	}
+
+	// The unified response formats the same way
+	unified := (&TavilyResponse{Results: results, Answer: "February 2025"}).ToSearchResponse()
+	if got := unified.FormatResultsAsContext(); !strings.HasPrefix(got, "Direct answer") {
+		t.Errorf("SearchResponse.FormatResultsAsContext() = %q, want direct answer first", got)
+	}
+}
+
+func TestPerplexityToSearchResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		resp       *PerplexityResponse
+		wantAnswer string
+		wantTitles []string
+		wantURLs   []string
+	}{
+		{
+			name: "citations enriched by search results",
+			resp: &PerplexityResponse{
+				Choices:   []PerplexityChoice{{Message: Message{Content: "Go 1.24 shipped in February [1]."}}},
+				Citations: []string{"https://go.dev/blog", "https://example.com"},
+				SearchResults: []PerplexitySearchResult{
+					{Title: "Example", URL: "https://example.com", Snippet: "snippet"},
+					{Title: "Go Blog", URL: "https://go.dev/blog"},
+				},
+			},
+			wantAnswer: "Go 1.24 shipped in February [1].",
+			wantTitles: []string{"Go Blog", "Example"},
+			wantURLs:   []string{"https://go.dev/blog", "https://example.com"},
+		},
+		{
+			name:       "citations only",
+			resp:       &PerplexityResponse{Citations: []string{"https://go.dev"}},
+			wantTitles: []string{"https://go.dev"},
+			wantURLs:   []string{"https://go.dev"},
+		},
+		{
+			name:       "search results only",
+			resp:       &PerplexityResponse{SearchResults: []PerplexitySearchResult{{Title: "Go", URL: "https://go.dev"}}},
+			wantTitles: []string{"Go"},
+			wantURLs:   []string{"https://go.dev"},
+		},
+		{
+			name: "empty",
+			resp: &PerplexityResponse{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.resp.ToSearchResponse()
+			if got.Answer != tt.wantAnswer {
+				t.Errorf("Answer = %q, want %q", got.Answer, tt.wantAnswer)
+			}
+			if len(got.Results) != len(tt.wantURLs) {
+				t.Fatalf("got %d results, want %d", len(got.Results), len(tt.wantURLs))
+			}
+			for i, res := range got.Results {
+				if res.URL != tt.wantURLs[i] || res.Title != tt.wantTitles[i] {
+					t.Errorf("result %d = {%q, %q}, want {%q, %q}", i, res.Title, res.URL, tt.wantTitles[i], tt.wantURLs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFilterResultsByDomain(t *testing.T) {
+	results := []SearchResult{
+		{URL: "https://go.dev/doc"},
+		{URL: "https://pkg.go.dev/net/http"},
+		{URL: "https://www.example.com/post"},
+		{URL: "https://spam.test/page"},
+	}
+
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    []string
+	}{
+		{"no filters", nil, nil, []string{"https://go.dev/doc", "https://pkg.go.dev/net/http", "https://www.example.com/post", "https://spam.test/page"}},
+		{"include with subdomains", []string{"go.dev"}, nil, []string{"https://go.dev/doc", "https://pkg.go.dev/net/http"}},
+		{"exclude", nil, []string{"spam.test", "EXAMPLE.com"}, []string{"https://go.dev/doc", "https://pkg.go.dev/net/http"}},
+		{"include and exclude", []string{"go.dev"}, []string{"pkg.go.dev"}, []string{"https://go.dev/doc"}},
+		{"suffix is not a subdomain", []string{"ample.com"}, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FilterResultsByDomain(results, tt.include, tt.exclude)
+			if len(got) != len(tt.want) {
+				t.Fatalf("FilterResultsByDomain() kept %d results, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, r := range got {
+				if r.URL != tt.want[i] {
+					t.Errorf("result %d = %q, want %q", i, r.URL, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSearXNGSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search" || r.URL.Query().Get("format") != "json" || r.URL.Query().Get("q") != "go generics" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		_, _ = w.Write([]byte(`{"results":[
+			{"title":"Go","url":"https://go.dev/doc","content":"docs","score":2.5},
+			{"title":"Spam","url":"https://spam.test/","content":"x"},
+			{"title":"Blog","url":"https://go.dev/blog","content":"blog"}
+		]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{SearXNGURL: server.URL, SearchMaxResults: 1, ExcludeDomains: []string{"spam.test"}}
+	resp, err := NewSearXNGClient(cfg).Search(context.Background(), "go generics")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].URL != "https://go.dev/doc" || resp.Results[0].Score != 2.5 {
+		t.Errorf("Search() results = %+v, want only the first go.dev result", resp.Results)
+	}
+
+	// Instances that only allow HTML output reject JSON requests
+	forbidden := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer forbidden.Close()
+	cfg.SearXNGURL = forbidden.URL
+	if _, err := NewSearXNGClient(cfg).Search(context.Background(), "go"); err == nil || !strings.Contains(err.Error(), "json format") {
+		t.Errorf("Search() error = %v, want hint about enabling json format", err)
+	}
+}
+
+func TestBraveErrorMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			"detail and code",
+			`{"type":"ErrorResponse","error":{"id":"x","status":401,"code":"SUBSCRIPTION_TOKEN_INVALID","detail":"The provided subscription token is invalid."}}`,
+			"The provided subscription token is invalid. (SUBSCRIPTION_TOKEN_INVALID)",
+		},
+		{"code only", `{"type":"ErrorResponse","error":{"code":"RATE_LIMITED"}}`, "RATE_LIMITED"},
+		{"detail only", `{"error":{"detail":"Quota exceeded"}}`, "Quota exceeded"},
+		{"empty error", `{"type":"ErrorResponse","error":{}}`, "status code 429"},
+		{"not JSON", "Too Many Requests", "status code 429: Too Many Requests"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := braveErrorMessage(http.StatusTooManyRequests, []byte(tt.body)); got != tt.want {
+				t.Errorf("braveErrorMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/config"
+)
+
+// SearXNGResponse represents the SearXNG JSON search response
+type SearXNGResponse struct {
+	Results []SearXNGResult `json:"results"`
+}
+
+// SearXNGResult represents a single search result
+type SearXNGResult struct {
+	Title   string  `json:"title"`
+	URL     string  `json:"url"`
+	Content string  `json:"content"`
+	Score   float64 `json:"score"`
+}
+
+// SearXNGClient is the client for a self-hosted SearXNG instance. It needs no API
+// keys, so there is nothing to rotate and failed searches are not retried.
+type SearXNGClient struct {
+	httpClient *http.Client
+	config     *config.Config
+}
+
+// Ensure SearXNGClient implements SearchClient
+var _ SearchClient = (*SearXNGClient)(nil)
+
+// NewSearXNGClient creates a new SearXNG client
+func NewSearXNGClient(cfg *config.Config) *SearXNGClient {
+	return &SearXNGClient{
+		httpClient: newHTTPClient(30 * time.Second),
+		config:     cfg,
+	}
+}
+
+// SetKeyRotationCallback is a no-op: SearXNG instances are not keyed
+func (c *SearXNGClient) SetKeyRotationCallback(callback func(fromIndex, toIndex, totalKeys int)) {}
+
+// Search performs a web search on the SearXNG instance (implements SearchClient interface)
+func (c *SearXNGClient) Search(ctx context.Context, query string) (*SearchResponse, error) {
+	if c.config.SearXNGURL == "" {
+		return nil, fmt.Errorf("SearXNG URL not set. Set %s to your instance, e.g. http://localhost:8888", config.EnvSearXNGURL)
+	}
+
+	resp, err := c.doSearch(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// SearXNG has no result count or domain parameters, so both are applied here
+	searchResp := resp.ToSearchResponse()
+	searchResp.Results = FilterResultsByDomain(searchResp.Results, c.config.IncludeDomains, c.config.ExcludeDomains)
+	if n := c.config.GetSearchMaxResults(); len(searchResp.Results) > n {
+		searchResp.Results = searchResp.Results[:n]
+	}
+	return searchResp, nil
+}
+
+// doSearch performs a single search request
+func (c *SearXNGClient) doSearch(ctx context.Context, query string) (*SearXNGResponse, error) {
+	reqURL, err := url.Parse(c.config.SearXNGURL + "/search")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("format", "json")
+	reqURL.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		errMsg := statusMessage(resp.StatusCode, body)
+		if resp.StatusCode == http.StatusForbidden {
+			// The default instance settings only allow HTML output
+			errMsg += " (enable the json format under search.formats in settings.yml)"
+		}
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("SearXNG error: %s", errMsg),
+		}
+	}
+
+	var searxResp SearXNGResponse
+	if err := json.Unmarshal(body, &searxResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &searxResp, nil
+}
+
+// ToSearchResponse converts SearXNGResponse to unified SearchResponse
+func (r *SearXNGResponse) ToSearchResponse() *SearchResponse {
+	results := make([]SearchResult, len(r.Results))
+	for i, res := range r.Results {
+		results[i] = SearchResult{
+			Title:   res.Title,
+			URL:     res.URL,
+			Content: res.Content,
+			Score:   res.Score,
+		}
+	}
+	return &SearchResponse{
+		Results: results,
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+// toolCallAccumulator assembles tool calls from streamed fragments.
+// The first fragment of a call carries its ID and name; later ones append argument text.
+type toolCallAccumulator struct {
+	order []int
+	byIdx map[int]*ToolCall
+}
+
+// add merges a fragment and returns a copy of the call assembled so far
+func (a *toolCallAccumulator) add(fragment ToolCall) ToolCall {
+	if a.byIdx == nil {
+		a.byIdx = make(map[int]*ToolCall)
+	}
+	idx := len(a.order)
+	if fragment.Index != nil {
+		idx = *fragment.Index
+	}
+
+	call, ok := a.byIdx[idx]
+	if !ok {
+		call = &ToolCall{Type: "function"}
+		a.byIdx[idx] = call
+		a.order = append(a.order, idx)
+	}
+	if fragment.ID != "" {
+		call.ID = fragment.ID
+	}
+	if fragment.Type != "" {
+		call.Type = fragment.Type
+	}
+	if fragment.Function.Name != "" {
+		call.Function.Name = fragment.Function.Name
+	}
+	call.Function.Arguments += fragment.Function.Arguments
+
+	return *call
+}
+
+// calls returns the assembled tool calls in stream order, or nil if there were none
+func (a *toolCallAccumulator) calls() []ToolCall {
+	if len(a.order) == 0 {
+		return nil
+	}
+	calls := make([]ToolCall, len(a.order))
+	for i, idx := range a.order {
+		calls[i] = *a.byIdx[idx]
+	}
+	return calls
+}
@@ -16,10 +16,13 @@ const TavilyAPIURL = "https://api.tavily.com/search"
 
 // TavilyRequest represents the Tavily search request
 type TavilyRequest struct {
-	APIKey      string `json:"api_key"`
-	Query       string `json:"query"`
-	SearchDepth string `json:"search_depth"`
-	MaxResults  int    `json:"max_results"`
+	APIKey         string   `json:"api_key"`
+	Query          string   `json:"query"`
+	SearchDepth    string   `json:"search_depth"`
+	MaxResults     int      `json:"max_results"`
+	IncludeAnswer  bool     `json:"include_answer,omitempty"`
+	IncludeDomains []string `json:"include_domains,omitempty"`
+	ExcludeDomains []string `json:"exclude_domains,omitempty"`
 }
 
 // TavilyResponse represents the Tavily search response
@@ -54,10 +57,8 @@ var _ SearchClient = (*TavilyClient)(nil)
 // NewTavilyClient creates a new Tavily client
 func NewTavilyClient(cfg *config.Config) *TavilyClient {
 	return &TavilyClient{
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		config: cfg,
+		httpClient: newHTTPClient(30 * time.Second),
+		config:     cfg,
 	}
 }
 
@@ -82,52 +83,21 @@ func (c *TavilyClient) SearchLegacy(query string) (*TavilyResponse, error) {
 
 // searchWithRetry performs search with automatic key rotation on failure
 func (c *TavilyClient) searchWithRetry(ctx context.Context, query string) (*TavilyResponse, error) {
-	if c.config.GetTavilyKeyCount() <= 1 {
+	return searchWithKeyRotation(ctx, "Tavily", c.rotateKey, func() (*TavilyResponse, error) {
 		return c.doSearch(ctx, query)
-	}
-
-	var lastErr error
-	for attempt := 0; attempt < MaxRetryAttempts; attempt++ {
-		// Check for context cancellation
-		if err := ctx.Err(); err != nil {
-			return nil, fmt.Errorf("search cancelled: %w", err)
-		}
-
-		resp, err := c.doSearch(ctx, query)
-		if err == nil {
-			return resp, nil
-		}
-		lastErr = err
-
-		apiErr, ok := err.(*APIError)
-		if !ok || !ShouldRotateKey(apiErr.StatusCode) {
-			return nil, err
-		}
-
-		if rotateErr := c.rotateKey(); rotateErr != nil {
-			return nil, fmt.Errorf("%v (no more Tavily API keys available)", err)
-		}
-
-		// Apply backoff before retry
-		if attempt < MaxRetryAttempts-1 {
-			select {
-			case <-ctx.Done():
-				return nil, fmt.Errorf("search cancelled: %w", ctx.Err())
-			case <-time.After(CalculateBackoff(attempt)):
-			}
-		}
-	}
-
-	return nil, fmt.Errorf("max retry attempts (%d) exceeded: %v", MaxRetryAttempts, lastErr)
+	})
 }
 
 // doSearch performs a single search attempt
 func (c *TavilyClient) doSearch(ctx context.Context, query string) (*TavilyResponse, error) {
 	reqBody := TavilyRequest{
-		APIKey:      c.config.TavilyAPIKey,
-		Query:       query,
-		SearchDepth: "basic",
-		MaxResults:  5,
+		APIKey:         c.config.TavilyAPIKey,
+		Query:          query,
+		SearchDepth:    "basic",
+		MaxResults:     c.config.GetSearchMaxResults(),
+		IncludeAnswer:  true,
+		IncludeDomains: c.config.IncludeDomains,
+		ExcludeDomains: c.config.ExcludeDomains,
 	}
 
 	jsonData, err := json.Marshal(reqBody)
@@ -155,13 +125,14 @@ func (c *TavilyClient) doSearch(ctx context.Context, query string) (*TavilyRespo
 
 	if resp.StatusCode != http.StatusOK {
 		var errResp TavilyErrorResponse
-		errMsg := fmt.Sprintf("status code %d", resp.StatusCode)
+		errMsg := statusMessage(resp.StatusCode, body)
 		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Detail != "" {
 			errMsg = errResp.Detail
 		}
 		return nil, &APIError{
 			StatusCode: resp.StatusCode,
 			Message:    fmt.Sprintf("Tavily API error: %s", errMsg),
+			RetryAfter: retryAfter(resp),
 		}
 	}
 
@@ -207,13 +178,19 @@ func (r *TavilyResponse) ToSearchResponse() *SearchResponse {
 
 // FormatResultsAsContext formats search results for use as LLM context
 func (r *TavilyResponse) FormatResultsAsContext() string {
-	if len(r.Results) == 0 {
+	return r.FormatResultsAsContextFrom(1)
+}
+
+// FormatResultsAsContextFrom formats results like FormatResultsAsContext, numbering
+// them from first, so they can follow earlier results the model may already cite
+func (r *TavilyResponse) FormatResultsAsContextFrom(first int) string {
+	if len(r.Results) == 0 && r.Answer == "" {
 		return ""
 	}
 
-	var result string
+	result := formatDirectAnswer(r.Answer)
 	for i, res := range r.Results {
-		result += fmt.Sprintf("[%d] %s\nURL: %s\n%s\n\n", i+1, res.Title, res.URL, res.Content)
+		result += fmt.Sprintf("[%d] %s\nURL: %s\n%s\n\n", first+i, res.Title, res.URL, res.Content)
 	}
 	return result
 }
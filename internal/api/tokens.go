@@ -0,0 +1,59 @@
+package api
+
+// Token estimation constants. The estimate is a heuristic, not a tokenizer: it is
+// meant for budgets and warnings, where being within ~20% is good enough.
+const (
+	// CharsPerToken is the rough characters-per-token ratio of English text and code
+	CharsPerToken = 4
+
+	// TokensPerMessage approximates the per-message overhead (role, separators)
+	TokensPerMessage = 4
+
+	// TokensPerImage approximates one attached image at the default detail level
+	TokensPerImage = 765
+)
+
+// EstimateTokens roughly estimates the prompt size of messages
+func EstimateTokens(messages []Message) int {
+	total := 0
+	for _, m := range messages {
+		chars := len(m.Content)
+		for _, tc := range m.ToolCalls {
+			chars += len(tc.Function.Name) + len(tc.Function.Arguments)
+		}
+		total += TokensPerMessage + (chars+CharsPerToken-1)/CharsPerToken + len(m.Images)*TokensPerImage
+	}
+	return total
+}
+
+// TrimToTokens drops the oldest messages after the system prompt until the estimate
+// fits maxTokens, returning the kept messages and how many were dropped. A tool result
+// is never kept without the call it answers, and the last message is always kept (with
+// its tool call, if it is a tool result), so the result can still exceed maxTokens.
+// messages itself is not modified.
+func TrimToTokens(messages []Message, maxTokens int) ([]Message, int) {
+	if maxTokens <= 0 || EstimateTokens(messages) <= maxTokens {
+		return messages, 0
+	}
+
+	start := 0
+	if len(messages) > 0 && messages[0].Role == "system" {
+		start = 1
+	}
+	total := EstimateTokens(messages)
+	cut := start
+	for cut < len(messages)-1 && (total > maxTokens || messages[cut].Role == "tool") {
+		total -= EstimateTokens(messages[cut : cut+1])
+		cut++
+	}
+	// A kept tool result needs the assistant message that called it
+	for cut > start && messages[cut].Role == "tool" {
+		cut--
+	}
+	if cut == start {
+		return messages, 0
+	}
+
+	kept := append(append([]Message(nil), messages[:start]...), messages[cut:]...)
+	return kept, cut - start
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	call := ToolCall{}
+	call.Function.Name = "run"     // 3 chars
+	call.Function.Arguments = "{}" // 2 chars
+	messages := []Message{
+		{Role: "user", Content: strings.Repeat("x", 40)},                   // 4 + 10
+		{Role: "assistant", ToolCalls: []ToolCall{call}},                   // 4 + 2
+		{Role: "user", Content: "hi", Images: []string{"data:image/png,"}}, // 4 + 1 + image
+	}
+	want := 14 + 6 + 5 + TokensPerImage
+	if got := EstimateTokens(messages); got != want {
+		t.Errorf("EstimateTokens() = %d, want %d", got, want)
+	}
+}
+
+func TestTrimToTokens(t *testing.T) {
+	long := strings.Repeat("x", 400) // 104 tokens with overhead
+	history := []Message{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: long},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1"}}},
+		{Role: "tool", Content: long, ToolCallID: "call_1"},
+		{Role: "assistant", Content: long},
+		{Role: "user", Content: long},
+	}
+
+	tests := []struct {
+		name        string
+		maxTokens   int
+		wantDropped int
+		wantFirst   string // role of the first message after the system prompt
+	}{
+		{"disabled", 0, 0, "user"},
+		{"within budget", 10000, 0, "user"},
+		{"drops oldest", 330, 1, "assistant"},
+		{"skips orphaned tool result", 250, 3, "assistant"},
+		{"always keeps latest message", 10, 4, "user"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, dropped := TrimToTokens(history, tt.maxTokens)
+			if dropped != tt.wantDropped || len(kept) != len(history)-dropped {
+				t.Fatalf("TrimToTokens(%d) kept %d, dropped %d; want %d dropped", tt.maxTokens, len(kept), dropped, tt.wantDropped)
+			}
+			if kept[0].Content != "sys" || kept[1].Role != tt.wantFirst {
+				t.Errorf("TrimToTokens(%d) = %+v", tt.maxTokens, kept)
+			}
+		})
+	}
+	if len(history) != 6 || history[1].Role != "user" {
+		t.Error("TrimToTokens modified its input")
+	}
+}
+
+func TestTrimToTokensKeepsToolPair(t *testing.T) {
+	long := strings.Repeat("x", 400)
+	tests := []struct {
+		name     string
+		messages []Message
+		wantLen  int
+	}{
+		{"trailing tool result", []Message{
+			{Role: "system", Content: "sys"},
+			{Role: "user", Content: long},
+			{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1"}}},
+			{Role: "tool", Content: long, ToolCallID: "call_1"},
+		}, 3},
+		{"trailing parallel tool results", []Message{
+			{Role: "system", Content: "sys"},
+			{Role: "user", Content: long},
+			{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1"}, {ID: "call_2"}}},
+			{Role: "tool", Content: long, ToolCallID: "call_1"},
+			{Role: "tool", Content: long, ToolCallID: "call_2"},
+		}, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, _ := TrimToTokens(tt.messages, 10)
+			if len(kept) != tt.wantLen {
+				t.Fatalf("TrimToTokens kept %d messages, want %d: %+v", len(kept), tt.wantLen, kept)
+			}
+			if kept[1].Role != "assistant" || len(kept[1].ToolCalls) == 0 {
+				t.Errorf("TrimToTokens dropped the tool call of a kept result: %+v", kept)
+			}
+		})
+	}
+}
+
+func TestMaxContext(t *testing.T) {
+	var sent int
+	client, cfg := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req ChatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		sent = len(req.Messages)
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+	})
+	var overflows [][3]int
+	client.SetContextOverflowCallback(func(estimated, limit, dropped int) {
+		overflows = append(overflows, [3]int{estimated, limit, dropped})
+	})
+
+	long := strings.Repeat("x", 400)
+	history := []Message{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: long},
+		{Role: "assistant", Content: long},
+		{Role: "user", Content: long},
+	}
+	cfg.MaxContextTokens = 250
+
+	if _, err := client.QueryWithHistory(history); err != nil {
+		t.Fatalf("QueryWithHistory() error = %v", err)
+	}
+	if sent != 4 || len(overflows) != 1 || overflows[0][2] != 0 {
+		t.Errorf("warn only: sent %d messages, overflows %v", sent, overflows)
+	}
+
+	cfg.TrimContext = true
+	if _, err := client.QueryWithHistory(history); err != nil {
+		t.Fatalf("QueryWithHistory() error = %v", err)
+	}
+	if sent != 3 || len(overflows) != 2 || overflows[1][2] != 1 {
+		t.Errorf("trim: sent %d messages, overflows %v", sent, overflows)
+	}
+	if len(history) != 4 {
+		t.Errorf("history has %d messages, want the caller's history untouched", len(history))
+	}
+}
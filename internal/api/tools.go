@@ -1,5 +1,14 @@
 package api
 
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
 // ExecuteCommandTool is the tool definition for command execution
 var ExecuteCommandTool = Tool{
 	Type: "function",
@@ -23,9 +32,245 @@ var ExecuteCommandTool = Tool{
 	},
 }
 
-// GetDefaultTools returns the default set of tools available to the AI
+// WriteFileTool is the tool definition for creating or replacing a file. The user
+// reviews a diff of the change before anything is written.
+var WriteFileTool = Tool{
+	Type: "function",
+	Function: Function{
+		Name:        "write_file",
+		Description: "Create a file or replace its entire contents. The user sees a diff of the change and must approve it before it is written. Prefer this over shell redirection or sed for editing files.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path of the file, relative to the user's current working directory",
+				},
+				"content": map[string]interface{}{
+					"type":        "string",
+					"description": "The complete new contents of the file",
+				},
+				"reasoning": map[string]interface{}{
+					"type":        "string",
+					"description": "Brief explanation of why this change is needed",
+				},
+			},
+			"required": []string{"path", "content", "reasoning"},
+		},
+	},
+}
+
+// WebSearchTool is the tool definition for searching the web during an agentic turn
+var WebSearchTool = Tool{
+	Type: "function",
+	Function: Function{
+		Name:        "web_search",
+		Description: "Search the web for current information and return the top results with titles, URLs and snippets. Use this when the answer depends on recent events or facts you are unsure about.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "A concise, self-contained search query (e.g., 'Go 1.24 release notes')",
+				},
+			},
+			"required": []string{"query"},
+		},
+	},
+}
+
+// webSearchToolEnabled controls whether WebSearchTool is offered to the AI
+var webSearchToolEnabled bool
+
+// EnableWebSearchTool sets whether the web_search tool is offered (requires search API keys)
+func EnableWebSearchTool(enabled bool) {
+	webSearchToolEnabled = enabled
+}
+
+// commandToolsEnabled controls whether tools that change the system are offered:
+// ExecuteCommandTool, WriteFileTool and the user-defined tools
+var commandToolsEnabled = true
+
+// EnableCommandTools sets whether tools that run commands or write files are offered (off in --safe-mode)
+func EnableCommandTools(enabled bool) {
+	commandToolsEnabled = enabled
+}
+
+// ToolStatus is a registered tool and whether it is currently offered to the AI
+type ToolStatus struct {
+	Tool    Tool
+	Custom  bool // Defined in --tools-file
+	Enabled bool
+}
+
+// RegisteredTools returns every tool the CLI knows, built-in and user-defined,
+// in the order they are offered, with whether each one is enabled
+func RegisteredTools() []ToolStatus {
+	tools := []ToolStatus{
+		{Tool: ExecuteCommandTool, Enabled: commandToolsEnabled},
+		{Tool: WriteFileTool, Enabled: commandToolsEnabled},
+		{Tool: WebSearchTool, Enabled: webSearchToolEnabled},
+	}
+	for _, t := range customTools {
+		tools = append(tools, ToolStatus{Tool: t.ToTool(), Custom: true, Enabled: commandToolsEnabled})
+	}
+	return tools
+}
+
+// GetDefaultTools returns the default set of tools available to the AI,
+// including any registered user-defined tools
 func GetDefaultTools() []Tool {
-	return []Tool{
-		ExecuteCommandTool,
+	var tools []Tool
+	for _, t := range RegisteredTools() {
+		if t.Enabled {
+			tools = append(tools, t.Tool)
+		}
+	}
+	return tools
+}
+
+// ParameterSummary lists a function's parameters as "name: type", sorted by name, with
+// "?" after optional ones, e.g. "command: string, timeout?: integer"
+func (f Function) ParameterSummary() string {
+	params, _ := f.Parameters.(map[string]interface{})
+	properties, _ := params["properties"].(map[string]interface{})
+	required := map[string]bool{}
+	switch names := params["required"].(type) {
+	case []string:
+		for _, name := range names {
+			required[name] = true
+		}
+	case []interface{}: // Decoded from a --tools-file
+		for _, name := range names {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		part := name
+		if !required[name] {
+			part += "?"
+		}
+		if prop, ok := properties[name].(map[string]interface{}); ok {
+			if typ, ok := prop["type"].(string); ok {
+				part += ": " + typ
+			}
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, ", ")
+}
+
+// CustomTool is a user-defined tool that runs a shell command template when called.
+// Placeholders like {{name}} in Command are replaced by the shell-quoted argument value.
+type CustomTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+	Command     string                 `json:"command"`
+}
+
+// customTools holds the user-defined tools registered for this process
+var customTools []CustomTool
+
+// placeholderPattern matches {{name}} placeholders in a command template
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+// LoadCustomTools reads a JSON array of tool definitions from a file
+func LoadCustomTools(path string) ([]CustomTool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tools file: %w", err)
+	}
+
+	var tools []CustomTool
+	if err := json.Unmarshal(data, &tools); err != nil {
+		return nil, fmt.Errorf("failed to parse tools file: %w", err)
+	}
+
+	seen := map[string]bool{
+		ExecuteCommandTool.Function.Name: true,
+		WriteFileTool.Function.Name:      true,
+		WebSearchTool.Function.Name:      true,
+	}
+	for i, t := range tools {
+		if t.Name == "" || t.Command == "" {
+			return nil, fmt.Errorf("tool #%d: name and command are required", i+1)
+		}
+		if seen[t.Name] {
+			return nil, fmt.Errorf("tool #%d: duplicate tool name %q", i+1, t.Name)
+		}
+		seen[t.Name] = true
+		if t.Parameters == nil {
+			tools[i].Parameters = map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			}
+		}
+	}
+	return tools, nil
+}
+
+// RegisterCustomTools makes user-defined tools available to GetDefaultTools
+func RegisterCustomTools(tools []CustomTool) {
+	customTools = tools
+}
+
+// FindCustomTool looks up a registered user-defined tool by name
+func FindCustomTool(name string) (CustomTool, bool) {
+	for _, t := range customTools {
+		if t.Name == name {
+			return t, true
+		}
 	}
+	return CustomTool{}, false
+}
+
+// ToTool converts the custom tool to an API tool definition
+func (t CustomTool) ToTool() Tool {
+	return Tool{
+		Type: "function",
+		Function: Function{
+			Name:        t.Name,
+			Description: t.Description,
+			Parameters:  t.Parameters,
+		},
+	}
+}
+
+// BuildCommand substitutes the JSON arguments from a tool call into the command template.
+// Values are shell-quoted so arguments cannot inject additional commands.
+func (t CustomTool) BuildCommand(arguments string) (string, error) {
+	args := map[string]interface{}{}
+	if strings.TrimSpace(arguments) != "" {
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return "", err
+		}
+	}
+
+	return placeholderPattern.ReplaceAllStringFunc(t.Command, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		value, ok := args[name]
+		if !ok || value == nil {
+			return "''"
+		}
+		if s, ok := value.(string); ok {
+			return shellQuote(s)
+		}
+		return shellQuote(fmt.Sprint(value))
+	}), nil
+}
+
+// shellQuote wraps s in single quotes for safe use in a POSIX shell command
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCustomToolBuildCommand(t *testing.T) {
+	tool := CustomTool{Name: "grep_code", Command: "grep -rn {{pattern}} {{ path }} --max-count={{limit}}"}
+
+	tests := []struct {
+		name      string
+		arguments string
+		want      string
+	}{
+		{"string args", `{"pattern":"TODO","path":"./cmd","limit":5}`, `grep -rn 'TODO' './cmd' --max-count='5'`},
+		{"missing arg", `{"pattern":"TODO"}`, `grep -rn 'TODO' '' --max-count=''`},
+		{"injection quoted", `{"pattern":"x'; rm -rf ~; echo '","path":".","limit":1}`, `grep -rn 'x'\''; rm -rf ~; echo '\''' '.' --max-count='1'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tool.BuildCommand(tt.arguments)
+			if err != nil {
+				t.Fatalf("BuildCommand() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("BuildCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := tool.BuildCommand("not json"); err == nil {
+		t.Error("expected error for invalid arguments")
+	}
+}
+
+func TestLoadCustomTools(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "tools.json")
+	content := `[{"name":"disk_usage","description":"Show disk usage","command":"du -sh {{path}}"}]`
+	if err := os.WriteFile(valid, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tools, err := LoadCustomTools(valid)
+	if err != nil {
+		t.Fatalf("LoadCustomTools() error = %v", err)
+	}
+	if len(tools) != 1 || tools[0].Parameters == nil {
+		t.Fatalf("unexpected tools: %+v", tools)
+	}
+
+	clash := filepath.Join(dir, "clash.json")
+	if err := os.WriteFile(clash, []byte(`[{"name":"execute_command","command":"ls"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCustomTools(clash); err == nil {
+		t.Error("expected error for tool name clashing with built-in")
+	}
+}
+
+func TestEnableCommandTools(t *testing.T) {
+	RegisterCustomTools([]CustomTool{{Name: "lint", Command: "golangci-lint run"}})
+	EnableWebSearchTool(true)
+	t.Cleanup(func() {
+		RegisterCustomTools(nil)
+		EnableWebSearchTool(false)
+		EnableCommandTools(true)
+	})
+
+	names := func() []string {
+		var names []string
+		for _, tool := range GetDefaultTools() {
+			names = append(names, tool.Function.Name)
+		}
+		return names
+	}
+
+	if got := names(); len(got) != 4 {
+		t.Errorf("default tools = %v, want execute_command, write_file, web_search and lint", got)
+	}
+	EnableCommandTools(false)
+	if got := names(); len(got) != 1 || got[0] != WebSearchTool.Function.Name {
+		t.Errorf("tools without command tools = %v, want only web_search", got)
+	}
+}
+
+func TestRegisteredTools(t *testing.T) {
+	RegisterCustomTools([]CustomTool{{Name: "lint", Command: "golangci-lint run"}})
+	EnableCommandTools(false)
+	t.Cleanup(func() {
+		RegisterCustomTools(nil)
+		EnableCommandTools(true)
+	})
+
+	tools := RegisteredTools()
+	if len(tools) != 4 {
+		t.Fatalf("RegisteredTools() returned %d tools, want 4", len(tools))
+	}
+	for _, tool := range tools {
+		wantEnabled := tool.Tool.Function.Name == WebSearchTool.Function.Name && webSearchToolEnabled
+		if tool.Enabled != wantEnabled {
+			t.Errorf("%s enabled = %v, want %v", tool.Tool.Function.Name, tool.Enabled, wantEnabled)
+		}
+		if tool.Custom != (tool.Tool.Function.Name == "lint") {
+			t.Errorf("%s custom = %v", tool.Tool.Function.Name, tool.Custom)
+		}
+	}
+}
+
+func TestParameterSummary(t *testing.T) {
+	custom := Function{Parameters: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"pattern": map[string]interface{}{"type": "string"},
+			"limit":   map[string]interface{}{"type": "integer"},
+			"path":    map[string]interface{}{},
+		},
+		"required": []interface{}{"pattern"},
+	}}
+
+	tests := []struct {
+		name string
+		fn   Function
+		want string
+	}{
+		{"built-in", ExecuteCommandTool.Function, "command: string, reasoning: string"},
+		{"optional and untyped", custom, "limit?: integer, path?, pattern: string"},
+		{"no parameters", Function{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn.ParameterSummary(); got != tt.want {
+				t.Errorf("ParameterSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
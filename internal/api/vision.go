@@ -0,0 +1,62 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ContentPart is one element of a multimodal message content array
+type ContentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+}
+
+// ImageURL references an image by URL or data URL
+type ImageURL struct {
+	URL string `json:"url"`
+}
+
+// MarshalJSON sends Content as a plain string, or as text and image parts when Images are attached
+func (m Message) MarshalJSON() ([]byte, error) {
+	type plain Message
+	if len(m.Images) == 0 {
+		return json.Marshal(plain(m))
+	}
+
+	parts := make([]ContentPart, 0, len(m.Images)+1)
+	if m.Content != "" {
+		parts = append(parts, ContentPart{Type: "text", Text: m.Content})
+	}
+	for _, url := range m.Images {
+		parts = append(parts, ContentPart{Type: "image_url", ImageURL: &ImageURL{URL: url}})
+	}
+
+	return json.Marshal(struct {
+		plain
+		Content []ContentPart `json:"content"`
+	}{plain: plain(m), Content: parts})
+}
+
+// ImageDataURL encodes raw image bytes as a base64 data URL.
+// Returns an error if the data is not a recognised image format.
+func ImageDataURL(data []byte) (string, error) {
+	mimeType := http.DetectContentType(data)
+	if !strings.HasPrefix(mimeType, "image/") {
+		return "", fmt.Errorf("data is not an image (detected %s)", mimeType)
+	}
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// hasImages reports whether any message carries image attachments
+func hasImages(messages []Message) bool {
+	for _, m := range messages {
+		if len(m.Images) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMessageMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  Message
+		want string
+	}{
+		{
+			name: "text only",
+			msg:  Message{Role: "user", Content: "hi"},
+			want: `{"role":"user","content":"hi"}`,
+		},
+		{
+			name: "text and image",
+			msg:  Message{Role: "user", Content: "what is this?", Images: []string{"data:image/png;base64,AAAA"}},
+			want: `{"role":"user","content":[{"type":"text","text":"what is this?"},{"type":"image_url","image_url":{"url":"data:image/png;base64,AAAA"}}]}`,
+		},
+		{
+			name: "image only",
+			msg:  Message{Role: "user", Images: []string{"data:image/png;base64,AAAA"}},
+			want: `{"role":"user","content":[{"type":"image_url","image_url":{"url":"data:image/png;base64,AAAA"}}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.msg)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageDataURL(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")
+	url, err := ImageDataURL(png)
+	if err != nil {
+		t.Fatalf("ImageDataURL() error = %v", err)
+	}
+	if !strings.HasPrefix(url, "data:image/png;base64,") {
+		t.Errorf("ImageDataURL() = %q, want data:image/png prefix", url)
+	}
+
+	if _, err := ImageDataURL([]byte("plain text")); err == nil {
+		t.Error("ImageDataURL() expected error for non-image data")
+	}
+}
@@ -1,44 +1,182 @@
 package config
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/keyvault"
 )
 
 // Environment variable names
 const (
-	EnvAzureEndpoint     = "AZURE_OPENAI_ENDPOINT"
-	EnvAzureAPIKey       = "AZURE_OPENAI_API_KEY"
-	EnvAzureModels       = "AZURE_OPENAI_MODELS"
-	EnvTavilyAPIKeys     = "TAVILY_API_KEYS"
-	EnvLinkupAPIKeys     = "LINKUP_API_KEYS"
-	EnvBraveAPIKeys      = "BRAVE_API_KEYS"
-	EnvWebSearchProvider = "WEB_SEARCH_PROVIDER"
+	EnvAzureEndpoint       = "AZURE_OPENAI_ENDPOINT"
+	EnvAzureStreamEndpoint = "AZURE_OPENAI_STREAM_ENDPOINT"
+	EnvAzureAPIKey         = "AZURE_OPENAI_API_KEY"
+	EnvAzureAPIKeys        = "AZURE_OPENAI_API_KEYS"
+	EnvAzureModels         = "AZURE_OPENAI_MODELS"
+	EnvSystemPrompt        = "AZURE_OPENAI_SYSTEM_PROMPT"
+	EnvUser                = "AZURE_OPENAI_USER"
+	EnvAPIVersion          = "AZURE_OPENAI_API_VERSION"
+	EnvAuthMode            = "AZURE_OPENAI_AUTH_MODE"
+	EnvKeyVaultURL         = "AZURE_KEYVAULT_URL"
+	EnvEndpointSecret      = "AZURE_ENDPOINT_SECRET"
+	EnvKeySecret           = "AZURE_KEY_SECRET"
+	EnvTavilyAPIKeys       = "TAVILY_API_KEYS"
+	EnvLinkupAPIKeys       = "LINKUP_API_KEYS"
+	EnvBraveAPIKeys        = "BRAVE_API_KEYS"
+	EnvPerplexityAPIKeys   = "PERPLEXITY_API_KEYS"
+	EnvSearXNGURL          = "SEARXNG_URL"
+	EnvWebSearchProvider   = "WEB_SEARCH_PROVIDER"
+	EnvWebSearchPriority   = "WEB_SEARCH_PRIORITY"
+	EnvAllowlistFile       = "AZURE_AI_ALLOWLIST_FILE"
+	EnvDenylistFile        = "AZURE_AI_DENYLIST_FILE"
+	EnvDefaultFlags        = "AZURE_AI_FLAGS"
+	EnvQuotaPeriod         = "AZURE_AI_QUOTA_PERIOD"
+	EnvQuotaResetDay       = "AZURE_AI_QUOTA_RESET_DAY"
 )
 
 // Defaults
 const (
-	DefaultModel          = "gpt-5.1-chat"
-	DefaultSystemMessage  = "Be precise and concise."
-	DefaultSearchProvider = "tavily"
+	DefaultModel            = "gpt-5.1-chat"
+	DefaultSystemMessage    = "Be precise and concise."
+	DefaultSearchProvider   = "tavily"
+	DefaultMaxSearches      = 3
+	DefaultSearchResults    = 5
+	MaxSearchResults        = 20 // Upper bound for --results to keep the LLM context reasonable
+	DefaultPromptPrefix     = "> "
+	DefaultMaxResponseBytes = 4 << 20 // 4 MiB of streamed content per response
+	DefaultExecTimeout      = 30 * time.Second
+	AppDirName              = "azure-ai"
+	AllowlistFileName       = "allowlist"
+	DenylistFileName        = "denylist"
+	QuotaFileName           = "quota.json"
+	SessionsDirName         = "sessions"
+	ConfigFileName          = "config.yaml"
 )
 
 // Errors
 var (
-	ErrEndpointNotFound      = errors.New("Azure endpoint not found. Set AZURE_OPENAI_ENDPOINT environment variable")
-	ErrAPIKeyNotFound        = errors.New("Azure API key not found. Set AZURE_OPENAI_API_KEY environment variable")
+	ErrEndpointNotFound      = errors.New("Azure endpoint not found. Set AZURE_OPENAI_ENDPOINT environment variable or endpoint in config.yaml")
+	ErrAPIKeyNotFound        = errors.New("Azure API key not found. Set AZURE_OPENAI_API_KEY environment variable or api_key in config.yaml")
 	ErrModelNotFound         = errors.New("model not found. Set AZURE_OPENAI_MODEL or use --model flag")
 	ErrInvalidModel          = errors.New("invalid model specified")
 	ErrNoAvailableKeys       = errors.New("all API keys exhausted")
-	ErrWebSearchKeyNotFound  = errors.New("web search API key not found. Set TAVILY_API_KEYS, LINKUP_API_KEYS, or BRAVE_API_KEYS to use --web flag")
-	ErrInvalidSearchProvider = errors.New("invalid search provider. Use 'tavily', 'linkup', or 'brave'")
+	ErrWebSearchKeyNotFound  = errors.New("web search API key not found. Set TAVILY_API_KEYS, LINKUP_API_KEYS, BRAVE_API_KEYS, or PERPLEXITY_API_KEYS (or SEARXNG_URL) to use --web flag")
+	ErrInvalidSearchProvider = errors.New("invalid search provider. Use 'tavily', 'linkup', 'brave', 'perplexity', 'searxng', or 'all'")
+	ErrInvalidTemperature    = errors.New("temperature must be between 0 and 2")
+	ErrInvalidMaxTokens      = errors.New("max tokens must not be negative")
+	ErrInvalidMaxResponse    = errors.New("max response bytes must not be negative")
+	ErrInvalidOptimizeMode   = errors.New("invalid optimize mode. Use 'first', 'followups', 'always', or 'never'")
+	ErrInvalidExecTimeout    = errors.New("exec timeout must not be negative")
+	ErrSystemPromptConflict  = errors.New("use either --system or --system-file, not both")
+	ErrInvalidExtraBody      = errors.New("extra body must be a JSON object")
+	ErrInvalidMaxContext     = errors.New("max context must not be negative")
+	ErrInvalidAuthMode       = errors.New("invalid auth mode. Use 'bearer' or 'api-key'")
+	ErrInvalidChoices        = errors.New("n must not be negative")
+	ErrInvalidMinAnswerWords = errors.New("min answer words must not be negative")
+	ErrInvalidAnswerLang     = errors.New("unsupported answer language")
+	ErrUnterminatedQuote     = errors.New("unterminated quote")
+)
+
+// SearchKeyEnvVars maps each search provider to the environment variable holding its API keys
+var SearchKeyEnvVars = map[string]string{
+	"tavily":     EnvTavilyAPIKeys,
+	"linkup":     EnvLinkupAPIKeys,
+	"brave":      EnvBraveAPIKeys,
+	"perplexity": EnvPerplexityAPIKeys,
+}
+
+// Query optimization modes for --optimize: which interactive web searches are
+// first rewritten by the model into a standalone search query
+const (
+	OptimizeFirst     = "first"     // Only the first search of a session
+	OptimizeFollowups = "followups" // Only searches after the first (default)
+	OptimizeAlways    = "always"
+	OptimizeNever     = "never"
+)
+
+// OptimizeModes lists the valid --optimize values
+var OptimizeModes = []string{OptimizeFirst, OptimizeFollowups, OptimizeAlways, OptimizeNever}
+
+// Azure authentication modes for --auth-mode: how the API key is sent
+const (
+	AuthBearer = "bearer"  // Authorization: Bearer <key>, for the v1 API (default)
+	AuthAPIKey = "api-key" // api-key: <key>, for classic resource keys (default with --api-version)
 )
 
+// AnswerLanguages maps the --answer-lang codes to the language named in the instruction
+var AnswerLanguages = map[string]string{
+	"ar":    "Arabic",
+	"cs":    "Czech",
+	"da":    "Danish",
+	"de":    "German",
+	"el":    "Greek",
+	"en":    "English",
+	"es":    "Spanish",
+	"fi":    "Finnish",
+	"fr":    "French",
+	"he":    "Hebrew",
+	"hi":    "Hindi",
+	"hu":    "Hungarian",
+	"id":    "Indonesian",
+	"it":    "Italian",
+	"ja":    "Japanese",
+	"ko":    "Korean",
+	"nl":    "Dutch",
+	"no":    "Norwegian",
+	"pl":    "Polish",
+	"pt":    "Portuguese",
+	"pt-br": "Brazilian Portuguese",
+	"ro":    "Romanian",
+	"ru":    "Russian",
+	"sv":    "Swedish",
+	"th":    "Thai",
+	"tr":    "Turkish",
+	"uk":    "Ukrainian",
+	"vi":    "Vietnamese",
+	"zh":    "Simplified Chinese",
+	"zh-tw": "Traditional Chinese",
+}
+
+// SearchProviders lists the supported web search providers
+var SearchProviders = []string{"tavily", "linkup", "brave", "perplexity", "searxng"}
+
+// SearchProviderAll searches every configured result provider at once and merges the results
+const SearchProviderAll = "all"
+
+// IsAnswerProvider reports whether a provider writes a grounded answer itself
+// (Perplexity Sonar), so one-shot web queries can skip the separate Azure call
+func IsAnswerProvider(name string) bool {
+	return name == "perplexity"
+}
+
+// IsValidSearchProvider checks if the given name is a supported web search provider or "all"
+func IsValidSearchProvider(name string) bool {
+	if name == SearchProviderAll {
+		return true
+	}
+	for _, p := range SearchProviders {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
 // Error codes that should trigger key rotation
 var RotatableErrorCodes = []int{401, 403, 429}
 
+// Error codes that mean a model is unavailable and a fallback model should be tried
+var ModelFallbackErrorCodes = []int{404, 429}
+
 // KeyRotator manages a pool of API keys with rotation support
 type KeyRotator struct {
 	keys       []string
@@ -69,6 +207,11 @@ func (kr *KeyRotator) GetKeyCount() int {
 	return len(kr.keys)
 }
 
+// GetKeys returns all configured keys in rotation order
+func (kr *KeyRotator) GetKeys() []string {
+	return kr.keys
+}
+
 // GetCurrentIndex returns the current key index (0-based)
 func (kr *KeyRotator) GetCurrentIndex() int {
 	return kr.currentIdx
@@ -112,77 +255,393 @@ func getKeysFromEnv(envVar string) []string {
 
 // Config holds the application configuration
 type Config struct {
-	// Azure OpenAI (single key)
-	AzureEndpoint   string
-	AzureAPIKey     string
-	Model           string
-	AvailableModels []string
+	// Azure OpenAI. AzureAPIKey is the key in use; AzureKeys rotates through
+	// AZURE_OPENAI_API_KEYS, or holds just the single AZURE_OPENAI_API_KEY.
+	AzureEndpoint       string
+	AzureStreamEndpoint string // Optional endpoint used only for streaming requests
+	AzureAPIKey         string
+	AzureKeys           *KeyRotator
+	Model               string
+	AvailableModels     []string
+	FallbackModels      []string // Tried in order when the primary model is unavailable
 
 	// Key rotators for search providers
-	TavilyKeys *KeyRotator
-	LinkupKeys *KeyRotator
-	BraveKeys  *KeyRotator
+	TavilyKeys     *KeyRotator
+	LinkupKeys     *KeyRotator
+	BraveKeys      *KeyRotator
+	PerplexityKeys *KeyRotator
 
 	// Legacy fields for backward compatibility (used by API clients)
-	TavilyAPIKey        string
-	TavilyAPIKeys       []string
-	TavilyCurrentKeyIdx int
-	LinkupAPIKey        string
-	LinkupAPIKeys       []string
-	LinkupCurrentKeyIdx int
-	BraveAPIKey         string
-	BraveAPIKeys        []string
-	BraveCurrentKeyIdx  int
+	TavilyAPIKey            string
+	TavilyAPIKeys           []string
+	TavilyCurrentKeyIdx     int
+	LinkupAPIKey            string
+	LinkupAPIKeys           []string
+	LinkupCurrentKeyIdx     int
+	BraveAPIKey             string
+	BraveAPIKeys            []string
+	BraveCurrentKeyIdx      int
+	PerplexityAPIKey        string
+	PerplexityCurrentKeyIdx int
+	SearXNGURL              string // Base URL of a self-hosted SearXNG instance (no keys)
 
 	// Web search provider selection
-	WebSearchProvider string // "tavily", "linkup", or "brave"
+	WebSearchProvider  string   // "tavily", "linkup", "brave", "perplexity", "searxng", or "all"
+	MaxSearches        int      // Maximum web_search tool calls per interactive turn
+	SearchMaxResults   int      // Results requested per web search (default 5, clamped to 20)
+	ProviderPriority   []string // Auto-detect order when no provider is set
+	IncludeDomains     []string // Only keep web search results from these domains
+	ExcludeDomains     []string // Drop web search results from these domains
+	SmartWeb           bool     // Ask the model whether a follow-up needs a new search before searching
+	AllowUnlistedModel bool     // Warn instead of failing when the model is not in AZURE_OPENAI_MODELS
+	Plan               bool     // Have the model propose a plan for approval before its first tool-using turn
+	Optimize           string   // Which interactive searches get an optimized query (see OptimizeModes)
+	SearchIntent       bool     // One JSON model call decides whether to search and the query, replacing Optimize and SmartWeb
 
 	// Flags
-	Stream      bool
-	Render      bool
-	Usage       bool
-	WebSearch   bool
-	Citations   bool // Show citations/sources from web search
-	Interactive bool // Interactive chat mode
+	Stream          bool
+	Render          bool
+	Usage           bool
+	WebSearch       bool
+	Citations       bool // Show citations/sources from web search
+	AlwaysCite      bool // Show citations even when the answer has no [n] markers
+	ShowSearchQuery bool // Show the query actually sent to the search provider with citations
+	ShortURLs       bool // Shorten long citation URLs for display (full URL kept behind a hyperlink)
+	PickSources     bool // On a terminal, choose which search results the model sees
+	SafeMode        bool // Never let the AI run commands: no execute_command or custom tools
+	DryRun          bool // Show the commands and file writes the AI asks for without running them
+	Interactive     bool // Interactive chat mode
+	ShowToolCalls   bool // Show tool calls (live while streaming) before they run
+	OnlySources     bool // Print search results without asking the model
+	FailEmpty       bool // Exit non-zero when --only-sources finds no results
+	JSON            bool // Print machine-readable JSON on stdout
+
+	// Structured output: JSONMode asks for any JSON object, JSONSchemaFile for
+	// answers matching the schema in that file
+	JSONMode       bool
+	JSONSchemaFile string
+
+	// OutputFile also receives each answer as unrendered markdown; an existing
+	// file is only replaced with Force
+	OutputFile string
+	Force      bool
+
+	// Timing prints a per-phase elapsed time breakdown to stderr
+	Timing bool
+
+	// DebugStream copies raw streaming SSE lines to stderr as they arrive
+	DebugStream bool
+
+	// ShowPrompt prints the final system prompt and user message to stderr before sending
+	ShowPrompt bool
+
+	// Bare prints only the answer on stdout: no spinner, notices, or rendering
+	Bare bool
+
+	// QuietNotices hides stderr status notices (searching, key rotation) but keeps errors
+	QuietNotices bool
+
+	// NoColor prints plain output: no colors, rendering, or spinner
+	NoColor bool
+
+	// Answer length budget (best-effort instruction plus a hard trim; 0 = unlimited)
+	MaxWords int
+	MaxChars int
+
+	// AnswerLang is an AnswerLanguages code the answer must be written in; "" follows the question
+	AnswerLang string
+
+	// Generation parameters (0 = omit and let Azure use the model default)
+	Temperature float64
+	MaxTokens   int
+	Choices     int  // --n: alternative answers to a one-shot query (0 or 1 = one)
+	RetryEmpty  bool // Ask once more when an answer is empty
+	CountOnly   bool // Print the prompt's token count instead of generating an answer
+
+	// MinAnswerWords makes RetryEmpty also ask again for answers shorter than this many words (0 = only empty)
+	MinAnswerWords int
+
+	// APIVersion selects the classic /openai/deployments/{model}/chat/completions
+	// path with this api-version; "" uses the /openai/v1 path
+	APIVersion string
+
+	// AuthMode is how the API key is sent (AuthBearer or AuthAPIKey); Validate
+	// defaults it to AuthAPIKey with an APIVersion and AuthBearer otherwise
+	AuthMode string
+
+	// User is a stable end-user identifier sent with every request, for Azure abuse
+	// monitoring and attribution on shared deployments ("" = omitted)
+	User string
+
+	// ExtraBody is a JSON object (--extra-body) whose fields are merged into every
+	// chat request, for API parameters the client does not know; Validate parses
+	// it into ExtraBodyFields
+	ExtraBody       string
+	ExtraBodyFields map[string]json.RawMessage
+
+	// MaxResponseBytes stops a streamed response that grows past this many bytes,
+	// keeping what arrived (0 = unlimited)
+	MaxResponseBytes int
+
+	// MaxHistoryTokens auto-summarizes the oldest interactive turns once the
+	// estimated history exceeds this many tokens (0 = never)
+	MaxHistoryTokens int
+
+	// MaxContextTokens warns before any request whose estimated prompt exceeds this
+	// many tokens (0 = never); with TrimContext the oldest messages are left out
+	// of that request so it fits. Interactive history is always kept within it.
+	MaxContextTokens int
+	TrimContext      bool
+
+	// SystemPrompt replaces DefaultSystemMessage; it is loaded from --system,
+	// --system-file, or AZURE_OPENAI_SYSTEM_PROMPT by Validate
+	SystemPrompt     string
+	SystemPromptFile string
+
+	// PromptPrefix is the interactive prompt template; {model}, {provider}
+	// and {web} are replaced with the current session state
+	PromptPrefix string
+
+	// Command execution
+	AllowlistFile  string // File of always-allowed commands or prefixes (trailing "*")
+	DenylistFile   string // File of always-blocked commands or prefixes, written by /deny
+	ToolsFile      string // JSON file of additional tool definitions
+	NoCommandCache bool   // Re-run repeated read-only commands instead of reusing this turn's result
+
+	// SummarizeToolOutput has the model summarize command output larger than this
+	// many bytes before it is sent back as a tool result (0 = never)
+	SummarizeToolOutput int
+	SummaryModel        string // Deployment that writes those summaries ("" = Model)
+
+	// ExecTimeout limits how long a command run for the AI may take (0 = executor default)
+	ExecTimeout time.Duration
+
+	// ConfigFile is the path of the loaded config file; its settings apply
+	// only where flags and environment variables leave a value unset
+	ConfigFile string
+	fileValues map[string]string
+	fileErr    error // Deferred error from the config file probed by NewConfig
+
+	// KeyVaultURL is the vault AZURE_ENDPOINT_SECRET and AZURE_KEY_SECRET are read from
+	KeyVaultURL string
+	keyVault    *keyvault.Client
 }
 
-// NewConfig creates a new Config with defaults
+// NewConfig creates a new Config with defaults, loading the first config
+// file found in ConfigFilePaths. A malformed file is reported by Validate.
 func NewConfig() *Config {
-	return &Config{}
+	c := &Config{}
+	for _, path := range ConfigFilePaths() {
+		if _, err := os.Stat(path); err == nil {
+			c.fileErr = c.LoadFile(path)
+			break
+		}
+	}
+	return c
 }
 
 // Validate validates the configuration and loads from environment
 func (c *Config) Validate() error {
+	if c.fileErr != nil {
+		return c.fileErr
+	}
+
+	// --only-sources never calls Azure, so its settings are optional
+	if !c.OnlySources {
+		if err := c.validateAzure(); err != nil {
+			return err
+		}
+	}
+
+	if err := c.loadSystemPrompt(); err != nil {
+		return err
+	}
+
+	c.LoadSearchKeys()
+
+	// Set web search provider (default to tavily, or auto-detect based on available keys)
+	if c.WebSearchProvider == "" {
+		c.WebSearchProvider = os.Getenv(EnvWebSearchProvider)
+	}
+	if c.WebSearchProvider == "" {
+		c.WebSearchProvider = c.fileValue(ConfigFileKeyProvider)
+	}
+	if c.WebSearchProvider == "" {
+		// Auto-detect: first provider with keys, in priority order (default tavily, linkup, brave, perplexity, searxng)
+		priority, err := c.providerPriority()
+		if err != nil {
+			return err
+		}
+		c.WebSearchProvider = DefaultSearchProvider
+		for _, p := range priority {
+			if c.HasSearchKeys(p) {
+				c.WebSearchProvider = p
+				break
+			}
+		}
+	}
+
+	// Validate provider
+	if !IsValidSearchProvider(c.WebSearchProvider) {
+		return ErrInvalidSearchProvider
+	}
+
+	// Validate web search keys if web search is requested
+	if c.WebSearch && !c.HasSearchKeys(c.WebSearchProvider) {
+		return ErrWebSearchKeyNotFound
+	}
+
+	if c.Optimize == "" {
+		c.Optimize = OptimizeFollowups
+	}
+	c.Optimize = strings.ToLower(c.Optimize)
+	if !IsValidOptimizeMode(c.Optimize) {
+		return fmt.Errorf("%w: %s", ErrInvalidOptimizeMode, c.Optimize)
+	}
+
+	return nil
+}
+
+// IsValidOptimizeMode checks if the given name is a supported --optimize mode
+func IsValidOptimizeMode(mode string) bool {
+	for _, m := range OptimizeModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// providerPriority returns the provider auto-detect order from --provider-priority or WEB_SEARCH_PRIORITY
+func (c *Config) providerPriority() ([]string, error) {
+	priority := c.ProviderPriority
+	if len(priority) == 0 {
+		if env := os.Getenv(EnvWebSearchPriority); env != "" {
+			priority = strings.Split(env, ",")
+		}
+	}
+	if len(priority) == 0 {
+		return SearchProviders, nil
+	}
+
+	var order []string
+	for _, p := range priority {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+		if !IsValidSearchProvider(p) {
+			return nil, fmt.Errorf("%w (priority): %s", ErrInvalidSearchProvider, p)
+		}
+		order = append(order, p)
+	}
+	return order, nil
+}
+
+// LoadAvailableModels reads the configured model list from AZURE_OPENAI_MODELS,
+// falling back to models in the config file
+func (c *Config) LoadAvailableModels() {
+	c.AvailableModels = nil
+	modelsEnv := os.Getenv(EnvAzureModels)
+	if modelsEnv == "" {
+		modelsEnv = c.fileValue(ConfigFileKeyModels)
+	}
+	if modelsEnv != "" {
+		models := strings.Split(modelsEnv, ",")
+		for _, m := range models {
+			m = strings.TrimSpace(m)
+			if m != "" {
+				c.AvailableModels = append(c.AvailableModels, m)
+			}
+		}
+	}
+}
+
+// validateAzure loads and validates the Azure endpoint, key, and models
+func (c *Config) validateAzure() error {
 	// Load Azure endpoint
 	if c.AzureEndpoint == "" {
 		c.AzureEndpoint = os.Getenv(EnvAzureEndpoint)
 	}
+	if c.AzureEndpoint == "" {
+		endpoint, err := c.keyVaultSecret(EnvEndpointSecret)
+		if err != nil {
+			return err
+		}
+		c.AzureEndpoint = endpoint
+	}
+	if c.AzureEndpoint == "" {
+		c.AzureEndpoint = c.fileValue(ConfigFileKeyEndpoint)
+	}
 	if c.AzureEndpoint == "" {
 		return ErrEndpointNotFound
 	}
 	// Remove trailing slash
 	c.AzureEndpoint = strings.TrimSuffix(c.AzureEndpoint, "/")
 
-	// Load Azure API key (single key)
+	// Load optional streaming endpoint override
+	if c.AzureStreamEndpoint == "" {
+		c.AzureStreamEndpoint = os.Getenv(EnvAzureStreamEndpoint)
+	}
+	c.AzureStreamEndpoint = strings.TrimSuffix(c.AzureStreamEndpoint, "/")
+
+	// Load optional API version, which switches to deployment-style URLs
+	if c.APIVersion == "" {
+		c.APIVersion = strings.TrimSpace(os.Getenv(EnvAPIVersion))
+	}
+
+	// Load the auth mode, defaulting to what the chosen API path expects
+	if c.AuthMode == "" {
+		c.AuthMode = strings.TrimSpace(os.Getenv(EnvAuthMode))
+	}
+	c.AuthMode = strings.ToLower(c.AuthMode)
+	switch c.AuthMode {
+	case "":
+		c.AuthMode = AuthBearer
+		if c.APIVersion != "" {
+			c.AuthMode = AuthAPIKey
+		}
+	case AuthBearer, AuthAPIKey:
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidAuthMode, c.AuthMode)
+	}
+
+	// Load optional end-user identifier for abuse monitoring
+	if c.User == "" {
+		c.User = strings.TrimSpace(os.Getenv(EnvUser))
+	}
+
+	// Load Azure API keys: the AZURE_OPENAI_API_KEYS pool, else the single key
+	c.AzureKeys = NewKeyRotator(EnvAzureAPIKeys)
+	if c.AzureAPIKey == "" {
+		c.AzureAPIKey = c.AzureKeys.GetCurrentKey()
+	}
 	if c.AzureAPIKey == "" {
 		c.AzureAPIKey = strings.TrimSpace(os.Getenv(EnvAzureAPIKey))
 	}
+	if c.AzureAPIKey == "" {
+		key, err := c.keyVaultSecret(EnvKeySecret)
+		if err != nil {
+			return err
+		}
+		c.AzureAPIKey = strings.TrimSpace(key)
+	}
+	if c.AzureAPIKey == "" {
+		c.AzureAPIKey = c.fileValue(ConfigFileKeyAPIKey)
+	}
+	if !c.AzureKeys.HasKeys() && c.AzureAPIKey != "" {
+		c.AzureKeys = &KeyRotator{keys: []string{c.AzureAPIKey}, currentKey: c.AzureAPIKey}
+	}
 	if c.AzureAPIKey == "" {
 		return ErrAPIKeyNotFound
 	}
 
-	// Load available models
-	if modelsEnv := os.Getenv(EnvAzureModels); modelsEnv != "" {
-		models := strings.Split(modelsEnv, ",")
-		for _, m := range models {
-			m = strings.TrimSpace(m)
-			if m != "" {
-				c.AvailableModels = append(c.AvailableModels, m)
-			}
-		}
-	}
+	c.LoadAvailableModels()
 
-	// Load default model
+	// Load default model: --model, then the config file, then the first configured model
+	if c.Model == "" {
+		c.Model = c.fileValue(ConfigFileKeyModel)
+	}
 	if c.Model == "" && len(c.AvailableModels) > 0 {
 		c.Model = c.AvailableModels[0]
 	}
@@ -190,57 +649,138 @@ func (c *Config) Validate() error {
 		c.Model = DefaultModel
 	}
 
-	// Validate model if available models are configured
-	if len(c.AvailableModels) > 0 && !c.ValidateModel(c.Model) {
+	// Validate model if available models are configured (--allow-unlisted-model
+	// leaves it to the caller to warn instead)
+	if c.IsUnlistedModel(c.Model) && !c.AllowUnlistedModel {
 		return fmt.Errorf("%w: %s. Available: %s", ErrInvalidModel, c.Model, c.GetAvailableModelsString())
 	}
 
-	// Initialize key rotators
-	c.TavilyKeys = NewKeyRotator(EnvTavilyAPIKeys)
-	c.LinkupKeys = NewKeyRotator(EnvLinkupAPIKeys)
-	c.BraveKeys = NewKeyRotator(EnvBraveAPIKeys)
-
-	// Sync legacy fields for backward compatibility
-	c.syncLegacyFields()
-
-	// Set web search provider (default to tavily, or auto-detect based on available keys)
-	if c.WebSearchProvider == "" {
-		c.WebSearchProvider = os.Getenv(EnvWebSearchProvider)
-	}
-	if c.WebSearchProvider == "" {
-		// Auto-detect: prefer tavily if available, then linkup, then brave
-		if c.TavilyKeys.HasKeys() {
-			c.WebSearchProvider = "tavily"
-		} else if c.LinkupKeys.HasKeys() {
-			c.WebSearchProvider = "linkup"
-		} else if c.BraveKeys.HasKeys() {
-			c.WebSearchProvider = "brave"
-		} else {
-			c.WebSearchProvider = DefaultSearchProvider
+	// Validate fallback models the same way
+	var fallbacks []string
+	for _, m := range c.FallbackModels {
+		m = strings.TrimSpace(m)
+		if m == "" {
+			continue
 		}
+		if !c.ValidateModel(m) && !c.AllowUnlistedModel {
+			return fmt.Errorf("%w (fallback): %s. Available: %s", ErrInvalidModel, m, c.GetAvailableModelsString())
+		}
+		fallbacks = append(fallbacks, m)
 	}
+	c.FallbackModels = fallbacks
 
-	// Validate provider
-	if c.WebSearchProvider != "tavily" && c.WebSearchProvider != "linkup" && c.WebSearchProvider != "brave" {
-		return ErrInvalidSearchProvider
+	if c.Temperature < 0 || c.Temperature > 2 {
+		return fmt.Errorf("%w: %g", ErrInvalidTemperature, c.Temperature)
 	}
-
-	// Validate web search keys if web search is requested
-	if c.WebSearch {
-		if c.WebSearchProvider == "tavily" && !c.TavilyKeys.HasKeys() {
-			return ErrWebSearchKeyNotFound
+	if c.MaxTokens < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidMaxTokens, c.MaxTokens)
+	}
+	if c.AnswerLang != "" {
+		c.AnswerLang = strings.ReplaceAll(strings.ToLower(c.AnswerLang), "_", "-")
+		if _, ok := AnswerLanguages[c.AnswerLang]; !ok {
+			codes := make([]string, 0, len(AnswerLanguages))
+			for code := range AnswerLanguages {
+				codes = append(codes, code)
+			}
+			sort.Strings(codes)
+			return fmt.Errorf("%w: %s (use one of: %s)", ErrInvalidAnswerLang, c.AnswerLang, strings.Join(codes, ", "))
 		}
-		if c.WebSearchProvider == "linkup" && !c.LinkupKeys.HasKeys() {
-			return ErrWebSearchKeyNotFound
+	}
+	if c.Choices < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidChoices, c.Choices)
+	}
+	if c.MinAnswerWords < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidMinAnswerWords, c.MinAnswerWords)
+	}
+	if c.MaxResponseBytes < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidMaxResponse, c.MaxResponseBytes)
+	}
+	if c.ExecTimeout < 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidExecTimeout, c.ExecTimeout)
+	}
+	if c.MaxContextTokens < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidMaxContext, c.MaxContextTokens)
+	}
+	if c.ExtraBody != "" {
+		c.ExtraBodyFields = nil
+		if err := json.Unmarshal([]byte(c.ExtraBody), &c.ExtraBodyFields); err != nil || c.ExtraBodyFields == nil {
+			return fmt.Errorf("%w: %s", ErrInvalidExtraBody, c.ExtraBody)
 		}
-		if c.WebSearchProvider == "brave" && !c.BraveKeys.HasKeys() {
-			return ErrWebSearchKeyNotFound
+		for _, key := range []string{"messages", "stream"} {
+			if _, ok := c.ExtraBodyFields[key]; ok {
+				return fmt.Errorf("%w: %q is set by the client and cannot be overridden", ErrInvalidExtraBody, key)
+			}
 		}
 	}
 
 	return nil
 }
 
+// keyVaultSecret reads the secret named by the environment variable envName from
+// the Key Vault, or returns "" when no vault or secret name is configured
+func (c *Config) keyVaultSecret(envName string) (string, error) {
+	name := strings.TrimSpace(os.Getenv(envName))
+	if c.KeyVaultURL == "" {
+		c.KeyVaultURL = os.Getenv(EnvKeyVaultURL)
+	}
+	if c.KeyVaultURL == "" || name == "" {
+		return "", nil
+	}
+	if c.keyVault == nil {
+		c.keyVault = keyvault.NewClient(c.KeyVaultURL, keyvault.DefaultCredential())
+	}
+	value, err := c.keyVault.GetSecret(context.Background(), name)
+	if err != nil {
+		return "", fmt.Errorf("Key Vault secret %s (%s): %w", name, envName, err)
+	}
+	return value, nil
+}
+
+// HasSearchKeys returns true if API keys are configured for the given search provider;
+// for "all", if any provider it combines has keys
+func (c *Config) HasSearchKeys(provider string) bool {
+	switch provider {
+	case SearchProviderAll:
+		return len(c.MultiSearchProviders()) > 0
+	case "tavily":
+		return c.TavilyKeys != nil && c.TavilyKeys.HasKeys()
+	case "linkup":
+		return c.LinkupKeys != nil && c.LinkupKeys.HasKeys()
+	case "brave":
+		return c.BraveKeys != nil && c.BraveKeys.HasKeys()
+	case "perplexity":
+		return c.PerplexityKeys != nil && c.PerplexityKeys.HasKeys()
+	case "searxng":
+		// SearXNG needs no key, only the instance URL
+		return c.SearXNGURL != ""
+	}
+	return false
+}
+
+// MultiSearchProviders returns the providers --provider all searches: every provider with
+// keys that returns a result list. Answer providers (Perplexity) are left out.
+func (c *Config) MultiSearchProviders() []string {
+	var providers []string
+	for _, p := range SearchProviders {
+		if !IsAnswerProvider(p) && c.HasSearchKeys(p) {
+			providers = append(providers, p)
+		}
+	}
+	return providers
+}
+
+// LoadSearchKeys initializes the search provider key rotators from the environment
+func (c *Config) LoadSearchKeys() {
+	c.TavilyKeys = NewKeyRotator(EnvTavilyAPIKeys)
+	c.LinkupKeys = NewKeyRotator(EnvLinkupAPIKeys)
+	c.BraveKeys = NewKeyRotator(EnvBraveAPIKeys)
+	c.PerplexityKeys = NewKeyRotator(EnvPerplexityAPIKeys)
+	c.SearXNGURL = strings.TrimSuffix(strings.TrimSpace(os.Getenv(EnvSearXNGURL)), "/")
+
+	// Sync legacy fields for backward compatibility
+	c.syncLegacyFields()
+}
+
 // syncLegacyFields synchronizes KeyRotator state to legacy fields for backward compatibility
 func (c *Config) syncLegacyFields() {
 	// Tavily
@@ -257,12 +797,184 @@ func (c *Config) syncLegacyFields() {
 	c.BraveAPIKey = c.BraveKeys.GetCurrentKey()
 	c.BraveAPIKeys = c.BraveKeys.keys
 	c.BraveCurrentKeyIdx = c.BraveKeys.GetCurrentIndex()
+
+	// Perplexity
+	c.PerplexityAPIKey = c.PerplexityKeys.GetCurrentKey()
+	c.PerplexityCurrentKeyIdx = c.PerplexityKeys.GetCurrentIndex()
+}
+
+// ConfigDir returns the user configuration directory ($XDG_CONFIG_HOME/azure-ai or ~/.config/azure-ai)
+func ConfigDir() (string, error) {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, AppDirName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", AppDirName), nil
+}
+
+// StateDir returns the user state directory ($XDG_STATE_HOME/azure-ai or ~/.local/state/azure-ai)
+func StateDir() (string, error) {
+	if xdg := os.Getenv("XDG_STATE_HOME"); xdg != "" {
+		return filepath.Join(xdg, AppDirName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", AppDirName), nil
+}
+
+// DataDir returns the user data directory ($XDG_DATA_HOME/azure-ai or ~/.local/share/azure-ai)
+func DataDir() (string, error) {
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		return filepath.Join(xdg, AppDirName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", AppDirName), nil
+}
+
+// GetSessionsDir returns the directory holding saved interactive conversations
+func GetSessionsDir() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, SessionsDirName), nil
+}
+
+// GetQuotaFile returns the path of the local search quota usage file
+func GetQuotaFile() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, QuotaFileName), nil
+}
+
+// CurrentSearchKey returns the API key currently in use for a search provider
+func (c *Config) CurrentSearchKey(provider string) string {
+	switch provider {
+	case "tavily":
+		return c.TavilyAPIKey
+	case "linkup":
+		return c.LinkupAPIKey
+	case "brave":
+		return c.BraveAPIKey
+	case "perplexity":
+		return c.PerplexityAPIKey
+	}
+	return ""
+}
+
+// GetAllowlistFile returns the path of the command allowlist file
+func (c *Config) GetAllowlistFile() string {
+	if c.AllowlistFile != "" {
+		return c.AllowlistFile
+	}
+	if env := os.Getenv(EnvAllowlistFile); env != "" {
+		return env
+	}
+	dir, err := ConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, AllowlistFileName)
+}
+
+// GetDenylistFile returns the path of the command denylist file
+func (c *Config) GetDenylistFile() string {
+	if c.DenylistFile != "" {
+		return c.DenylistFile
+	}
+	if env := os.Getenv(EnvDenylistFile); env != "" {
+		return env
+	}
+	dir, err := ConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, DenylistFileName)
+}
+
+// loadSystemPrompt sets SystemPrompt from --system, --system-file, or AZURE_OPENAI_SYSTEM_PROMPT
+func (c *Config) loadSystemPrompt() error {
+	if c.SystemPromptFile != "" {
+		if c.SystemPrompt != "" {
+			return ErrSystemPromptConflict
+		}
+		data, err := os.ReadFile(c.SystemPromptFile)
+		if err != nil {
+			return fmt.Errorf("failed to read system prompt: %w", err)
+		}
+		c.SystemPrompt = string(data)
+	}
+	if c.SystemPrompt == "" {
+		c.SystemPrompt = os.Getenv(EnvSystemPrompt)
+	}
+	c.SystemPrompt = strings.TrimSpace(c.SystemPrompt)
+	return nil
 }
 
-// GetAzureAPIURL builds the full API URL for chat completions
-func (c *Config) GetAzureAPIURL() string {
-	return fmt.Sprintf("%s/openai/v1/chat/completions",
-		c.AzureEndpoint)
+// GetSystemPrompt returns the custom system prompt, or DefaultSystemMessage when none is set
+func (c *Config) GetSystemPrompt() string {
+	if c.SystemPrompt != "" {
+		return c.SystemPrompt
+	}
+	return DefaultSystemMessage
+}
+
+// GetPromptPrefix returns the interactive prompt template: --prompt-prefix,
+// then prompt_prefix from the config file, then the default "> "
+func (c *Config) GetPromptPrefix() string {
+	if c.PromptPrefix != "" {
+		return c.PromptPrefix
+	}
+	if prefix := c.fileValue(ConfigFileKeyPromptPrefix); prefix != "" {
+		return prefix
+	}
+	return DefaultPromptPrefix
+}
+
+// GetSearchMaxResults returns the number of results to request per web search,
+// defaulting to 5 when unset and clamped to MaxSearchResults
+func (c *Config) GetSearchMaxResults() int {
+	if c.SearchMaxResults <= 0 {
+		return DefaultSearchResults
+	}
+	if c.SearchMaxResults > MaxSearchResults {
+		return MaxSearchResults
+	}
+	return c.SearchMaxResults
+}
+
+// GetAzureAPIURL builds the full API URL for chat completions with a deployment
+func (c *Config) GetAzureAPIURL(deployment string) string {
+	return c.chatCompletionsURL(c.AzureEndpoint, deployment)
+}
+
+// GetAzureStreamAPIURL builds the API URL for streaming chat completions,
+// using the stream endpoint override when set
+func (c *Config) GetAzureStreamAPIURL(deployment string) string {
+	if c.AzureStreamEndpoint == "" {
+		return c.GetAzureAPIURL(deployment)
+	}
+	return c.chatCompletionsURL(c.AzureStreamEndpoint, deployment)
+}
+
+// chatCompletionsURL returns the v1 chat completions URL on endpoint or, with an
+// API version set, the classic per-deployment URL older resources expect
+func (c *Config) chatCompletionsURL(endpoint, deployment string) string {
+	if c.APIVersion == "" {
+		return fmt.Sprintf("%s/openai/v1/chat/completions", endpoint)
+	}
+	return fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
+		endpoint, url.PathEscape(deployment), url.QueryEscape(c.APIVersion))
 }
 
 // ValidateModel checks if the given model is in available models
@@ -278,6 +990,11 @@ func (c *Config) ValidateModel(model string) bool {
 	return false
 }
 
+// IsUnlistedModel reports whether models are configured and model is not one of them
+func (c *Config) IsUnlistedModel(model string) bool {
+	return len(c.AvailableModels) > 0 && !c.ValidateModel(model)
+}
+
 // GetAvailableModelsString returns a formatted string of available models
 func (c *Config) GetAvailableModelsString() string {
 	if len(c.AvailableModels) == 0 {
@@ -318,6 +1035,27 @@ func (c *Config) GetLinkupKeyCount() int {
 	return c.LinkupKeys.GetKeyCount()
 }
 
+// RotateAzureKey moves to the next available Azure OpenAI API key
+func (c *Config) RotateAzureKey() (string, error) {
+	if c.AzureKeys == nil {
+		return "", ErrNoAvailableKeys
+	}
+	key, err := c.AzureKeys.Rotate()
+	if err != nil {
+		return "", err
+	}
+	c.AzureAPIKey = key
+	return key, nil
+}
+
+// GetAzureKeyCount returns the total number of Azure OpenAI keys
+func (c *Config) GetAzureKeyCount() int {
+	if c.AzureKeys == nil {
+		return 0
+	}
+	return c.AzureKeys.GetKeyCount()
+}
+
 // RotateBraveKey moves to the next available Brave API key
 func (c *Config) RotateBraveKey() (string, error) {
 	key, err := c.BraveKeys.Rotate()
@@ -333,3 +1071,19 @@ func (c *Config) RotateBraveKey() (string, error) {
 func (c *Config) GetBraveKeyCount() int {
 	return c.BraveKeys.GetKeyCount()
 }
+
+// RotatePerplexityKey moves to the next available Perplexity API key
+func (c *Config) RotatePerplexityKey() (string, error) {
+	key, err := c.PerplexityKeys.Rotate()
+	if err != nil {
+		return "", err
+	}
+	c.PerplexityAPIKey = key
+	c.PerplexityCurrentKeyIdx = c.PerplexityKeys.GetCurrentIndex()
+	return key, nil
+}
+
+// GetPerplexityKeyCount returns the total number of Perplexity keys
+func (c *Config) GetPerplexityKeyCount() int {
+	return c.PerplexityKeys.GetKeyCount()
+}
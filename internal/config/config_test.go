@@ -0,0 +1,351 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/azure-ai-cli/internal/keyvault"
+)
+
+func TestGetSearchMaxResults(t *testing.T) {
+	tests := []struct {
+		name string
+		set  int
+		want int
+	}{
+		{"unset", 0, DefaultSearchResults},
+		{"negative", -3, DefaultSearchResults},
+		{"custom", 12, 12},
+		{"at limit", MaxSearchResults, MaxSearchResults},
+		{"clamped", 100, MaxSearchResults},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{SearchMaxResults: tt.set}
+			if got := c.GetSearchMaxResults(); got != tt.want {
+				t.Errorf("GetSearchMaxResults() with %d = %d, want %d", tt.set, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateUnlistedModel(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(EnvAzureEndpoint, "https://example.openai.azure.com")
+	t.Setenv(EnvAzureAPIKey, "key")
+	t.Setenv(EnvAzureModels, "gpt-4o,gpt-4")
+
+	tests := []struct {
+		name    string
+		model   string
+		allow   bool
+		wantErr bool
+	}{
+		{"listed", "gpt-4o", false, false},
+		{"unlisted", "my-deployment", false, true},
+		{"unlisted allowed", "my-deployment", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Model: tt.model, AllowUnlistedModel: tt.allow}
+			err := c.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrInvalidModel) {
+				t.Errorf("Validate() error = %v, want ErrInvalidModel", err)
+			}
+			if !tt.wantErr && c.Model != tt.model {
+				t.Errorf("Model = %q, want %q", c.Model, tt.model)
+			}
+		})
+	}
+}
+
+func TestValidateKeyVault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/secrets/endpoint":
+			_, _ = w.Write([]byte(`{"value":"https://vault.openai.azure.com/"}`))
+		case "/secrets/key":
+			_, _ = w.Write([]byte(`{"value":"vault-key"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(EnvAzureAPIKeys, "")
+	t.Setenv(EnvAzureAPIKey, "")
+	t.Setenv(EnvAzureModels, "")
+	t.Setenv(EnvKeyVaultURL, server.URL)
+	t.Setenv(EnvEndpointSecret, "endpoint")
+	t.Setenv(EnvKeySecret, "key")
+	newConfig := func() *Config {
+		return &Config{keyVault: keyvault.NewClient(server.URL, func(context.Context) (string, error) { return "token", nil })}
+	}
+
+	t.Setenv(EnvAzureEndpoint, "")
+	c := newConfig()
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if c.AzureEndpoint != "https://vault.openai.azure.com" || c.AzureAPIKey != "vault-key" {
+		t.Errorf("endpoint, key = %q, %q, want the vault secrets", c.AzureEndpoint, c.AzureAPIKey)
+	}
+
+	// Environment variables win over the vault
+	t.Setenv(EnvAzureEndpoint, "https://env.openai.azure.com")
+	c = newConfig()
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if c.AzureEndpoint != "https://env.openai.azure.com" {
+		t.Errorf("endpoint = %q, want the environment value", c.AzureEndpoint)
+	}
+
+	t.Setenv(EnvKeySecret, "missing")
+	if err := newConfig().Validate(); err == nil {
+		t.Error("Validate() with a missing secret should fail")
+	}
+}
+
+func TestLoadSystemPrompt(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "system.txt")
+	if err := os.WriteFile(file, []byte("From file.\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		flag    string
+		file    string
+		env     string
+		want    string
+		wantErr error
+	}{
+		{"default", "", "", "", DefaultSystemMessage, nil},
+		{"env", "", "", "From env.", "From env.", nil},
+		{"flag wins over env", "From flag.", "", "From env.", "From flag.", nil},
+		{"file wins over env", "", file, "From env.", "From file.", nil},
+		{"flag and file", "From flag.", file, "", "", ErrSystemPromptConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvSystemPrompt, tt.env)
+			c := &Config{SystemPrompt: tt.flag, SystemPromptFile: tt.file}
+			err := c.loadSystemPrompt()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("loadSystemPrompt() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && c.GetSystemPrompt() != tt.want {
+				t.Errorf("GetSystemPrompt() = %q, want %q", c.GetSystemPrompt(), tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateExtraBody(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(EnvAzureEndpoint, "https://example.openai.azure.com")
+	t.Setenv(EnvAzureAPIKey, "key")
+	t.Setenv(EnvAzureModels, "gpt-4o")
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{"unset", "", false},
+		{"object", `{"user":"alice","logit_bias":{"50256":-100}}`, false},
+		{"not json", `user=alice`, true},
+		{"array", `["user"]`, true},
+		{"null", `null`, true},
+		{"client field", `{"stream":false}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{ExtraBody: tt.body}
+			err := c.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrInvalidExtraBody) {
+				t.Errorf("Validate() error = %v, want ErrInvalidExtraBody", err)
+			}
+			if tt.name == "object" && string(c.ExtraBodyFields["user"]) != `"alice"` {
+				t.Errorf("ExtraBodyFields = %v, want the parsed object", c.ExtraBodyFields)
+			}
+		})
+	}
+}
+
+func TestGetAzureAPIURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		apiVersion string
+		stream     string
+		want       string
+		wantStream string
+	}{
+		{"v1", "", "", "https://res.openai.azure.com/openai/v1/chat/completions", "https://res.openai.azure.com/openai/v1/chat/completions"},
+		{"deployment", "2024-10-21", "",
+			"https://res.openai.azure.com/openai/deployments/gpt-4o/chat/completions?api-version=2024-10-21",
+			"https://res.openai.azure.com/openai/deployments/gpt-4o/chat/completions?api-version=2024-10-21"},
+		{"deployment with stream endpoint", "2024-10-21", "https://proxy.example.com",
+			"https://res.openai.azure.com/openai/deployments/gpt-4o/chat/completions?api-version=2024-10-21",
+			"https://proxy.example.com/openai/deployments/gpt-4o/chat/completions?api-version=2024-10-21"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{AzureEndpoint: "https://res.openai.azure.com", AzureStreamEndpoint: tt.stream, APIVersion: tt.apiVersion}
+			if got := c.GetAzureAPIURL("gpt-4o"); got != tt.want {
+				t.Errorf("GetAzureAPIURL() = %q, want %q", got, tt.want)
+			}
+			if got := c.GetAzureStreamAPIURL("gpt-4o"); got != tt.wantStream {
+				t.Errorf("GetAzureStreamAPIURL() = %q, want %q", got, tt.wantStream)
+			}
+		})
+	}
+}
+
+func TestValidateAuthMode(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(EnvAzureEndpoint, "https://example.openai.azure.com")
+	t.Setenv(EnvAzureAPIKey, "key")
+	t.Setenv(EnvAzureModels, "gpt-4o")
+
+	tests := []struct {
+		name       string
+		mode       string
+		env        string
+		apiVersion string
+		want       string
+		wantErr    bool
+	}{
+		{"v1 default", "", "", "", AuthBearer, false},
+		{"classic default", "", "", "2024-10-21", AuthAPIKey, false},
+		{"flag wins", "Bearer", "api-key", "2024-10-21", AuthBearer, false},
+		{"env", "", "api-key", "", AuthAPIKey, false},
+		{"invalid", "basic", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvAuthMode, tt.env)
+			c := &Config{AuthMode: tt.mode, APIVersion: tt.apiVersion}
+			err := c.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAuthMode) {
+					t.Errorf("Validate() error = %v, want ErrInvalidAuthMode", err)
+				}
+				return
+			}
+			if c.AuthMode != tt.want {
+				t.Errorf("AuthMode = %q, want %q", c.AuthMode, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAnswerLang(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(EnvAzureEndpoint, "https://example.openai.azure.com")
+	t.Setenv(EnvAzureAPIKey, "key")
+	t.Setenv(EnvAzureModels, "gpt-4o")
+
+	tests := []struct {
+		lang    string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"DE", "de", false},
+		{"pt_BR", "pt-br", false},
+		{"klingon", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			c := &Config{AnswerLang: tt.lang}
+			err := c.Validate()
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAnswerLang) || !strings.Contains(err.Error(), "ja") {
+					t.Errorf("Validate() error = %v, want ErrInvalidAnswerLang listing the codes", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if c.AnswerLang != tt.want {
+				t.Errorf("AnswerLang = %q, want %q", c.AnswerLang, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateProviderPriority(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(EnvAzureEndpoint, "https://example.openai.azure.com")
+	t.Setenv(EnvAzureAPIKey, "key")
+	t.Setenv(EnvAzureModels, "gpt-4o")
+	t.Setenv(EnvWebSearchProvider, "")
+	t.Setenv(EnvTavilyAPIKeys, "tvly-key")
+	t.Setenv(EnvBraveAPIKeys, "brave-key")
+	t.Setenv(EnvLinkupAPIKeys, "")
+	t.Setenv(EnvPerplexityAPIKeys, "")
+	t.Setenv(EnvSearXNGURL, "")
+
+	tests := []struct {
+		name     string
+		flag     []string
+		env      string
+		provider string
+		want     string
+		wantErr  bool
+	}{
+		{"default order", nil, "", "", "tavily", false},
+		{"flag order", []string{"brave", "tavily"}, "", "", "brave", false},
+		{"env order", nil, " Brave , tavily", "", "brave", false},
+		{"flag beats env", []string{"tavily"}, "brave", "", "tavily", false},
+		{"skips providers without keys", []string{"linkup", "brave"}, "", "", "brave", false},
+		{"no listed provider has keys", []string{"linkup"}, "", "", DefaultSearchProvider, false},
+		{"explicit provider ignores priority", []string{"brave"}, "", "tavily", "tavily", false},
+		{"unknown provider", []string{"bing"}, "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvWebSearchPriority, tt.env)
+			c := &Config{ProviderPriority: tt.flag, WebSearchProvider: tt.provider}
+			err := c.Validate()
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSearchProvider) {
+					t.Errorf("Validate() error = %v, want ErrInvalidSearchProvider", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if c.WebSearchProvider != tt.want {
+				t.Errorf("WebSearchProvider = %q, want %q", c.WebSearchProvider, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config file keys. Boolean flag defaults use the flag name as key (see ApplyFileFlags).
+const (
+	ConfigFileKeyEndpoint     = "endpoint"
+	ConfigFileKeyAPIKey       = "api_key"
+	ConfigFileKeyModels       = "models"
+	ConfigFileKeyModel        = "model"
+	ConfigFileKeyProvider     = "provider"
+	ConfigFileKeyPromptPrefix = "prompt_prefix"
+	ConfigFileKeyAliases      = "aliases"
+	ConfigFileKeyDefaultFlags = "default_flags"
+)
+
+// ConfigFilePaths returns the config file locations probed by NewConfig, in order:
+// $XDG_CONFIG_HOME/azure-ai/config.yaml, then ~/.config/azure-ai/config.yaml
+func ConfigFilePaths() []string {
+	var paths []string
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		paths = append(paths, filepath.Join(xdg, AppDirName, ConfigFileName))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".config", AppDirName, ConfigFileName))
+	}
+	return paths
+}
+
+// NewConfigFromFile creates a Config that falls back to the settings in the YAML
+// file at path wherever flags and environment variables leave a value unset.
+// A missing file is not an error.
+func NewConfigFromFile(path string) (*Config, error) {
+	c := &Config{}
+	if err := c.LoadFile(path); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// LoadFile replaces the config file settings with those read from path.
+// A missing file leaves no file settings and is not an error.
+func (c *Config) LoadFile(path string) error {
+	c.fileErr = nil
+	values, err := readFileValues(path)
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	c.ConfigFile = path
+	c.fileValues = values
+	return nil
+}
+
+// fileValue returns a setting from the loaded config file, or "" if it is not set
+func (c *Config) fileValue(key string) string {
+	return c.fileValues[key]
+}
+
+// Alias returns the prompt template of a query alias from the "aliases:" section
+// of the config file, e.g. "review" for `review: "review this code for bugs: "`
+func (c *Config) Alias(name string) (string, bool) {
+	template, ok := c.fileValues[ConfigFileKeyAliases+"."+name]
+	return template, ok && template != ""
+}
+
+// DefaultFlags returns the flags put before the command line, from AZURE_AI_FLAGS
+// or else "default_flags" in the config file, split into words like a shell would
+func (c *Config) DefaultFlags() ([]string, error) {
+	source, flags := EnvDefaultFlags, os.Getenv(EnvDefaultFlags)
+	if flags == "" {
+		source, flags = ConfigFileKeyDefaultFlags, c.fileValue(ConfigFileKeyDefaultFlags)
+	}
+	words, err := splitWords(flags)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	return words, nil
+}
+
+// splitWords splits s on whitespace like a POSIX shell, without expansions: single
+// quotes keep everything literal, and a backslash escapes the next character outside
+// them (inside double quotes only before " and \)
+func splitWords(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			if quote == '"' && r != '"' && r != '\\' {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inWord = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, ErrUnterminatedQuote
+	}
+	if escaped { // A trailing backslash stays literal
+		word.WriteRune('\\')
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// ApplyFileFlags applies boolean flag defaults from the config file, e.g.
+// "stream: true", except for flags given on the command line (changed reports those)
+func (c *Config) ApplyFileFlags(changed func(name string) bool) error {
+	flags := map[string]*bool{
+		"stream":    &c.Stream,
+		"render":    &c.Render,
+		"web":       &c.WebSearch,
+		"citations": &c.Citations,
+		"usage":     &c.Usage,
+	}
+	for name, field := range flags {
+		value, ok := c.fileValues[name]
+		if !ok || changed(name) {
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("config file %s: %s: invalid boolean %q", c.ConfigFile, name, value)
+		}
+		*field = enabled
+	}
+	return nil
+}
+
+// ConfigFilePath returns the path of the user config file (see ConfigDir)
+func ConfigFilePath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ConfigFileName), nil
+}
+
+// ReadFileValue returns a top-level "key: value" setting from the config file.
+// A missing file or key yields an empty value.
+func ReadFileValue(key string) (string, error) {
+	path, err := ConfigFilePath()
+	if err != nil {
+		return "", nil
+	}
+	values, err := readFileValues(path)
+	if err != nil {
+		return "", err
+	}
+	return values[key], nil
+}
+
+// readFileValues parses the top-level settings of a config file. Lists, either
+// "[a, b]" or "- item" lines below the key, are joined with commas like the
+// AZURE_OPENAI_MODELS variable. The "name: value" entries of a section such as
+// aliases are stored as "key.name". A missing file yields no settings.
+func readFileValues(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	if len(doc.Content) == 0 {
+		return values, nil // Empty or comments only
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected \"key: value\" settings", root.Line)
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i].Value, root.Content[i+1]
+		switch value.Kind {
+		case yaml.SequenceNode:
+			var items []string
+			for _, item := range value.Content {
+				if item.Kind == yaml.ScalarNode && item.Value != "" {
+					items = append(items, item.Value)
+				}
+			}
+			values[key] = strings.Join(items, ",")
+		case yaml.MappingNode:
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				values[key+"."+value.Content[j].Value] = scalarValue(value.Content[j+1])
+			}
+		default:
+			values[key] = scalarValue(value)
+		}
+	}
+	return values, nil
+}
+
+// scalarValue returns the text of a scalar as written, so "yes" stays a string;
+// null and non-scalar values are empty
+func scalarValue(node *yaml.Node) string {
+	if node.Kind != yaml.ScalarNode || node.Tag == "!!null" {
+		return ""
+	}
+	return node.Value
+}
+
+// SetFileValue writes a top-level "key: value" setting to the config file,
+// replacing an existing line for key and keeping everything else as is
+func SetFileValue(key, value string) (string, error) {
+	path, err := ConfigFilePath()
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+
+	entry := fmt.Sprintf("%s: %s", key, formatFileValue(value))
+	replaced := false
+	for i, line := range lines {
+		if k, ok := fileLineKey(line); ok && k == key {
+			lines[i] = entry
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		lines = append(lines, entry)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600)
+}
+
+// fileLineKey returns the key of a top-level "key: value" line, ignoring comments, blank and indented lines
+func fileLineKey(line string) (string, bool) {
+	if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' {
+		return "", false
+	}
+	key, _, ok := strings.Cut(line, ":")
+	return strings.TrimSpace(key), ok
+}
+
+// formatFileValue quotes a value when writing it bare could change its meaning
+func formatFileValue(value string) string {
+	if value == "" || strings.ContainsAny(value, ":#'\"") || strings.TrimSpace(value) != value {
+		return strconv.Quote(value)
+	}
+	return value
+}
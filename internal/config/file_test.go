@@ -0,0 +1,245 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSetFileValue(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	path := filepath.Join(dir, AppDirName, ConfigFileName)
+
+	if got, err := ReadFileValue(ConfigFileKeyModel); err != nil || got != "" {
+		t.Fatalf("ReadFileValue() on missing file = %q, %v; want empty", got, err)
+	}
+
+	if _, err := SetFileValue(ConfigFileKeyModel, "gpt-4o"); err != nil {
+		t.Fatalf("SetFileValue() error = %v", err)
+	}
+	if got, _ := ReadFileValue(ConfigFileKeyModel); got != "gpt-4o" {
+		t.Errorf("ReadFileValue() = %q, want gpt-4o", got)
+	}
+
+	// Other settings and comments survive an update
+	existing := "# my settings\nprovider: brave\nmodel: gpt-4o # current\n"
+	if err := os.WriteFile(path, []byte(existing), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := ReadFileValue(ConfigFileKeyModel); got != "gpt-4o" {
+		t.Errorf("ReadFileValue() with comment = %q, want gpt-4o", got)
+	}
+	if _, err := SetFileValue(ConfigFileKeyModel, "gpt-5.1-chat"); err != nil {
+		t.Fatalf("SetFileValue() error = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	want := "# my settings\nprovider: brave\nmodel: gpt-5.1-chat\n"
+	if string(data) != want {
+		t.Errorf("file = %q, want %q", data, want)
+	}
+}
+
+func TestNewConfigFromFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for _, env := range []string{EnvAzureEndpoint, EnvAzureStreamEndpoint, EnvAzureAPIKey, EnvAzureModels, EnvWebSearchProvider, EnvWebSearchPriority} {
+		t.Setenv(env, "")
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `# azure-ai settings
+endpoint: https://file.openai.azure.com/
+api_key: "file-key"
+models:
+  - gpt-4o
+  - gpt-4
+provider: brave
+stream: true
+render: yes
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Environment variables win over the file
+	t.Setenv(EnvAzureAPIKey, "env-key")
+
+	cfg, err := NewConfigFromFile(path)
+	if err != nil {
+		t.Fatalf("NewConfigFromFile() error = %v", err)
+	}
+	if err := cfg.ApplyFileFlags(func(name string) bool { return false }); err == nil {
+		t.Error("ApplyFileFlags() with render: yes should fail")
+	}
+	cfg.fileValues["render"] = "true"
+	cfg.Stream = false
+	// --stream=false on the command line wins over the file
+	if err := cfg.ApplyFileFlags(func(name string) bool { return name == "stream" }); err != nil {
+		t.Fatalf("ApplyFileFlags() error = %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	if cfg.AzureEndpoint != "https://file.openai.azure.com" {
+		t.Errorf("AzureEndpoint = %q, want file value without trailing slash", cfg.AzureEndpoint)
+	}
+	if cfg.AzureAPIKey != "env-key" {
+		t.Errorf("AzureAPIKey = %q, want env-key", cfg.AzureAPIKey)
+	}
+	if cfg.Model != "gpt-4o" || len(cfg.AvailableModels) != 2 {
+		t.Errorf("Model = %q, AvailableModels = %v; want gpt-4o of [gpt-4o gpt-4]", cfg.Model, cfg.AvailableModels)
+	}
+	if cfg.WebSearchProvider != "brave" {
+		t.Errorf("WebSearchProvider = %q, want brave", cfg.WebSearchProvider)
+	}
+	if cfg.Stream || !cfg.Render {
+		t.Errorf("Stream = %v, Render = %v; want false, true", cfg.Stream, cfg.Render)
+	}
+}
+
+func TestNewConfigFromFileMissing(t *testing.T) {
+	cfg, err := NewConfigFromFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("NewConfigFromFile() on missing file error = %v", err)
+	}
+	if cfg.fileValue(ConfigFileKeyEndpoint) != "" {
+		t.Error("expected no file settings")
+	}
+}
+
+func TestReadFileValuesLists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "models: [gpt-4o, \"gpt-4\"]\nfallback:\n- a\n- b # backup\nprovider: tavily\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := readFileValues(path)
+	if err != nil {
+		t.Fatalf("readFileValues() error = %v", err)
+	}
+	want := map[string]string{"models": "gpt-4o,gpt-4", "fallback": "a,b", "provider": "tavily"}
+	for k, v := range want {
+		if values[k] != v {
+			t.Errorf("values[%q] = %q, want %q", k, values[k], v)
+		}
+	}
+}
+
+func TestReadFileValuesYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"quoted hash", "prompt_prefix: \"use C# # not a comment\"\n", map[string]string{"prompt_prefix": "use C# # not a comment"}, false},
+		{"block scalar", "aliases:\n  review: |\n    review this\n    carefully\n", map[string]string{"aliases.review": "review this\ncarefully\n"}, false},
+		{"flow mapping", "aliases: {fix: \"fix: \", tldr: summarize}\n", map[string]string{"aliases.fix": "fix: ", "aliases.tldr": "summarize"}, false},
+		{"null value", "model: ~\nprovider:\n", map[string]string{"model": "", "provider": ""}, false},
+		{"yes stays a string", "render: yes\n", map[string]string{"render": "yes"}, false},
+		{"comments only", "# nothing yet\n", map[string]string{}, false},
+		{"invalid yaml", "models: [gpt-4o\n", nil, true},
+		{"not a mapping", "- gpt-4o\n", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			values, err := readFileValues(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readFileValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(values, tt.want) {
+				t.Errorf("readFileValues() = %q, want %q", values, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigFileAliases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `aliases:
+  review: "review this code for bugs: "
+  # comment
+  standup: summarize my git diff
+model: gpt-4o
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := NewConfigFromFile(path)
+	if err != nil {
+		t.Fatalf("NewConfigFromFile() error = %v", err)
+	}
+	if got, ok := cfg.Alias("review"); !ok || got != "review this code for bugs: " {
+		t.Errorf("Alias(review) = %q, %v", got, ok)
+	}
+	if got, ok := cfg.Alias("standup"); !ok || got != "summarize my git diff" {
+		t.Errorf("Alias(standup) = %q, %v", got, ok)
+	}
+	if _, ok := cfg.Alias("missing"); ok {
+		t.Error("Alias(missing) found")
+	}
+	// The section ends at the next top-level key
+	if got := cfg.fileValue(ConfigFileKeyModel); got != "gpt-4o" {
+		t.Errorf("model = %q, want gpt-4o", got)
+	}
+}
+
+func TestDefaultFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("default_flags: -sr  --web\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := NewConfigFromFile(path)
+	if err != nil {
+		t.Fatalf("NewConfigFromFile() error = %v", err)
+	}
+
+	t.Setenv(EnvDefaultFlags, "")
+	if got, err := cfg.DefaultFlags(); err != nil || !reflect.DeepEqual(got, []string{"-sr", "--web"}) {
+		t.Errorf("DefaultFlags() from file = %v, %v", got, err)
+	}
+	t.Setenv(EnvDefaultFlags, `--citations --system "be terse"`)
+	if got, err := cfg.DefaultFlags(); err != nil || !reflect.DeepEqual(got, []string{"--citations", "--system", "be terse"}) {
+		t.Errorf("DefaultFlags() from env = %v, %v", got, err)
+	}
+	t.Setenv(EnvDefaultFlags, `--system "be terse`)
+	if _, err := cfg.DefaultFlags(); !errors.Is(err, ErrUnterminatedQuote) {
+		t.Errorf("DefaultFlags() with open quote error = %v, want %v", err, ErrUnterminatedQuote)
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"  -sr   --web\t", []string{"-sr", "--web"}},
+		{`--system "be terse"`, []string{"--system", "be terse"}},
+		{`--system='it''s'`, []string{"--system=its"}},
+		{`--system 'say "hi"'`, []string{"--system", `say "hi"`}},
+		{`--system "a \"b\" \n"`, []string{"--system", `a "b" \n`}},
+		{`one\ word ""`, []string{"one word", ""}},
+		{`trailing\`, []string{`trailing\`}},
+	}
+	for _, tt := range tests {
+		got, err := splitWords(tt.in)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitWords(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{`"open`, `'open`} {
+		if _, err := splitWords(in); !errors.Is(err, ErrUnterminatedQuote) {
+			t.Errorf("splitWords(%q) error = %v, want %v", in, err, ErrUnterminatedQuote)
+		}
+	}
+}
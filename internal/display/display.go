@@ -1,7 +1,10 @@
 package display
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"strings"
 	"sync"
@@ -18,6 +21,92 @@ var (
 	rendererErr  error
 )
 
+// bare suppresses spinners and status notices so stdout carries only the answer
+var bare bool
+
+// SetBare enables bare output: no spinners, no status notices, and
+// supplementary output such as token usage goes to stderr
+func SetBare(enabled bool) {
+	bare = enabled
+}
+
+// quietNotices suppresses status notices on stderr while keeping errors
+var quietNotices bool
+
+// SetQuietNotices hides status notices (searching, key rotation, fallbacks); errors are still shown
+func SetQuietNotices(enabled bool) {
+	quietNotices = enabled
+}
+
+// shortURLs shortens long citation URLs for display
+var shortURLs bool
+
+// SetShortURLs truncates the middle of long citation URLs; on a terminal the
+// shortened text stays a hyperlink to the full URL
+func SetShortURLs(enabled bool) {
+	shortURLs = enabled
+}
+
+// noColor turns off colors, rendering styles and the spinner (--no-color)
+var noColor bool
+
+// SetNoColor disables all colored output and the spinner, e.g. for CI logs
+func SetNoColor(enabled bool) {
+	noColor = enabled
+}
+
+// colorDisabled reports whether colors are off via --no-color or NO_COLOR (https://no-color.org)
+func colorDisabled() bool {
+	if noColor {
+		return true
+	}
+	_, ok := os.LookupEnv("NO_COLOR")
+	return ok
+}
+
+// ANSI styles for stderr notices
+const (
+	styleDim   = "\033[2m"
+	styleWarn  = "\033[33m"
+	styleReset = "\033[0m"
+)
+
+// colorEnabled reports whether stderr notices may be colored: colors are not
+// disabled and stderr is a terminal
+func colorEnabled() bool {
+	if colorDisabled() {
+		return false
+	}
+	info, err := os.Stderr.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// notice writes a status line to stderr in the given style, unless notices are suppressed
+func notice(style, format string, args ...interface{}) {
+	if bare || quietNotices {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	if colorEnabled() {
+		msg = style + msg + styleReset
+	}
+	fmt.Fprintln(os.Stderr, msg)
+}
+
+// stdoutIsTerminal reports whether stdout is a terminal rather than a pipe or file
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// infoOut returns the writer for supplementary output like token usage
+func infoOut() io.Writer {
+	if bare {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
 // Spinner wraps the spinner with elapsed time display
 type Spinner struct {
 	s         *spinner.Spinner
@@ -26,6 +115,7 @@ type Spinner struct {
 	stopChan  chan struct{}
 	wg        sync.WaitGroup
 	stopped   bool
+	disabled  bool
 	mu        sync.Mutex
 }
 
@@ -38,11 +128,16 @@ func NewSpinner(message string) *Spinner {
 		s:        s,
 		message:  message,
 		stopChan: make(chan struct{}),
+		disabled: bare || noColor,
 	}
 }
 
 // Start begins the spinner animation
 func (sp *Spinner) Start() {
+	if sp.disabled {
+		return
+	}
+
 	sp.mu.Lock()
 	sp.startTime = time.Now()
 	sp.mu.Unlock()
@@ -103,8 +198,13 @@ func (sp *Spinner) UpdateMessage(message string) {
 // InitRenderer initializes the markdown renderer
 func InitRenderer() error {
 	rendererOnce.Do(func() {
+		style := glamour.WithAutoStyle()
+		if colorDisabled() {
+			// Keeps the markdown layout without any escape codes
+			style = glamour.WithStandardStyle("notty")
+		}
 		r, err := glamour.NewTermRenderer(
-			glamour.WithAutoStyle(),
+			style,
 			glamour.WithWordWrap(100),
 		)
 		if err != nil {
@@ -116,23 +216,85 @@ func InitRenderer() error {
 	return rendererErr
 }
 
-// ShowUsage displays token usage statistics
+// ShowUsage displays token usage statistics, separated from the answer by a blank line
 func ShowUsage(usage map[string]int) {
-	fmt.Println("## Tokens")
+	w := infoOut()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "## Tokens")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Type | Count |")
+	fmt.Fprintln(w, "|------|-------|")
+	fmt.Fprintf(w, "| Input | %d |\n", usage["input_tokens"])
+	fmt.Fprintf(w, "| Output | %d |\n", usage["output_tokens"])
+	fmt.Fprintf(w, "| **Total** | **%d** |\n", usage["total_tokens"])
+	fmt.Fprintln(w)
+}
+
+// ShowSessionUsage displays the tokens used by an interactive session so far and
+// the estimated size of the history sent with the next message
+func ShowSessionUsage(input, output, total, requests, historyMessages, historyTokens int) {
 	fmt.Println()
-	fmt.Println("| Type | Count |")
-	fmt.Println("|------|-------|")
-	fmt.Printf("| Input | %d |\n", usage["input_tokens"])
-	fmt.Printf("| Output | %d |\n", usage["output_tokens"])
-	fmt.Printf("| **Total** | **%d** |\n", usage["total_tokens"])
+	fmt.Printf("Session tokens (%d requests since start or /clear):\n", requests)
+	fmt.Printf("  Input:   %d\n", input)
+	fmt.Printf("  Output:  %d\n", output)
+	fmt.Printf("  Total:   %d\n", total)
+	fmt.Printf("History:   %d messages, ~%d tokens (estimate)\n", historyMessages, historyTokens)
 	fmt.Println()
 }
 
+// PhaseTiming is the elapsed time of one phase of a request
+type PhaseTiming struct {
+	Name     string
+	Duration time.Duration
+}
+
+// ShowTimings displays a per-phase elapsed time breakdown on stderr
+func ShowTimings(phases []PhaseTiming) {
+	parts := make([]string, len(phases))
+	var total time.Duration
+	for i, p := range phases {
+		parts[i] = fmt.Sprintf("%s %.1fs", p.Name, p.Duration.Seconds())
+		total += p.Duration
+	}
+	fmt.Fprintf(os.Stderr, "Timing: %s (total %.1fs)\n", strings.Join(parts, ", "), total.Seconds())
+}
+
+// ShowFirstToken displays how long the first streamed token took to arrive on stderr
+func ShowFirstToken(d time.Duration) {
+	fmt.Fprintf(os.Stderr, "First token in %.1fs\n", d.Seconds())
+}
+
 // ShowContent displays the main content response
 func ShowContent(content string) {
 	fmt.Println(strings.TrimSpace(content))
 }
 
+// ChoiceSeparator is the rule printed between alternative answers (--n)
+const ChoiceSeparator = "---"
+
+// ShowChoices displays alternative answers numbered and separated by a rule
+func ShowChoices(contents []string, render bool) {
+	for i, content := range contents {
+		if i > 0 {
+			fmt.Printf("\n%s\n\n", ChoiceSeparator)
+		}
+		fmt.Printf("### Answer %d of %d\n\n", i+1, len(contents))
+		if render {
+			ShowContentRendered(content)
+		} else {
+			ShowContent(content)
+		}
+	}
+}
+
+// ShowQueryHeader separates answers when several queries are given on the command line
+func ShowQueryHeader(index, total int, query string) {
+	if index > 1 {
+		fmt.Println()
+	}
+	fmt.Printf("## [%d/%d] %s\n\n", index, total, query)
+}
+
 // ShowContentRendered displays markdown content with terminal rendering
 func ShowContentRendered(content string) {
 	if renderer == nil {
@@ -147,6 +309,26 @@ func ShowContentRendered(content string) {
 	fmt.Print(strings.TrimSuffix(rendered, "\n"))
 }
 
+// RenderMarkdown returns content rendered for the terminal, initializing the renderer
+// if needed; content is returned unchanged when rendering is unavailable
+func RenderMarkdown(content string) string {
+	if err := InitRenderer(); err != nil || renderer == nil {
+		return content
+	}
+	rendered, err := renderer.Render(content)
+	if err != nil {
+		return content
+	}
+	return rendered
+}
+
+// ShowJSON prints v as indented JSON on stdout
+func ShowJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
 // ShowError displays an error message
 func ShowError(message string) {
 	fmt.Fprintf(os.Stderr, "Error: %s\n", message)
@@ -154,18 +336,83 @@ func ShowError(message string) {
 
 // ShowKeyRotation displays a message when API key is rotated
 func ShowKeyRotation(service string, fromIndex, toIndex, totalKeys int) {
-	fmt.Fprintf(os.Stderr, "Note: %s API key %d/%d failed, switching to key %d/%d\n",
+	notice(styleWarn, "Note: %s API key %d/%d failed, switching to key %d/%d",
 		service, fromIndex, totalKeys, toIndex, totalKeys)
 }
 
+// ShowModelFallback displays a message when a request falls back to another model.
+// A nil err means toModel produced the answer.
+func ShowModelFallback(fromModel, toModel string, err error) {
+	if err != nil {
+		notice(styleWarn, "Note: model %s unavailable (%v), trying %s", fromModel, err, toModel)
+		return
+	}
+	notice(styleWarn, "Note: answered by fallback model %s", toModel)
+}
+
+// ShowUnlistedModel warns that a model is not among the configured ones (--allow-unlisted-model)
+func ShowUnlistedModel(model, available string) {
+	notice(styleWarn, "Note: model %s is not in the configured models (%s); using it anyway", model, available)
+}
+
+// ShowHistorySummarized displays a note when old turns were replaced by a summary
+func ShowHistorySummarized(messages, fromTokens, toTokens int) {
+	notice(styleDim, "Note: summarized %d older message(s) (~%d → ~%d tokens)", messages, fromTokens, toTokens)
+}
+
+// ShowContextOverflow warns that a request's estimated prompt exceeds --max-context.
+// With trimming on and nothing dropped, the newest message alone is over the limit.
+func ShowContextOverflow(estimated, limit, dropped int, trimming bool) {
+	if dropped > 0 {
+		notice(styleWarn, "Warning: prompt is ~%d tokens, over --max-context %d; left out the %d oldest message(s)", estimated, limit, dropped)
+		return
+	}
+	if trimming {
+		notice(styleWarn, "Warning: prompt is ~%d tokens, over --max-context %d even without older messages; the request may be rejected", estimated, limit)
+		return
+	}
+	notice(styleWarn, "Warning: prompt is ~%d tokens, over --max-context %d; the request may be rejected (--trim-context drops old messages)", estimated, limit)
+}
+
+// ShowHistoryTrimmed displays a note when the oldest messages were dropped to fit --max-context
+func ShowHistoryTrimmed(messages, fromTokens, toTokens int) {
+	notice(styleDim, "Note: dropped %d oldest message(s) to fit --max-context (~%d → ~%d tokens)", messages, fromTokens, toTokens)
+}
+
+// ShowToolOutputSummarized displays a note when large command output was summarized for the model
+func ShowToolOutputSummarized(fromBytes, toBytes int) {
+	notice(styleDim, "Note: summarized command output for the model (%d → %d bytes)", fromBytes, toBytes)
+}
+
+// ShowTokenEstimate warns that --count-only printed a local estimate because counting failed
+func ShowTokenEstimate(err error) {
+	notice(styleWarn, "Warning: token count request failed (%v); printing a local estimate", err)
+}
+
+// ShowCountWithoutSearch notes that --count-only skipped an answer provider's search,
+// since that search would already return a paid answer
+func ShowCountWithoutSearch(provider string) {
+	notice(styleDim, "Note: %s answers with its search results; counting the prompt without searching", provider)
+}
+
+// ShowAnswerRetry displays a note when --retry-empty asks again after an empty or short answer
+func ShowAnswerRetry() {
+	notice(styleWarn, "Answer was empty or too short; asking once more...")
+}
+
+// ShowSearchSkipped displays a note when --smart-web or --search-intent answers without searching
+func ShowSearchSkipped() {
+	notice(styleDim, "Note: answering from conversation context (no new search)")
+}
+
 // ShowWebSearching displays a message when web search starts
 func ShowWebSearching(query string) {
-	fmt.Fprintf(os.Stderr, "Searching web for: %s\n", query)
+	notice(styleDim, "Searching web for: %s", query)
 }
 
 // ShowWebResults displays the number of web results found
 func ShowWebResults(count int) {
-	fmt.Fprintf(os.Stderr, "Found %d results\n", count)
+	notice(styleDim, "Found %d results", count)
 }
 
 // ShowModels displays available models
@@ -180,26 +427,274 @@ func ShowModels(models []string, currentModel string) {
 	}
 }
 
+// ToolListing describes a tool for --list-tools and /tools
+type ToolListing struct {
+	Name        string
+	Description string
+	Parameters  string // Parameter summary, e.g. "command: string, reasoning: string"
+	Custom      bool   // Defined in --tools-file
+	Enabled     bool
+	Disabled    string // Why the tool is off, when it is
+}
+
+// ShowTools displays the registered tools with their parameters and whether each is enabled
+func ShowTools(tools []ToolListing) {
+	fmt.Println("Tools:")
+	for _, t := range tools {
+		status := "enabled"
+		if !t.Enabled {
+			status = "disabled: " + t.Disabled
+		}
+		name := t.Name
+		if t.Custom {
+			name += " (custom)"
+		}
+		fmt.Printf("  %s [%s]\n", name, status)
+		if t.Description != "" {
+			fmt.Printf("      %s\n", t.Description)
+		}
+		if t.Parameters != "" {
+			fmt.Printf("      Parameters: %s\n", t.Parameters)
+		}
+	}
+}
+
+// ModelCapability describes the known capabilities of a model.
+// A nil flag means the capability has not been probed yet.
+type ModelCapability struct {
+	Name      string
+	Current   bool
+	Tools     *bool
+	Vision    *bool
+	Streaming *bool
+}
+
+// ShowModelCapabilities displays models with their cached capability flags
+func ShowModelCapabilities(models []ModelCapability) {
+	fmt.Printf("    %-24s %-6s %-7s %-9s\n", "Model", "Tools", "Vision", "Streaming")
+	for _, m := range models {
+		marker := " "
+		if m.Current {
+			marker = "*"
+		}
+		fmt.Printf("  %s %-24s %-6s %-7s %-9s\n", marker, m.Name,
+			formatCapability(m.Tools), formatCapability(m.Vision), formatCapability(m.Streaming))
+	}
+	fmt.Println("(* current, ? not yet probed - capabilities are detected on first use)")
+}
+
+func formatCapability(flag *bool) string {
+	if flag == nil {
+		return "?"
+	}
+	if *flag {
+		return "yes"
+	}
+	return "no"
+}
+
 // Citation represents a source citation
 type Citation struct {
-	Title string
-	URL   string
+	Title   string
+	URL     string
+	Snippet string
+	Score   float64
+}
+
+// QuotaUsage is the local request count for one search provider key
+type QuotaUsage struct {
+	Provider string
+	KeyIndex int    // 1-based position in the provider's key list
+	KeyHint  string // Last characters of the key, for identification
+	Count    int
 }
 
-// ShowCitations displays the source citations from web search
-func ShowCitations(citations []Citation) {
+// ShowQuota displays local search usage estimates for the current period
+func ShowQuota(periodStart string, usage []QuotaUsage) {
+	fmt.Printf("Search requests since %s (local estimate):\n", periodStart)
+	if len(usage) == 0 {
+		fmt.Println("  No search provider keys configured.")
+		return
+	}
+	for _, u := range usage {
+		fmt.Printf("  %-7s key %d (…%s)  %d\n", u.Provider, u.KeyIndex, u.KeyHint, u.Count)
+	}
+}
+
+// ShowCitations displays the source citations from web search.
+// If searchQuery is non-empty it is shown as the query that produced the sources.
+func ShowCitations(citations []Citation, searchQuery string) {
 	fmt.Println("## Sources")
 	fmt.Println()
+	if searchQuery != "" {
+		fmt.Printf("Searched for: %s\n\n", searchQuery)
+	}
 	for i, c := range citations {
-		fmt.Printf("[%d] %s - %s\n", i+1, c.Title, c.URL)
+		fmt.Printf("[%d] %s - %s\n", i+1, c.Title, displayURL(c.URL))
 	}
 }
 
+// maxDisplayURLLength is the length long citation URLs are shortened to with --short-urls
+const maxDisplayURLLength = 60
+
+// displayURL returns the URL as shown in the Sources block: unchanged by
+// default, or shortened with an OSC-8 hyperlink to the full URL on a terminal
+func displayURL(rawURL string) string {
+	if !shortURLs {
+		return rawURL
+	}
+	short := shortenURL(rawURL, maxDisplayURLLength)
+	if short == rawURL || !stdoutIsTerminal() {
+		return short
+	}
+	return "\033]8;;" + rawURL + "\033\\" + short + "\033]8;;\033\\"
+}
+
+// shortenURL truncates the middle of a URL longer than max runes with "…",
+// keeping the scheme and host plus as much of the tail as fits
+func shortenURL(rawURL string, max int) string {
+	runes := []rune(rawURL)
+	if len(runes) <= max {
+		return rawURL
+	}
+
+	head := rawURL
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		head = u.Scheme + "://" + u.Host
+	}
+	headRunes := []rune(head)
+
+	// Keep at least a few tail characters; if the host alone is too long, cut it too
+	const minTail = 8
+	if len(headRunes)+1+minTail > max {
+		return string(runes[:max-1]) + "…"
+	}
+	tail := runes[len(runes)-(max-len(headRunes)-1):]
+	return head + "…" + string(tail)
+}
+
+// ShowToolCall displays a tool call the AI has decided to make
+func ShowToolCall(name, arguments string) {
+	if bare {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "→ %s %s\n", name, arguments)
+}
+
+// ShowSearchResults displays search results with their snippets and scores (--only-sources)
+func ShowSearchResults(results []Citation) {
+	for i, r := range results {
+		fmt.Printf("[%d] %s\n    %s\n", i+1, r.Title, r.URL)
+		if snippet := strings.Join(strings.Fields(r.Snippet), " "); snippet != "" {
+			fmt.Printf("    %s\n", snippet)
+		}
+		if r.Score > 0 {
+			fmt.Printf("    score: %.2f\n", r.Score)
+		}
+		fmt.Println()
+	}
+}
+
+// BenchResult is the outcome of one query on one provider
+type BenchResult struct {
+	Results int
+	Latency time.Duration
+	Err     error
+}
+
+// ShowBenchTable displays a query-by-provider comparison followed by per-provider totals.
+// results[i][j] is query i on provider j.
+func ShowBenchTable(providers, queries []string, results [][]BenchResult) {
+	const queryWidth = 32
+
+	fmt.Printf("%-*s", queryWidth, "Query")
+	for _, p := range providers {
+		fmt.Printf("  %-14s", p)
+	}
+	fmt.Println()
+
+	for i, q := range queries {
+		if runes := []rune(q); len(runes) > queryWidth-2 {
+			q = string(runes[:queryWidth-3]) + "…"
+		}
+		fmt.Printf("%-*s", queryWidth, q)
+		for _, r := range results[i] {
+			cell := "error"
+			if r.Err == nil {
+				cell = fmt.Sprintf("%d (%.2fs)", r.Results, r.Latency.Seconds())
+			}
+			fmt.Printf("  %-14s", cell)
+		}
+		fmt.Println()
+	}
+
+	fmt.Println()
+	fmt.Printf("%-*s", queryWidth, "Avg latency")
+	for j := range providers {
+		var total time.Duration
+		ok := 0
+		for i := range queries {
+			if results[i][j].Err == nil {
+				total += results[i][j].Latency
+				ok++
+			}
+		}
+		cell := "-"
+		if ok > 0 {
+			cell = fmt.Sprintf("%.2fs", (total / time.Duration(ok)).Seconds())
+		}
+		fmt.Printf("  %-14s", cell)
+	}
+	fmt.Println()
+
+	fmt.Printf("%-*s", queryWidth, "Avg results")
+	for j := range providers {
+		count, ok := 0, 0
+		for i := range queries {
+			if results[i][j].Err == nil {
+				count += results[i][j].Results
+				ok++
+			}
+		}
+		cell := "-"
+		if ok > 0 {
+			cell = fmt.Sprintf("%.1f", float64(count)/float64(ok))
+		}
+		fmt.Printf("  %-14s", cell)
+	}
+	fmt.Println()
+
+	fmt.Printf("%-*s", queryWidth, "Errors")
+	for j := range providers {
+		errs := 0
+		for i := range queries {
+			if results[i][j].Err != nil {
+				errs++
+			}
+		}
+		fmt.Printf("  %-14d", errs)
+	}
+	fmt.Println()
+}
+
 // ShowCommandExecuting displays a message when a command is being executed
 func ShowCommandExecuting(command string) {
 	fmt.Fprintf(os.Stderr, "🔧 Executing: %s\n", command)
 }
 
+// ShowCommandDryRun displays a command that --dry-run kept from running
+func ShowCommandDryRun(command, reasoning string) {
+	fmt.Fprintf(os.Stderr, "🔍 Would run: %s\n", command)
+	if reasoning != "" {
+		fmt.Fprintf(os.Stderr, "Reason: %s\n", reasoning)
+	}
+}
+
+// ShowCommandCached displays a message when a read-only command's result is reused within a turn
+func ShowCommandCached(command string) {
+	fmt.Fprintf(os.Stderr, "🔧 Reusing result: %s\n", command)
+}
+
 // ShowCommandOutput displays the output of a successfully executed command
 func ShowCommandOutput(output string) {
 	if output != "" {
@@ -218,11 +713,15 @@ func ShowCommandBlocked(command, reason string) {
 	fmt.Fprintf(os.Stderr, "Reason: %s\n", reason)
 }
 
-// AskCommandConfirmation asks the user to confirm command execution
+// AskCommandConfirmation asks the user to confirm command execution. explanation,
+// when known, says what the command does; reasoning is the model's reason for it.
 // Returns: (allowed bool, always bool)
-func AskCommandConfirmation(command, reasoning string) (bool, bool) {
+func AskCommandConfirmation(command, explanation, reasoning string) (bool, bool) {
 	fmt.Printf("\n⚠️  Command Execution Request\n")
 	fmt.Printf("Command:  %s\n", command)
+	if explanation != "" {
+		fmt.Printf("Effect:   %s\n", explanation)
+	}
 	fmt.Printf("Reason:   %s\n", reasoning)
 	fmt.Printf("\nAllow? [y]es / [n]o / [a]lways: ")
 
@@ -242,10 +741,177 @@ func AskCommandConfirmation(command, reasoning string) (bool, bool) {
 	}
 }
 
+// ANSI colors for diff lines
+const (
+	diffAdded   = "\033[32m"
+	diffRemoved = "\033[31m"
+	diffHunk    = "\033[36m"
+	diffHeader  = "\033[1m"
+)
+
+// ShowDiff displays a unified diff, colored when stdout is a terminal
+func ShowDiff(diff string) {
+	fmt.Println()
+	color := stdoutIsTerminal() && !colorDisabled()
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		style := ""
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			style = diffHeader
+		case strings.HasPrefix(line, "@@"):
+			style = diffHunk
+		case strings.HasPrefix(line, "+"):
+			style = diffAdded
+		case strings.HasPrefix(line, "-"):
+			style = diffRemoved
+		}
+		if color && style != "" {
+			line = style + line + styleReset
+		}
+		fmt.Println(line)
+	}
+}
+
+// FileChange summarizes one pending file write for a batch review
+type FileChange struct {
+	Path    string
+	Added   int
+	Removed int
+	New     bool
+}
+
+// String formats the change as "path (+a -r)", marking new files
+func (c FileChange) String() string {
+	if c.New {
+		return fmt.Sprintf("%s (new, +%d)", c.Path, c.Added)
+	}
+	return fmt.Sprintf("%s (+%d -%d)", c.Path, c.Added, c.Removed)
+}
+
+// AskFileWriteConfirmation asks the user to approve the file write whose diff was just shown
+func AskFileWriteConfirmation(path, reasoning string, added, removed int, isNew bool) bool {
+	fmt.Printf("\n📝 File Write Request\n")
+	fmt.Printf("File:     %s\n", FileChange{Path: path, Added: added, Removed: removed, New: isNew})
+	fmt.Printf("Reason:   %s\n", reasoning)
+	fmt.Printf("\nWrite this file? [y]es / [n]o: ")
+
+	// Read single character from stdin
+	var buf [1]byte
+	os.Stdin.Read(buf[:])
+	fmt.Println() // New line after input
+
+	return strings.ToLower(string(buf[0])) == "y"
+}
+
+// AskFileWritesApproval summarizes several pending file writes and asks how to apply
+// them: "all", "none", or "each" to confirm them one at a time
+func AskFileWritesApproval(changes []FileChange) string {
+	fmt.Printf("\n📝 %d File Write Requests\n", len(changes))
+	for _, c := range changes {
+		fmt.Printf("  - %s\n", c)
+	}
+	fmt.Printf("\nWrite [a]ll / [n]one / review [e]ach: ")
+	switch strings.ToLower(strings.TrimSpace(readLine())) {
+	case "a", "all":
+		return "all"
+	case "n", "none":
+		return "none"
+	default:
+		return "each"
+	}
+}
+
+// ShowFileWritten confirms a file written by the write_file tool
+func ShowFileWritten(path string) {
+	fmt.Printf("✓ Wrote %s\n", path)
+}
+
+// ShowFileDryRun displays a file write that --dry-run kept from happening
+func ShowFileDryRun(path string) {
+	fmt.Fprintf(os.Stderr, "🔍 Would write: %s (dry run)\n", path)
+}
+
+// AskPlanApproval asks the user to approve the plan shown for --plan
+func AskPlanApproval() bool {
+	fmt.Printf("\nRun this plan? [y]es / [n]o: ")
+
+	// Read single character from stdin
+	var buf [1]byte
+	os.Stdin.Read(buf[:])
+	fmt.Println() // New line after input
+
+	return strings.ToLower(string(buf[0])) == "y"
+}
+
+// ShowPausePending acknowledges a Ctrl+C during a tool-using turn
+func ShowPausePending() {
+	notice(styleWarn, "Pausing after the current step (Ctrl+C again to abort the turn)...")
+}
+
+// ShowPauseIgnored tells the user a Ctrl+C pause came after the last step of the turn
+func ShowPauseIgnored() {
+	notice(styleDim, "Pause ignored: the turn had already finished its last step.")
+}
+
+// AskSteering asks how to go on with a paused turn: "continue", "steer" with new
+// instructions, or "abort"
+func AskSteering() (string, string) {
+	fmt.Printf("\nPaused. [c]ontinue / [s]teer with new instructions / [a]bort: ")
+	switch strings.ToLower(strings.TrimSpace(readLine())) {
+	case "s", "steer":
+		fmt.Print("New instructions: ")
+		return "steer", strings.TrimSpace(readLine())
+	case "a", "abort":
+		return "abort", ""
+	default:
+		return "continue", ""
+	}
+}
+
+// AskSourceSelection asks which of the listed search results to use
+func AskSourceSelection() string {
+	fmt.Printf("\nUse which sources? (e.g. 1,3-4; Enter for all): ")
+	return strings.TrimSpace(readLine())
+}
+
+// readLine reads one line from stdin a byte at a time, so nothing after it is
+// buffered away from the interactive prompt
+func readLine() string {
+	var line []byte
+	var buf [1]byte
+	for {
+		n, err := os.Stdin.Read(buf[:])
+		if n == 0 || err != nil || buf[0] == '\n' {
+			return strings.TrimSuffix(string(line), "\r")
+		}
+		line = append(line, buf[0])
+	}
+}
+
+// ShowDangerousEnabled explains what /allow-dangerous unblocked
+func ShowDangerousEnabled(categories []string) {
+	fmt.Println("⚠️  Dangerous commands enabled for the rest of this session")
+	fmt.Println("These become runnable, each after you confirm it:")
+	for _, c := range categories {
+		fmt.Printf("  - %s\n", c)
+	}
+	fmt.Println("Use /disallow-dangerous to block them again.")
+}
+
 // ShowPermissionSettings displays current permission settings
-func ShowPermissionSettings(settings map[string]interface{}) {
+func ShowPermissionSettings(settings map[string]interface{}, timeout time.Duration) {
 	fmt.Println("Permission Settings:")
 	fmt.Printf("  Auto-allow safe commands: %v\n", settings["auto_allow_reads"])
-	fmt.Printf("  Dangerous mode enabled:   %v\n", settings["dangerous_enabled"])
+	dangerous := "blocked (/allow-dangerous to enable)"
+	if enabled, _ := settings["dangerous_enabled"].(bool); enabled {
+		dangerous = "allowed with confirmation (/lock to block)"
+	}
+	fmt.Printf("  Dangerous commands:       %s\n", dangerous)
+	if dryRun, _ := settings["dry_run"].(bool); dryRun {
+		fmt.Println("  Dry run:                  on (commands are shown, not run)")
+	}
 	fmt.Printf("  Commands in allowlist:    %v\n", settings["allowlist_count"])
+	fmt.Printf("  Allowlisted prefixes:     %v\n", settings["prefix_count"])
+	fmt.Printf("  Denylisted commands:      %v\n", settings["denylist_count"])
+	fmt.Printf("  Command timeout:          %s\n", timeout)
 }
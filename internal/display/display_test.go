@@ -0,0 +1,114 @@
+package display
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestShortenURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		max  int
+		want string
+	}{
+		{"short unchanged", "https://go.dev/doc/", 60, "https://go.dev/doc/"},
+		{"exact length unchanged", "https://example.com/abcde", 25, "https://example.com/abcde"},
+		{"keeps host and tail", "https://example.com/a/very/long/path/to/the/page.html", 40, "https://example.com…ath/to/the/page.html"},
+		{"long host cut", "https://a-really-long-subdomain.example.com/page", 30, "https://a-really-long-subdoma…"},
+		{"not a URL", strings.Repeat("x", 20), 10, "xxxxxxxxx…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := shortenURL(tt.url, tt.max)
+			if got != tt.want {
+				t.Errorf("shortenURL(%q, %d) = %q, want %q", tt.url, tt.max, got, tt.want)
+			}
+			if n := utf8.RuneCountInString(got); n > tt.max {
+				t.Errorf("shortenURL(%q, %d) has %d runes, want at most %d", tt.url, tt.max, n, tt.max)
+			}
+		})
+	}
+}
+
+func TestDisplayURLDefaultUnchanged(t *testing.T) {
+	long := "https://example.com/" + strings.Repeat("a", 100)
+	if got := displayURL(long); got != long {
+		t.Errorf("displayURL without --short-urls = %q, want full URL", got)
+	}
+}
+
+func TestNoColor(t *testing.T) {
+	t.Cleanup(func() { SetNoColor(false) })
+
+	t.Setenv("NO_COLOR", "")
+	if !colorDisabled() {
+		t.Error("NO_COLOR set (even empty) should disable colors")
+	}
+
+	SetNoColor(true)
+	if colorEnabled() {
+		t.Error("colorEnabled() with --no-color = true")
+	}
+	if sp := NewSpinner("x"); !sp.disabled {
+		t.Error("spinner should be disabled with --no-color")
+	}
+}
+
+// captureStderr returns what fn writes on stderr
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+
+	out := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		out <- string(data)
+	}()
+	fn()
+	_ = w.Close()
+	return <-out
+}
+
+func TestNotices(t *testing.T) {
+	t.Cleanup(func() {
+		SetQuietNotices(false)
+		SetBare(false)
+	})
+
+	tests := []struct {
+		name  string
+		quiet bool
+		bare  bool
+		want  string
+	}{
+		{"shown", false, false, "Note: Tavily API key 1/2 failed, switching to key 2/2\nError: boom\n"},
+		{"--quiet-notices keeps errors", true, false, "Error: boom\n"},
+		{"--bare keeps errors", false, true, "Error: boom\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetQuietNotices(tt.quiet)
+			SetBare(tt.bare)
+			// A pipe is not a terminal, so notices are never colored here
+			got := captureStderr(t, func() {
+				ShowKeyRotation("Tavily", 1, 2, 2)
+				ShowError("boom")
+			})
+			if got != tt.want {
+				t.Errorf("stderr = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -36,19 +36,86 @@ var safePatterns = []*regexp.Regexp{
 	regexp.MustCompile(`^kubectl\s+(get|describe|logs)`),
 }
 
+// dangerousPattern is a blocked command pattern and the category /allow-dangerous reports it under
+type dangerousPattern struct {
+	re       *regexp.Regexp
+	category string
+}
+
 // Dangerous command patterns that are blocked by default
-var dangerousPatterns = []*regexp.Regexp{
-	regexp.MustCompile(`rm\s+(-[rf]*\s+)?/`),       // rm -rf / or variations
-	regexp.MustCompile(`sudo`),                     // Any sudo command
-	regexp.MustCompile(`dd\s+if=`),                 // dd commands
-	regexp.MustCompile(`mkfs`),                     // Format filesystem
-	regexp.MustCompile(`:\(\)\{`),                  // Fork bomb
-	regexp.MustCompile(`curl.*\|\s*(sh|bash|zsh)`), // Pipe to shell
-	regexp.MustCompile(`wget.*\|\s*(sh|bash|zsh)`), // Pipe to shell
-	regexp.MustCompile(`>\s*/dev/sd`),              // Write to disk device
-	regexp.MustCompile(`chmod.*777`),               // Overly permissive chmod
-	regexp.MustCompile(`chown.*-R\s+`),             // Recursive ownership change
-	regexp.MustCompile(`eval.*\$`),                 // Eval with variables
+var dangerousPatterns = []dangerousPattern{
+	{regexp.MustCompile(`rm\s+(-[rf]*\s+)?/`), "Deleting absolute paths (rm /...)"},
+	{regexp.MustCompile(`sudo`), "Anything run with sudo"},
+	{regexp.MustCompile(`dd\s+if=`), "Raw disk copies and writes (dd, > /dev/sd*)"},
+	{regexp.MustCompile(`mkfs`), "Formatting filesystems (mkfs)"},
+	{regexp.MustCompile(`:\(\)\{`), "Fork bombs"},
+	{regexp.MustCompile(`curl.*\|\s*(sh|bash|zsh)`), "Piping downloads into a shell (curl/wget | sh)"},
+	{regexp.MustCompile(`wget.*\|\s*(sh|bash|zsh)`), "Piping downloads into a shell (curl/wget | sh)"},
+	{regexp.MustCompile(`>\s*/dev/sd`), "Raw disk copies and writes (dd, > /dev/sd*)"},
+	{regexp.MustCompile(`chmod.*777`), "World-writable permissions (chmod 777)"},
+	{regexp.MustCompile(`chown.*-R\s+`), "Recursive ownership changes (chown -R)"},
+	{regexp.MustCompile(`eval.*\$`), "eval of variables"},
+}
+
+// DangerousCategories lists the kinds of commands blocked by default, in pattern order
+func DangerousCategories() []string {
+	var categories []string
+	seen := make(map[string]bool)
+	for _, p := range dangerousPatterns {
+		if !seen[p.category] {
+			seen[p.category] = true
+			categories = append(categories, p.category)
+		}
+	}
+	return categories
+}
+
+// commandExplanation says in plain words what commands matching re do
+type commandExplanation struct {
+	re          *regexp.Regexp
+	explanation string
+}
+
+// commandExplanations describe common state-changing commands for the confirmation
+// prompt. The first match wins, so specific forms come before general ones.
+var commandExplanations = []commandExplanation{
+	{regexp.MustCompile(`^git\s+reset\s+.*--hard`), "Discards all uncommitted changes to tracked files"},
+	{regexp.MustCompile(`^git\s+reset\b`), "Moves the branch pointer and unstages changes"},
+	{regexp.MustCompile(`^git\s+clean\s+.*-[a-z]*f`), "Permanently deletes untracked files"},
+	{regexp.MustCompile(`^git\s+push\s+.*(--force|-f\b)`), "Overwrites the remote branch, discarding commits others may have pushed"},
+	{regexp.MustCompile(`^git\s+push\b`), "Uploads local commits to the remote repository"},
+	{regexp.MustCompile(`^git\s+(checkout\s+--(\s|$)|restore\b)`), "Discards uncommitted changes to the given files"},
+	{regexp.MustCompile(`^git\s+(checkout|switch)\b`), "Switches branches or commits in the working tree"},
+	{regexp.MustCompile(`^git\s+commit\b`), "Records the staged changes as a new commit"},
+	{regexp.MustCompile(`^git\s+rebase\b`), "Rewrites commit history onto another base"},
+	{regexp.MustCompile(`^git\s+stash\b`), "Sets uncommitted changes aside and reverts the working tree"},
+	{regexp.MustCompile(`^git\s+(pull|merge)\b`), "Merges other changes into the current branch"},
+	{regexp.MustCompile(`^rm\s+.*-[a-zA-Z]*[rR]`), "Deletes files and directories recursively, without a trash to restore from"},
+	{regexp.MustCompile(`^rm\b`), "Deletes files permanently, without a trash to restore from"},
+	{regexp.MustCompile(`^mv\b`), "Moves or renames files, replacing any existing target"},
+	{regexp.MustCompile(`^cp\b`), "Copies files, replacing any existing target"},
+	{regexp.MustCompile(`^sed\s+.*-i`), "Edits files in place"},
+	{regexp.MustCompile(`^chmod\b`), "Changes file permissions"},
+	{regexp.MustCompile(`^(kill|pkill|killall)\b`), "Stops running processes"},
+	{regexp.MustCompile(`^(npm|yarn|pnpm)\s+(install|i|add)\b|^pip3?\s+install\b|^go\s+(get|install)\b|^cargo\s+(add|install)\b|^brew\s+install\b`), "Downloads and installs packages, which may run their install scripts"},
+	{regexp.MustCompile(`^docker\s+(system|image|container|volume)\s+prune\b`), "Deletes unused Docker data"},
+	{regexp.MustCompile(`^docker\s+(rm|rmi)\b`), "Deletes Docker containers or images"},
+	{regexp.MustCompile(`^kubectl\s+delete\b`), "Deletes Kubernetes resources from the cluster"},
+	{regexp.MustCompile(`^kubectl\s+apply\b`), "Creates or updates Kubernetes resources in the cluster"},
+	{regexp.MustCompile(`^terraform\s+destroy\b`), "Destroys all infrastructure managed by this configuration"},
+	{regexp.MustCompile(`^terraform\s+apply\b`), "Creates, changes or destroys infrastructure to match the configuration"},
+}
+
+// ExplainCommand returns a short plain-language description of what a command
+// does, or "" when it is not one of the common commands it knows
+func ExplainCommand(cmd string) string {
+	cmd = strings.TrimSpace(cmd)
+	for _, e := range commandExplanations {
+		if e.re.MatchString(cmd) {
+			return e.explanation
+		}
+	}
+	return ""
 }
 
 // ClassifyCommand determines the risk level of a shell command
@@ -61,7 +128,7 @@ func ClassifyCommand(cmd string) RiskLevel {
 
 	// Check dangerous patterns first (highest priority)
 	for _, pattern := range dangerousPatterns {
-		if pattern.MatchString(cmd) {
+		if pattern.re.MatchString(cmd) {
 			return Dangerous
 		}
 	}
@@ -74,3 +74,47 @@ func TestGetRiskDescription(t *testing.T) {
 		})
 	}
 }
+
+func TestDangerousCategories(t *testing.T) {
+	categories := DangerousCategories()
+	seen := make(map[string]bool)
+	for _, c := range categories {
+		if seen[c] {
+			t.Errorf("category %q listed twice", c)
+		}
+		seen[c] = true
+	}
+	for _, p := range dangerousPatterns {
+		if !seen[p.category] {
+			t.Errorf("pattern %s has unlisted category %q", p.re, p.category)
+		}
+	}
+}
+
+func TestExplainCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"git reset --hard HEAD~1", "Discards all uncommitted changes to tracked files"},
+		{"git reset HEAD file.go", "Moves the branch pointer and unstages changes"},
+		{"git push --force origin main", "Overwrites the remote branch, discarding commits others may have pushed"},
+		{"git push origin main", "Uploads local commits to the remote repository"},
+		{"git checkout -- main.go", "Discards uncommitted changes to the given files"},
+		{"git checkout feature", "Switches branches or commits in the working tree"},
+		{"rm -rf build", "Deletes files and directories recursively, without a trash to restore from"},
+		{"rm temp.txt", "Deletes files permanently, without a trash to restore from"},
+		{"  npm install express", "Downloads and installs packages, which may run their install scripts"},
+		{"kubectl delete pod web-1", "Deletes Kubernetes resources from the cluster"},
+		{"make build", ""},
+		{"rmdir empty", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if got := ExplainCommand(tt.command); got != tt.want {
+				t.Errorf("ExplainCommand(%q) = %q, want %q", tt.command, got, tt.want)
+			}
+		})
+	}
+}
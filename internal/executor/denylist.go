@@ -0,0 +1,153 @@
+package executor
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Reasons CheckPermission gives for denylisted commands
+const (
+	deniedReason          = "explicitly denied"
+	deniedUncheckedReason = "explicitly denied: subshells, substitutions and sh -c cannot be checked against the denylist"
+)
+
+// commandSeparators split a command line into the simple commands it runs
+const commandSeparators = ";&|\n{}"
+
+// uncheckedSyntax is shell syntax that runs text the denylist cannot see as words:
+// command substitution, subshells and process substitution
+var uncheckedSyntax = []string{"$(", "`", "(", ")"}
+
+// assignmentPattern matches a leading VAR=value assignment
+var assignmentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// commandWrappers run the command that follows them
+var commandWrappers = map[string]bool{
+	"command": true, "env": true, "exec": true, "nohup": true, "time": true, "builtin": true,
+}
+
+// shellPrograms run their -c argument as a command line
+var shellPrograms = map[string]bool{
+	"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true, "fish": true,
+}
+
+// isDenied checks each simple command in a command line against the denylist.
+// Entries match on whole words, so "git push" also denies "git push --force".
+// Commands are normalized first: whitespace, quotes, leading VAR=value assignments,
+// wrappers like command and env, and the program's directory are ignored, and
+// options before a subcommand are skipped ("git -C . push"). A command line that
+// hides commands in a subshell, a substitution, eval or sh -c is denied whenever
+// the denylist is in use. Caller must hold the read lock.
+func (pm *PermissionManager) isDenied(cmd string) (bool, string) {
+	if len(pm.denylist) == 0 && len(pm.denyPrefixes) == 0 {
+		return false, ""
+	}
+	for _, syntax := range uncheckedSyntax {
+		if strings.Contains(cmd, syntax) {
+			return true, deniedUncheckedReason
+		}
+	}
+
+	segments := strings.FieldsFunc(cmd, func(r rune) bool {
+		return strings.ContainsRune(commandSeparators, r)
+	})
+	for _, segment := range segments {
+		words := normalizeCommand(segment)
+		if len(words) == 0 {
+			continue
+		}
+		if words[0] == "eval" || (shellPrograms[words[0]] && hasShortFlag(words[1:], 'c')) {
+			return true, deniedUncheckedReason
+		}
+		for _, candidate := range commandForms(words) {
+			if pm.matchesDenyEntry(candidate) {
+				return true, deniedReason
+			}
+		}
+	}
+	return false, ""
+}
+
+// matchesDenyEntry reports whether words start with the words of a denylist entry.
+// Caller must hold the read lock.
+func (pm *PermissionManager) matchesDenyEntry(words []string) bool {
+	for entry := range pm.denylist {
+		if hasWordPrefix(words, normalizeCommand(entry)) {
+			return true
+		}
+	}
+	for _, prefix := range pm.denyPrefixes {
+		if hasWordPrefix(words, normalizeCommand(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeCommand splits a simple command into words without quotes, leading
+// assignments or wrappers, and with the program's directory dropped
+func normalizeCommand(cmd string) []string {
+	words := strings.Fields(strings.NewReplacer(`"`, "", "'", "", `\`, "").Replace(cmd))
+	for len(words) > 0 {
+		switch {
+		case assignmentPattern.MatchString(words[0]):
+			words = words[1:]
+		case commandWrappers[path.Base(words[0])]:
+			words = words[1:]
+			for len(words) > 0 && strings.HasPrefix(words[0], "-") { // e.g. env -i
+				words = words[1:]
+			}
+		default:
+			words[0] = path.Base(words[0])
+			return words
+		}
+	}
+	return nil
+}
+
+// commandForms returns words as written and with the options between the program and
+// its subcommand skipped, both without and with a value after each short option
+// (so "git -C . push" and "npm -g publish" both reach their subcommand)
+func commandForms(words []string) [][]string {
+	forms := [][]string{words}
+	if len(words) < 2 || !strings.HasPrefix(words[1], "-") {
+		return forms
+	}
+	for _, skipValues := range []bool{false, true} {
+		i := 1
+		for i < len(words) && strings.HasPrefix(words[i], "-") {
+			if skipValues && len(words[i]) == 2 && words[i] != "--" {
+				i++ // "-C ." takes a value
+			}
+			i++
+		}
+		if i < len(words) {
+			forms = append(forms, append([]string{words[0]}, words[i:]...))
+		}
+	}
+	return forms
+}
+
+// hasWordPrefix reports whether words begin with all of prefix's words
+func hasWordPrefix(words, prefix []string) bool {
+	if len(prefix) == 0 || len(words) < len(prefix) {
+		return false
+	}
+	for i, w := range prefix {
+		if words[i] != w {
+			return false
+		}
+	}
+	return true
+}
+
+// hasShortFlag reports whether words include a short option cluster with flag, e.g. -c or -lc
+func hasShortFlag(words []string, flag rune) bool {
+	for _, word := range words {
+		if strings.HasPrefix(word, "-") && !strings.HasPrefix(word, "--") && strings.ContainsRune(word[1:], flag) {
+			return true
+		}
+	}
+	return false
+}
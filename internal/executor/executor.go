@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os/exec"
+	"sync"
 	"time"
 )
 
@@ -11,6 +12,11 @@ import (
 type Executor struct {
 	permissions *PermissionManager
 	timeout     time.Duration
+
+	// Results of Safe (read-only) commands, reused until ClearCache
+	cacheEnabled bool
+	cache        map[string]*ExecutionResult
+	cacheMu      sync.Mutex
 }
 
 // NewExecutor creates a new command executor with default settings
@@ -18,6 +24,7 @@ func NewExecutor() *Executor {
 	return &Executor{
 		permissions: NewPermissionManager(),
 		timeout:     30 * time.Second, // Default 30 second timeout
+		cache:       make(map[string]*ExecutionResult),
 	}
 }
 
@@ -28,10 +35,29 @@ type ExecutionResult struct {
 	Error    error
 	ExitCode int
 	Duration time.Duration
+	Cached   bool // Result was reused from an earlier identical Safe command
 }
 
-// Execute runs a shell command and returns the result
+// Execute runs a shell command and returns the result.
+// With caching enabled, a repeated Safe command returns the earlier successful result.
+// Running any command that is not Safe clears the cache, since it may have changed
+// what the cached reads would show.
 func (e *Executor) Execute(ctx context.Context, command string) (*ExecutionResult, error) {
+	if cached, ok := e.cachedResult(command); ok {
+		return cached, nil
+	}
+
+	result, err := e.run(ctx, command)
+	if ClassifyCommand(command) != Safe {
+		e.ClearCache()
+	} else if err == nil && result.IsSuccess() {
+		e.storeResult(command, result)
+	}
+	return result, err
+}
+
+// run executes a shell command without consulting the cache
+func (e *Executor) run(ctx context.Context, command string) (*ExecutionResult, error) {
 	start := time.Now()
 
 	// Create context with timeout
@@ -40,6 +66,9 @@ func (e *Executor) Execute(ctx context.Context, command string) (*ExecutionResul
 
 	// Execute command using shell
 	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	isolateProcessGroup(cmd)
+	// Killing sh on timeout can leave its children holding the output pipe; stop waiting for them
+	cmd.WaitDelay = time.Second
 	output, err := cmd.CombinedOutput()
 
 	result := &ExecutionResult{
@@ -61,6 +90,55 @@ func (e *Executor) Execute(ctx context.Context, command string) (*ExecutionResul
 	return result, nil
 }
 
+// EnableCache turns the Safe command result cache on or off
+func (e *Executor) EnableCache(enabled bool) {
+	e.cacheMu.Lock()
+	defer e.cacheMu.Unlock()
+	e.cacheEnabled = enabled
+	e.cache = make(map[string]*ExecutionResult)
+}
+
+// ClearCache drops all cached results, e.g. at the end of a turn so later reads are fresh
+func (e *Executor) ClearCache() {
+	e.cacheMu.Lock()
+	defer e.cacheMu.Unlock()
+	e.cache = make(map[string]*ExecutionResult)
+}
+
+// IsCached reports whether Execute would return a cached result for the command
+func (e *Executor) IsCached(command string) bool {
+	_, ok := e.cachedResult(command)
+	return ok
+}
+
+// cachedResult returns a copy of the cached result for a command, if any
+func (e *Executor) cachedResult(command string) (*ExecutionResult, bool) {
+	e.cacheMu.Lock()
+	defer e.cacheMu.Unlock()
+	if !e.cacheEnabled {
+		return nil, false
+	}
+	result, ok := e.cache[command]
+	if !ok {
+		return nil, false
+	}
+	cached := *result
+	cached.Cached = true
+	return &cached, true
+}
+
+// storeResult caches a result if caching is on and the command is Safe
+func (e *Executor) storeResult(command string, result *ExecutionResult) {
+	if ClassifyCommand(command) != Safe {
+		return
+	}
+	e.cacheMu.Lock()
+	defer e.cacheMu.Unlock()
+	if e.cacheEnabled {
+		e.cache[command] = result
+	}
+}
+
 // GetPermissionManager returns the permission manager
 func (e *Executor) GetPermissionManager() *PermissionManager {
 	return e.permissions
@@ -71,6 +149,11 @@ func (e *Executor) SetTimeout(timeout time.Duration) {
 	e.timeout = timeout
 }
 
+// Timeout returns the command execution timeout
+func (e *Executor) Timeout() time.Duration {
+	return e.timeout
+}
+
 // FormatResult formats an execution result for display
 func (r *ExecutionResult) FormatResult() string {
 	if r.Error != nil && r.ExitCode != 0 {
@@ -0,0 +1,115 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecuteCache(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ls := "ls " + dir
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		command    string
+		wantCached bool
+	}{
+		{"safe command cached", true, ls, true},
+		{"cache disabled", false, ls, false},
+		{"non-safe command not cached", true, "touch " + filepath.Join(dir, "b.txt"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExecutor()
+			e.EnableCache(tt.enabled)
+			ctx := context.Background()
+
+			first, err := e.Execute(ctx, tt.command)
+			if err != nil || first.Cached {
+				t.Fatalf("first Execute() = %+v, %v; want fresh result", first, err)
+			}
+			second, err := e.Execute(ctx, tt.command)
+			if err != nil {
+				t.Fatalf("second Execute() error = %v", err)
+			}
+			if second.Cached != tt.wantCached {
+				t.Errorf("second Execute() Cached = %v, want %v", second.Cached, tt.wantCached)
+			}
+			if tt.wantCached && second.Output != first.Output {
+				t.Errorf("cached output = %q, want %q", second.Output, first.Output)
+			}
+		})
+	}
+}
+
+func TestClearCache(t *testing.T) {
+	e := NewExecutor()
+	e.EnableCache(true)
+	ctx := context.Background()
+
+	if _, err := e.Execute(ctx, "pwd"); err != nil {
+		t.Fatal(err)
+	}
+	if !e.IsCached("pwd") {
+		t.Fatal("pwd should be cached after running")
+	}
+	e.ClearCache()
+	if e.IsCached("pwd") {
+		t.Error("cache should be empty after ClearCache")
+	}
+}
+
+func TestSetTimeout(t *testing.T) {
+	e := NewExecutor()
+	e.SetTimeout(100 * time.Millisecond)
+	if got := e.Timeout(); got != 100*time.Millisecond {
+		t.Fatalf("Timeout() = %s, want 100ms", got)
+	}
+
+	result, err := e.Execute(context.Background(), "sleep 5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsSuccess() {
+		t.Error("command outliving the timeout should fail")
+	}
+	if result.Duration > 2*time.Second {
+		t.Errorf("command ran for %s, want it killed at the timeout", result.Duration)
+	}
+}
+
+func TestCacheClearedByMutatingCommand(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "x")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	e := NewExecutor()
+	e.EnableCache(true)
+	ctx := context.Background()
+	ls := "ls " + dir
+
+	before, err := e.Execute(ctx, ls)
+	if err != nil || !strings.Contains(before.Output, "x") {
+		t.Fatalf("first ls = %+v, %v", before, err)
+	}
+	if _, err := e.Execute(ctx, "rm "+file); err != nil {
+		t.Fatal(err)
+	}
+	after, err := e.Execute(ctx, ls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Cached || strings.Contains(after.Output, "x") {
+		t.Errorf("ls after rm = %+v, want a fresh listing without x", after)
+	}
+}
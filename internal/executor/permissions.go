@@ -1,31 +1,65 @@
 package executor
 
 import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 )
 
+// shellOperators are rejected in prefix-allowlisted commands so a trusted
+// prefix like "git log*" cannot be chained into something else; "$" covers
+// substitutions and $VAR/${...} expansion
+var shellOperators = []string{";", "&", "|", "`", "$", ">", "<", "\n"}
+
 // PermissionManager handles command execution permissions
 type PermissionManager struct {
 	mu               sync.RWMutex
 	alwaysAllow      map[string]bool
+	allowPrefixes    []string
+	denylist         map[string]bool
+	denyPrefixes     []string
 	dangerousEnabled bool
 	autoAllowReads   bool
+	dryRun           bool
 }
 
 // NewPermissionManager creates a new permission manager with safe defaults
 func NewPermissionManager() *PermissionManager {
 	return &PermissionManager{
 		alwaysAllow:    make(map[string]bool),
+		denylist:       make(map[string]bool),
 		autoAllowReads: true, // Default: auto-allow safe read-only commands
 	}
 }
 
+// DryRunReason is the CheckPermission reason for commands that dry-run mode lets through
+const DryRunReason = "Dry run: would run"
+
 // CheckPermission checks if a command is allowed to execute
 // Returns: (allowed, needsConfirm, reason)
+// In dry-run mode every command that is not blocked is allowed without confirmation
+// with DryRunReason; callers must then skip execution.
 func (pm *PermissionManager) CheckPermission(cmd string) (allowed bool, needsConfirm bool, reason string) {
 	pm.mu.RLock()
 	defer pm.mu.RUnlock()
 
+	allowed, needsConfirm, reason = pm.checkPermission(cmd)
+	if pm.dryRun && (allowed || needsConfirm) {
+		return true, false, DryRunReason
+	}
+	return allowed, needsConfirm, reason
+}
+
+// checkPermission applies the permission rules. Caller must hold the read lock.
+func (pm *PermissionManager) checkPermission(cmd string) (allowed bool, needsConfirm bool, reason string) {
+	// The denylist wins over everything, including the allowlist
+	if denied, reason := pm.isDenied(cmd); denied {
+		return false, false, reason
+	}
+
 	// Check if user previously said "always allow" for this specific command
 	if pm.alwaysAllow[cmd] {
 		return true, false, "Previously approved by user"
@@ -33,6 +67,11 @@ func (pm *PermissionManager) CheckPermission(cmd string) (allowed bool, needsCon
 
 	risk := ClassifyCommand(cmd)
 
+	// Prefix entries never override the dangerous classification
+	if risk != Dangerous && pm.matchesPrefix(cmd) {
+		return true, false, "Matches allowlisted prefix"
+	}
+
 	switch risk {
 	case Safe:
 		if pm.autoAllowReads {
@@ -60,6 +99,114 @@ func (pm *PermissionManager) AddToAllowlist(cmd string) {
 	pm.alwaysAllow[cmd] = true
 }
 
+// AddAllowPattern adds an allowlist entry; a trailing "*" makes it a prefix match
+func (pm *PermissionManager) AddAllowPattern(pattern string) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		pm.mu.Lock()
+		defer pm.mu.Unlock()
+		pm.allowPrefixes = append(pm.allowPrefixes, prefix)
+		return
+	}
+	pm.AddToAllowlist(pattern)
+}
+
+// AddToDenylist adds a command that is always blocked
+func (pm *PermissionManager) AddToDenylist(cmd string) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.denylist[cmd] = true
+}
+
+// AddDenyPattern adds a denylist entry; a trailing "*" makes it a prefix match
+func (pm *PermissionManager) AddDenyPattern(pattern string) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		pm.mu.Lock()
+		defer pm.mu.Unlock()
+		pm.denyPrefixes = append(pm.denyPrefixes, prefix)
+		return
+	}
+	pm.AddToDenylist(pattern)
+}
+
+// LoadAllowlistFile seeds the allowlist from a file with one command or prefix per line.
+// Blank lines and lines starting with "#" are ignored. A missing file is not an error.
+func (pm *PermissionManager) LoadAllowlistFile(path string) error {
+	return loadPatternFile(path, pm.AddAllowPattern)
+}
+
+// LoadDenylistFile seeds the denylist from a file in the allowlist file's format
+func (pm *PermissionManager) LoadDenylistFile(path string) error {
+	return loadPatternFile(path, pm.AddDenyPattern)
+}
+
+// AppendPatternFile adds an allowlist or denylist entry to the end of a pattern file,
+// creating the file and its directory if needed
+func AppendPatternFile(path, pattern string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, strings.TrimSpace(pattern)); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// loadPatternFile calls add for each entry in a file with one command or prefix per line
+func loadPatternFile(path string, add func(string)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		add(line)
+	}
+	return scanner.Err()
+}
+
+// matchesPrefix checks if a command starts with an allowlisted prefix followed by
+// a space or nothing. Caller must hold the read lock.
+func (pm *PermissionManager) matchesPrefix(cmd string) bool {
+	if len(pm.allowPrefixes) == 0 {
+		return false
+	}
+	for _, op := range shellOperators {
+		if strings.Contains(cmd, op) {
+			return false
+		}
+	}
+	for _, prefix := range pm.allowPrefixes {
+		rest, ok := strings.CutPrefix(cmd, prefix)
+		// "git log*" matches "git log" and "git log -5", not "git logx"
+		if ok && (rest == "" || rest[0] == ' ' || strings.HasSuffix(prefix, " ")) {
+			return true
+		}
+	}
+	return false
+}
+
 // EnableDangerous enables execution of dangerous commands (with confirmation)
 func (pm *PermissionManager) EnableDangerous() {
 	pm.mu.Lock()
@@ -81,6 +228,20 @@ func (pm *PermissionManager) SetAutoAllowReads(enabled bool) {
 	pm.autoAllowReads = enabled
 }
 
+// SetDryRun sets whether commands are only shown instead of executed
+func (pm *PermissionManager) SetDryRun(enabled bool) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.dryRun = enabled
+}
+
+// DryRun reports whether dry-run mode is on
+func (pm *PermissionManager) DryRun() bool {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.dryRun
+}
+
 // GetSettings returns current permission settings
 func (pm *PermissionManager) GetSettings() map[string]interface{} {
 	pm.mu.RLock()
@@ -90,6 +251,9 @@ func (pm *PermissionManager) GetSettings() map[string]interface{} {
 		"auto_allow_reads":  pm.autoAllowReads,
 		"dangerous_enabled": pm.dangerousEnabled,
 		"allowlist_count":   len(pm.alwaysAllow),
+		"prefix_count":      len(pm.allowPrefixes),
+		"denylist_count":    len(pm.denylist) + len(pm.denyPrefixes),
+		"dry_run":           pm.dryRun,
 	}
 }
 
@@ -98,4 +262,5 @@ func (pm *PermissionManager) ClearAllowlist() {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 	pm.alwaysAllow = make(map[string]bool)
+	pm.allowPrefixes = nil
 }
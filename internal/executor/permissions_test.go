@@ -0,0 +1,191 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckPermissionPrefix(t *testing.T) {
+	pm := NewPermissionManager()
+	pm.AddAllowPattern("npm test*")
+	pm.AddAllowPattern("go build")
+
+	tests := []struct {
+		name    string
+		command string
+		allowed bool
+	}{
+		{"exact match", "go build", true},
+		{"exact does not prefix", "go build ./...", false},
+		{"prefix match", "npm test", true},
+		{"prefix with args", "npm test -- --watch", true},
+		{"prefix chained", "npm test && rm -rf build", false},
+		{"prefix piped", "npm test | sh", false},
+		{"unrelated", "npm install", false},
+		{"dangerous never allowed", "npm test; sudo reboot", false},
+		{"prefix needs word boundary", "npm testfoo", false},
+		{"variable expansion", "npm test $HOME", false},
+		{"braced expansion", "npm test ${PATH}", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, _, _ := pm.CheckPermission(tt.command)
+			if allowed != tt.allowed {
+				t.Errorf("CheckPermission(%q) allowed = %v, want %v", tt.command, allowed, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestLoadAllowlistFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "allowlist")
+	content := "# trusted commands\nmake lint\n\ngit fetch*\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	pm := NewPermissionManager()
+	if err := pm.LoadAllowlistFile(path); err != nil {
+		t.Fatalf("LoadAllowlistFile() error = %v", err)
+	}
+
+	if allowed, _, _ := pm.CheckPermission("make lint"); !allowed {
+		t.Error("expected exact entry to be allowed")
+	}
+	if allowed, _, _ := pm.CheckPermission("git fetch origin"); !allowed {
+		t.Error("expected prefix entry to be allowed")
+	}
+	if allowed, _, _ := pm.CheckPermission("git fetchx"); allowed {
+		t.Error("git fetchx should not match the git fetch prefix")
+	}
+
+	if err := pm.LoadAllowlistFile(filepath.Join(t.TempDir(), "missing")); err != nil {
+		t.Errorf("missing file should not be an error, got %v", err)
+	}
+}
+
+func TestDisableDangerous(t *testing.T) {
+	pm := NewPermissionManager()
+	const cmd = "sudo reboot"
+
+	if _, needsConfirm, _ := pm.CheckPermission(cmd); needsConfirm {
+		t.Fatal("dangerous command should be blocked by default")
+	}
+	pm.EnableDangerous()
+	if allowed, needsConfirm, _ := pm.CheckPermission(cmd); allowed || !needsConfirm {
+		t.Fatalf("after EnableDangerous: allowed, needsConfirm = %v, %v, want false, true", allowed, needsConfirm)
+	}
+	pm.DisableDangerous()
+	if allowed, needsConfirm, _ := pm.CheckPermission(cmd); allowed || needsConfirm {
+		t.Errorf("after DisableDangerous: allowed, needsConfirm = %v, %v, want blocked", allowed, needsConfirm)
+	}
+	if pm.GetSettings()["dangerous_enabled"] != false {
+		t.Error("settings still report dangerous mode enabled")
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	pm := NewPermissionManager()
+	pm.SetDryRun(true)
+
+	tests := []struct {
+		cmd         string
+		wantAllowed bool
+	}{
+		{"ls -la", true},
+		{"rm file.txt", true},
+		{"sudo reboot", false}, // Blocked commands stay blocked
+	}
+	for _, tt := range tests {
+		t.Run(tt.cmd, func(t *testing.T) {
+			allowed, needsConfirm, reason := pm.CheckPermission(tt.cmd)
+			if allowed != tt.wantAllowed || needsConfirm {
+				t.Errorf("allowed, needsConfirm = %v, %v, want %v, false", allowed, needsConfirm, tt.wantAllowed)
+			}
+			if allowed && reason != DryRunReason {
+				t.Errorf("reason = %q, want %q", reason, DryRunReason)
+			}
+		})
+	}
+
+	pm.SetDryRun(false)
+	if _, needsConfirm, _ := pm.CheckPermission("rm file.txt"); !needsConfirm {
+		t.Error("after SetDryRun(false): rm should need confirmation again")
+	}
+	if pm.DryRun() || pm.GetSettings()["dry_run"] != false {
+		t.Error("dry run still reported on")
+	}
+}
+
+func TestDenylist(t *testing.T) {
+	pm := NewPermissionManager()
+	pm.AddToAllowlist("git push")
+	pm.AddDenyPattern("git push*")
+	pm.AddDenyPattern("make deploy")
+
+	tests := []struct {
+		name    string
+		command string
+		denied  bool
+	}{
+		{"overrides allowlist", "git push", true},
+		{"prefix with args", "git push origin main", true},
+		{"chained", "git add . && git push", true},
+		{"exact", "make deploy", true},
+		{"exact with args", "make deploy --env prod", true},
+		{"exact does not prefix", "make deploy-docs", false},
+		{"prefix on word boundary", "git pushx", false},
+		{"safe command still allowed", "git status", false},
+		{"extra whitespace", "git  push", true},
+		{"subshell", "(git push)", true},
+		{"command substitution", "echo $(git push)", true},
+		{"backticks", "echo `git push`", true},
+		{"env assignment", "FOO=1 git push", true},
+		{"sh -c", "sh -c 'git push'", true},
+		{"bash -lc", `bash -lc "git push"`, true},
+		{"eval", "eval git push", true},
+		{"command builtin", "command git push", true},
+		{"env wrapper", "env -i GIT_TRACE=1 git push", true},
+		{"full path", "/usr/bin/git push", true},
+		{"global option", "git -C . push", true},
+		{"quoted subcommand", "git 'push'", true},
+		{"grouped", "{ git push; }", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, needsConfirm, reason := pm.CheckPermission(tt.command)
+			denied := !allowed && !needsConfirm && strings.HasPrefix(reason, "explicitly denied")
+			if denied != tt.denied {
+				t.Errorf("CheckPermission(%q) = %v, %v, %q, want denied = %v", tt.command, allowed, needsConfirm, reason, tt.denied)
+			}
+		})
+	}
+	if _, _, reason := NewPermissionManager().CheckPermission("echo $(date)"); strings.HasPrefix(reason, "explicitly denied") {
+		t.Error("substitutions should only be denied while the denylist is in use")
+	}
+	if pm.GetSettings()["denylist_count"] != 2 {
+		t.Errorf("denylist_count = %v, want 2", pm.GetSettings()["denylist_count"])
+	}
+}
+
+func TestDenylistFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "denylist")
+	for _, pattern := range []string{"git push*", "npm publish"} {
+		if err := AppendPatternFile(path, pattern); err != nil {
+			t.Fatalf("AppendPatternFile() error = %v", err)
+		}
+	}
+
+	pm := NewPermissionManager()
+	if err := pm.LoadDenylistFile(path); err != nil {
+		t.Fatalf("LoadDenylistFile() error = %v", err)
+	}
+	for _, cmd := range []string{"git push --force", "npm publish"} {
+		if allowed, needsConfirm, _ := pm.CheckPermission(cmd); allowed || needsConfirm {
+			t.Errorf("%q should be denied after loading the file", cmd)
+		}
+	}
+}
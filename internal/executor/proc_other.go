@@ -0,0 +1,8 @@
+//go:build !unix
+
+package executor
+
+import "os/exec"
+
+// isolateProcessGroup is a no-op where process groups are not available
+func isolateProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package executor
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// isolateProcessGroup runs cmd in its own process group, so a Ctrl+C at the terminal
+// (which pauses the turn) does not reach it. Cancelling the command kills the whole
+// group, including anything the shell started.
+func isolateProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,47 @@
+//go:build unix
+
+package executor
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCommandProcessGroup(t *testing.T) {
+	if _, err := exec.LookPath("ps"); err != nil {
+		t.Skip("ps not available")
+	}
+	e := NewExecutor()
+	result, err := e.Execute(context.Background(), "ps -o pgid= -p $$")
+	if err != nil || !result.IsSuccess() {
+		t.Fatalf("Execute() = %+v, %v", result, err)
+	}
+	pgid, err := strconv.Atoi(strings.TrimSpace(result.Output))
+	if err != nil {
+		t.Fatalf("unexpected ps output %q", result.Output)
+	}
+	if pgid == syscall.Getpgrp() {
+		t.Error("command runs in the CLI's process group, so Ctrl+C would reach it")
+	}
+}
+
+func TestCancelKillsProcessGroup(t *testing.T) {
+	e := NewExecutor()
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// The shell's background child holds the output pipe open; killing only sh would
+	// leave Execute waiting for WaitDelay
+	start := time.Now()
+	if _, err := e.Execute(ctx, "sleep 10 & sleep 10"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 900*time.Millisecond {
+		t.Errorf("cancelled command took %v, want the whole group killed promptly", elapsed)
+	}
+}
@@ -0,0 +1,27 @@
+//go:build keyvault
+
+package keyvault
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// DefaultCredential gets tokens from the azidentity default credential chain: environment
+// service principal, workload identity, managed identity, Azure CLI and Azure Developer CLI
+func DefaultCredential() Credential {
+	return func(ctx context.Context) (string, error) {
+		cred, err := azidentity.NewDefaultAzureCredential(nil)
+		if err != nil {
+			return "", fmt.Errorf("no Azure credential for Key Vault: %w", err)
+		}
+		token, err := cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{Scope}})
+		if err != nil {
+			return "", fmt.Errorf("no Azure credential for Key Vault: %w", err)
+		}
+		return token.Token, nil
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !keyvault
+
+package keyvault
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotSupported is returned by DefaultCredential in builds without the keyvault tag
+var ErrNotSupported = errors.New("not built with Key Vault support; rebuild with -tags keyvault")
+
+// DefaultCredential fails in builds without the keyvault tag, which leave out the Azure SDK
+func DefaultCredential() Credential {
+	return func(ctx context.Context) (string, error) {
+		return "", ErrNotSupported
+	}
+}
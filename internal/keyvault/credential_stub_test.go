@@ -0,0 +1,16 @@
+//go:build !keyvault
+
+package keyvault
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDefaultCredentialNotSupported(t *testing.T) {
+	client := NewClient("https://unused.vault.azure.net", DefaultCredential())
+	if _, err := client.GetSecret(context.Background(), "x"); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("GetSecret() error = %v, want ErrNotSupported", err)
+	}
+}
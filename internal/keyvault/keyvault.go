@@ -0,0 +1,94 @@
+// Package keyvault reads secrets from Azure Key Vault over its REST API, so the
+// endpoint and API key need not live in plaintext environment variables. Tokens
+// come from the azidentity default credential chain, which is only linked into
+// builds with the keyvault tag.
+package keyvault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// APIVersion is the Key Vault REST API version used for secret reads
+	APIVersion = "7.4"
+	// Scope is the token scope for Key Vault
+	Scope = "https://vault.azure.net/.default"
+)
+
+// Credential returns a bearer token for Key Vault
+type Credential func(ctx context.Context) (string, error)
+
+// Client reads secrets from one vault
+type Client struct {
+	vaultURL   string
+	credential Credential
+	httpClient *http.Client
+	token      string // Cached for the process; secrets are only read at startup
+}
+
+// NewClient creates a client for the vault at vaultURL, e.g. https://myvault.vault.azure.net
+func NewClient(vaultURL string, credential Credential) *Client {
+	return &Client{
+		vaultURL:   strings.TrimSuffix(vaultURL, "/"),
+		credential: credential,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// GetSecret returns the current value of the named secret
+func (c *Client) GetSecret(ctx context.Context, name string) (string, error) {
+	if c.token == "" {
+		token, err := c.credential(ctx)
+		if err != nil {
+			return "", err
+		}
+		c.token = token
+	}
+
+	reqURL := fmt.Sprintf("%s/secrets/%s?api-version=%s", c.vaultURL, url.PathEscape(name), APIVersion)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		errMsg := fmt.Sprintf("status code %d", resp.StatusCode)
+		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Message != "" {
+			errMsg = errResp.Error.Message
+		}
+		return "", fmt.Errorf("Key Vault error: %s", errMsg)
+	}
+
+	var secret struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	return secret.Value, nil
+}
@@ -0,0 +1,62 @@
+package keyvault
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetSecret(t *testing.T) {
+	tokenCalls := 0
+	cred := func(ctx context.Context) (string, error) {
+		tokenCalls++
+		return "test-token", nil
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.URL.Query().Get("api-version"); got != APIVersion {
+			t.Errorf("api-version = %q, want %q", got, APIVersion)
+		}
+		switch r.URL.Path {
+		case "/secrets/openai-endpoint":
+			_, _ = w.Write([]byte(`{"value":"https://example.openai.azure.com","id":"x"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":"SecretNotFound","message":"A secret with (name/id) missing was not found in this key vault."}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", cred)
+	ctx := context.Background()
+
+	got, err := client.GetSecret(ctx, "openai-endpoint")
+	if err != nil {
+		t.Fatalf("GetSecret() error = %v", err)
+	}
+	if got != "https://example.openai.azure.com" {
+		t.Errorf("GetSecret() = %q", got)
+	}
+
+	_, err = client.GetSecret(ctx, "missing")
+	if err == nil || !strings.Contains(err.Error(), "was not found") {
+		t.Errorf("GetSecret(missing) error = %v, want Key Vault message", err)
+	}
+	if tokenCalls != 1 {
+		t.Errorf("credential called %d times, want the token reused", tokenCalls)
+	}
+}
+
+func TestGetSecretCredentialError(t *testing.T) {
+	client := NewClient("https://unused.vault.azure.net", func(ctx context.Context) (string, error) {
+		return "", errors.New("not logged in")
+	})
+	if _, err := client.GetSecret(context.Background(), "x"); err == nil || !strings.Contains(err.Error(), "not logged in") {
+		t.Errorf("GetSecret() error = %v, want credential error", err)
+	}
+}
@@ -0,0 +1,143 @@
+// Package quota keeps a local, best-effort count of search requests per provider key
+// so free-tier usage can be estimated between provider dashboards.
+package quota
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Period kinds
+const (
+	PeriodMonthly = "monthly"
+	PeriodDaily   = "daily"
+)
+
+// Period describes when usage counters reset
+type Period struct {
+	Kind     string // PeriodMonthly or PeriodDaily
+	ResetDay int    // Day of month a monthly period starts on (1-28)
+}
+
+// ParsePeriod validates a period kind and reset day, applying defaults for empty values
+func ParsePeriod(kind string, resetDay int) (Period, error) {
+	if kind == "" {
+		kind = PeriodMonthly
+	}
+	if kind != PeriodMonthly && kind != PeriodDaily {
+		return Period{}, fmt.Errorf("invalid quota period: %s (use %s or %s)", kind, PeriodMonthly, PeriodDaily)
+	}
+	if resetDay == 0 {
+		resetDay = 1
+	}
+	if resetDay < 1 || resetDay > 28 {
+		return Period{}, fmt.Errorf("invalid quota reset day: %d (use 1-28)", resetDay)
+	}
+	return Period{Kind: kind, ResetDay: resetDay}, nil
+}
+
+// Start returns the beginning of the period containing now, in now's location
+func (p Period) Start(now time.Time) time.Time {
+	y, m, d := now.Date()
+	if p.Kind == PeriodDaily {
+		return time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	}
+	if d < p.ResetDay {
+		m--
+	}
+	return time.Date(y, m, p.ResetDay, 0, 0, 0, 0, now.Location())
+}
+
+// KeyUsage is the request count for one key within one period
+type KeyUsage struct {
+	PeriodStart string `json:"period_start"` // YYYY-MM-DD
+	Count       int    `json:"count"`
+}
+
+// Usage holds counters per provider, keyed by key fingerprint
+type Usage struct {
+	Providers map[string]map[string]KeyUsage `json:"providers"`
+}
+
+// Fingerprint identifies a key without storing it
+func Fingerprint(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Load reads usage from path. A missing file yields empty usage.
+func Load(path string) (*Usage, error) {
+	u := &Usage{Providers: make(map[string]map[string]KeyUsage)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return u, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, u); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if u.Providers == nil {
+		u.Providers = make(map[string]map[string]KeyUsage)
+	}
+	return u, nil
+}
+
+// Save writes usage to path, creating the parent directory if needed
+func (u *Usage) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(u, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Count returns the requests recorded for a key in the period containing now
+func (u *Usage) Count(provider, key string, p Period, now time.Time) int {
+	ku, ok := u.Providers[provider][Fingerprint(key)]
+	if !ok || ku.PeriodStart != p.Start(now).Format(time.DateOnly) {
+		return 0
+	}
+	return ku.Count
+}
+
+// Increment adds one request for a key, starting a new count when the period has rolled over
+func (u *Usage) Increment(provider, key string, p Period, now time.Time) {
+	keys, ok := u.Providers[provider]
+	if !ok {
+		keys = make(map[string]KeyUsage)
+		u.Providers[provider] = keys
+	}
+	start := p.Start(now).Format(time.DateOnly)
+	fp := Fingerprint(key)
+	ku := keys[fp]
+	if ku.PeriodStart != start {
+		ku = KeyUsage{PeriodStart: start}
+	}
+	ku.Count++
+	keys[fp] = ku
+}
+
+// Record loads usage from path, increments the key, and saves it
+func Record(path, provider, key string, p Period, now time.Time) error {
+	u, err := Load(path)
+	if err != nil {
+		return err
+	}
+	u.Increment(provider, key, p, now)
+	return u.Save(path)
+}
@@ -0,0 +1,86 @@
+package quota
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPeriodStart(t *testing.T) {
+	tests := []struct {
+		name   string
+		period Period
+		now    time.Time
+		want   string
+	}{
+		{"monthly default", Period{Kind: PeriodMonthly, ResetDay: 1}, time.Date(2026, 3, 17, 10, 0, 0, 0, time.UTC), "2026-03-01"},
+		{"monthly after reset day", Period{Kind: PeriodMonthly, ResetDay: 15}, time.Date(2026, 3, 17, 10, 0, 0, 0, time.UTC), "2026-03-15"},
+		{"monthly before reset day", Period{Kind: PeriodMonthly, ResetDay: 15}, time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC), "2026-02-15"},
+		{"monthly across year", Period{Kind: PeriodMonthly, ResetDay: 15}, time.Date(2026, 1, 3, 10, 0, 0, 0, time.UTC), "2025-12-15"},
+		{"daily", Period{Kind: PeriodDaily}, time.Date(2026, 3, 17, 23, 59, 0, 0, time.UTC), "2026-03-17"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.period.Start(tt.now).Format(time.DateOnly); got != tt.want {
+				t.Errorf("Start() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	tests := []struct {
+		kind     string
+		resetDay int
+		wantErr  bool
+	}{
+		{"", 0, false},
+		{"daily", 0, false},
+		{"monthly", 28, false},
+		{"weekly", 0, true},
+		{"monthly", 31, true},
+	}
+
+	for _, tt := range tests {
+		_, err := ParsePeriod(tt.kind, tt.resetDay)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePeriod(%q, %d) error = %v, wantErr %v", tt.kind, tt.resetDay, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRecordRollsOver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quota.json")
+	p := Period{Kind: PeriodMonthly, ResetDay: 1}
+	march := time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)
+	april := time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		if err := Record(path, "brave", "key-a", p, march); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	if err := Record(path, "brave", "key-b", p, march); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	u, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := u.Count("brave", "key-a", p, march); got != 3 {
+		t.Errorf("Count(key-a, march) = %d, want 3", got)
+	}
+	if got := u.Count("brave", "key-b", p, march); got != 1 {
+		t.Errorf("Count(key-b, march) = %d, want 1", got)
+	}
+	if got := u.Count("brave", "key-a", p, april); got != 0 {
+		t.Errorf("Count(key-a, april) = %d, want 0 after rollover", got)
+	}
+
+	u.Increment("brave", "key-a", p, april)
+	if got := u.Count("brave", "key-a", p, april); got != 1 {
+		t.Errorf("Count(key-a, april) = %d, want 1", got)
+	}
+}